@@ -0,0 +1,334 @@
+/*
+Package cache is a bounded, concurrency-safe key/value cache.
+
+map/main.go counts words in a plain map that grows without limit. A cache is
+such a map with a budget: when it is full, an eviction policy picks what to
+forget.
+
+	LRU  forgets the entry used least recently. Cheap and good when recent
+	     keys are likely to be used again, but a single scan over many cold
+	     keys flushes everything.
+	LFU  forgets the entry used least often, oldest first among ties. It keeps
+	     a popular working set through scans, but an entry that was hot once
+	     lingers after it cools down.
+
+The budget is MaxEntries, MaxCost (a caller-defined size per entry, e.g. bytes)
+or both. Entries can also expire after a TTL. Every removal is reported to
+OnEvict with its reason, and GetOrLoad lets many goroutines that miss on the
+same key share a single call to the loader.
+*/
+package cache
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Policy selects which entry is evicted when the cache is over budget.
+type Policy int
+
+const (
+	LRU Policy = iota
+	LFU
+)
+
+func (p Policy) String() string {
+	switch p {
+	case LRU:
+		return "LRU"
+	case LFU:
+		return "LFU"
+	}
+	return "Policy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// Reason says why an entry left the cache.
+type Reason int
+
+const (
+	Removed  Reason = iota // Delete or Purge
+	Replaced               // Set on an existing key; the old value is reported
+	Expired                // its TTL passed
+	Capacity               // MaxEntries was exceeded
+	Cost                   // MaxCost was exceeded
+	numReasons
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Removed:
+		return "removed"
+	case Replaced:
+		return "replaced"
+	case Expired:
+		return "expired"
+	case Capacity:
+		return "capacity"
+	case Cost:
+		return "cost"
+	}
+	return "Reason(" + strconv.Itoa(int(r)) + ")"
+}
+
+// Options configures a Cache. Zero fields mean "no limit" or the documented
+// default.
+type Options[K comparable, V any] struct {
+	Policy     Policy
+	MaxEntries int
+	MaxCost    int64
+	// Cost returns the cost of an entry counted against MaxCost; nil counts
+	// every entry as 1.
+	Cost func(K, V) int64
+	// TTL is the lifetime of entries added with Set; 0 keeps them until they
+	// are evicted.
+	TTL time.Duration
+	// OnEvict is called after an entry leaves the cache, outside the cache's
+	// lock, so it may call back into the cache.
+	OnEvict func(key K, value V, reason Reason)
+	// Now replaces time.Now, for simulated clocks.
+	Now func() time.Time
+}
+
+// Stats are counters since the cache was created.
+type Stats struct {
+	Hits, Misses int64
+	Evictions    [numReasons]int64 // indexed by Reason
+	Loads        int64             // loader calls made by GetOrLoad
+	LoadErrors   int64
+	Coalesced    int64 // GetOrLoad calls that waited for another caller's load
+	Entries      int
+	Cost         int64
+}
+
+// HitRate returns Hits / (Hits + Misses).
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	val     V
+	cost    int64
+	expires time.Time // zero means never
+	elem    *list.Element
+	freq    int // LFU only
+}
+
+// policy orders entries for eviction. All methods run under Cache.mu.
+type policy[K comparable, V any] interface {
+	add(e *entry[K, V])
+	touch(e *entry[K, V])
+	remove(e *entry[K, V])
+	victim() *entry[K, V]
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	val    V
+	reason Reason
+}
+
+// Cache is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	opts Options[K, V]
+
+	mu      sync.Mutex
+	items   map[K]*entry[K, V]
+	policy  policy[K, V]
+	cost    int64
+	stats   Stats
+	loading map[K]*call[V]
+}
+
+// New returns an empty cache.
+func New[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	if opts.Cost == nil {
+		opts.Cost = func(K, V) int64 { return 1 }
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	c := &Cache[K, V]{
+		opts:    opts,
+		items:   make(map[K]*entry[K, V]),
+		loading: make(map[K]*call[V]),
+	}
+	if opts.Policy == LFU {
+		c.policy = newLFU[K, V]()
+	} else {
+		c.policy = &lru[K, V]{}
+	}
+	return c
+}
+
+// Get returns the value for key. An expired entry is removed and reported as
+// a miss.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	e, ok := c.items[key]
+	var evicted []eviction[K, V]
+	if ok && c.expired(e) {
+		evicted = c.removeLocked(e, Expired, evicted)
+		ok = false
+	}
+	var v V
+	if ok {
+		c.stats.Hits++
+		c.policy.touch(e)
+		v = e.val
+	} else {
+		c.stats.Misses++
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return v, ok
+}
+
+// Peek returns the value for key without counting a hit or miss and without
+// changing its eviction order.
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok && !c.expired(e) {
+		return e.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores value under key with the default TTL.
+func (c *Cache[K, V]) Set(key K, value V) { c.SetWithTTL(key, value, c.opts.TTL) }
+
+// SetWithTTL stores value under key, expiring after ttl; 0 means never. An
+// entry whose cost alone exceeds MaxCost is not stored and is reported to
+// OnEvict with reason Cost; an older value under key is still replaced.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	evicted := c.setLocked(key, value, ttl, nil)
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V, ttl time.Duration, evicted []eviction[K, V]) []eviction[K, V] {
+	if cl := c.loading[key]; cl != nil {
+		cl.stale = true
+	}
+	if old, ok := c.items[key]; ok {
+		evicted = c.removeLocked(old, Replaced, evicted)
+	}
+	e := &entry[K, V]{key: key, val: value, cost: c.opts.Cost(key, value)}
+	if c.opts.MaxCost > 0 && e.cost > c.opts.MaxCost {
+		// Admitting it would flush every other entry and then itself.
+		c.stats.Evictions[Cost]++
+		if c.opts.OnEvict != nil {
+			evicted = append(evicted, eviction[K, V]{key, value, Cost})
+		}
+		return evicted
+	}
+
+	// Make room before adding: once in the policy, a new LFU entry has the
+	// lowest count and would be its own victim.
+	for c.opts.MaxEntries > 0 && len(c.items) >= c.opts.MaxEntries {
+		evicted = c.removeLocked(c.policy.victim(), Capacity, evicted)
+	}
+	for c.opts.MaxCost > 0 && len(c.items) > 0 && c.cost+e.cost > c.opts.MaxCost {
+		evicted = c.removeLocked(c.policy.victim(), Cost, evicted)
+	}
+
+	if ttl > 0 {
+		e.expires = c.opts.Now().Add(ttl)
+	}
+	c.items[key] = e
+	c.cost += e.cost
+	c.policy.add(e)
+	return evicted
+}
+
+// Delete removes key and reports whether it was present.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	if cl := c.loading[key]; cl != nil {
+		cl.stale = true
+	}
+	e, ok := c.items[key]
+	var evicted []eviction[K, V]
+	if ok {
+		evicted = c.removeLocked(e, Removed, nil)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return ok
+}
+
+// DeleteExpired removes every expired entry and returns how many there were.
+// Expired entries are otherwise only noticed by Get, so a cache with a TTL
+// and many keys that are never read again should call this periodically.
+func (c *Cache[K, V]) DeleteExpired() int {
+	c.mu.Lock()
+	var evicted []eviction[K, V]
+	for _, e := range c.items {
+		if c.expired(e) {
+			evicted = c.removeLocked(e, Expired, evicted)
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return len(evicted)
+}
+
+// Purge removes every entry. Loads in flight are returned but not stored.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	for _, cl := range c.loading {
+		cl.stale = true
+	}
+	var evicted []eviction[K, V]
+	for _, e := range c.items {
+		evicted = c.removeLocked(e, Removed, evicted)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Stats returns a snapshot of the counters.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = len(c.items)
+	s.Cost = c.cost
+	return s
+}
+
+func (c *Cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expires.IsZero() && !c.opts.Now().Before(e.expires)
+}
+
+func (c *Cache[K, V]) removeLocked(e *entry[K, V], reason Reason, evicted []eviction[K, V]) []eviction[K, V] {
+	delete(c.items, e.key)
+	c.policy.remove(e)
+	c.cost -= e.cost
+	c.stats.Evictions[reason]++
+	if c.opts.OnEvict != nil {
+		evicted = append(evicted, eviction[K, V]{e.key, e.val, reason})
+	}
+	return evicted
+}
+
+func (c *Cache[K, V]) notify(evicted []eviction[K, V]) {
+	for _, ev := range evicted {
+		c.opts.OnEvict(ev.key, ev.val, ev.reason)
+	}
+}
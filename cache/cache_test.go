@@ -0,0 +1,74 @@
+package cache
+
+import "testing"
+
+func TestNewEntryNotOwnVictim(t *testing.T) {
+	for _, p := range []Policy{LRU, LFU} {
+		t.Run(p.String(), func(t *testing.T) {
+			c := New(Options[string, int]{Policy: p, MaxEntries: 2})
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Get("a")
+			c.Get("b")
+			c.Set("c", 3)
+			if v, ok := c.Get("c"); !ok || v != 3 {
+				t.Errorf("Get(c) = %d, %v; want 3, true", v, ok)
+			}
+			if n := c.Len(); n != 2 {
+				t.Errorf("Len = %d, want 2", n)
+			}
+		})
+	}
+}
+
+func TestNewEntryNotOwnVictimByCost(t *testing.T) {
+	c := New(Options[string, int]{
+		Policy:  LFU,
+		MaxCost: 10,
+		Cost:    func(_ string, v int) int64 { return int64(v) },
+	})
+	c.Set("a", 4)
+	c.Set("b", 4)
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 4)
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Get(c) missed")
+	}
+	if s := c.Stats(); s.Evictions[Cost] != 1 {
+		t.Errorf("cost evictions = %d, want 1", s.Evictions[Cost])
+	}
+}
+
+func TestWriteDuringLoad(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		write  func(*Cache[string, int])
+		want   int
+		wantOK bool
+	}{
+		{"set", func(c *Cache[string, int]) { c.Set("k", 2) }, 2, true},
+		{"delete", func(c *Cache[string, int]) { c.Delete("k") }, 0, false},
+		{"purge", func(c *Cache[string, int]) { c.Purge() }, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(Options[string, int]{})
+			v, err := c.GetOrLoad("k", func(string) (int, error) {
+				tc.write(c)
+				return 1, nil
+			})
+			if err != nil || v != 1 {
+				t.Fatalf("GetOrLoad = %d, %v; want 1, nil", v, err)
+			}
+			if v, ok := c.Peek("k"); v != tc.want || ok != tc.wantOK {
+				t.Errorf("Peek = %d, %v; want %d, %v", v, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+
+	c := New(Options[string, int]{})
+	c.GetOrLoad("k", func(string) (int, error) { return 1, nil })
+	if v, ok := c.Peek("k"); !ok || v != 1 {
+		t.Errorf("undisturbed load: Peek = %d, %v; want 1, true", v, ok)
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// call is one in-flight load shared by every GetOrLoad waiting on its key.
+type call[V any] struct {
+	wg    sync.WaitGroup
+	val   V
+	err   error
+	stale bool // key was Set or Deleted during the load; set under Cache.mu
+}
+
+// GetOrLoad returns the cached value for key or calls load to produce it.
+// Concurrent calls for the same missing key wait for a single load and share
+// its result. A successful result is stored with the default TTL; an error is
+// returned to every waiter and nothing is cached. If key is Set or Deleted
+// while the load runs, the loaded value is still returned but not stored,
+// so it cannot overwrite the newer write. A panic in load is turned
+// into an error so waiters are not left blocked.
+func (c *Cache[K, V]) GetOrLoad(key K, load func(K) (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	c.mu.Lock()
+	// The value may have been stored between Get and Lock.
+	if e, ok := c.items[key]; ok && !c.expired(e) {
+		c.policy.touch(e)
+		c.mu.Unlock()
+		return e.val, nil
+	}
+	if cl, ok := c.loading[key]; ok {
+		c.stats.Coalesced++
+		c.mu.Unlock()
+		cl.wg.Wait()
+		return cl.val, cl.err
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	c.loading[key] = cl
+	c.stats.Loads++
+	c.mu.Unlock()
+
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				cl.err = fmt.Errorf("cache: load panicked: %v", p)
+			}
+		}()
+		cl.val, cl.err = load(key)
+	}()
+
+	c.mu.Lock()
+	delete(c.loading, key)
+	var evicted []eviction[K, V]
+	if cl.err != nil {
+		c.stats.LoadErrors++
+	} else if !cl.stale {
+		evicted = c.setLocked(key, cl.val, c.opts.TTL, nil)
+	}
+	c.mu.Unlock()
+	cl.wg.Done()
+	c.notify(evicted)
+	return cl.val, cl.err
+}
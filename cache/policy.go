@@ -0,0 +1,76 @@
+package cache
+
+import "container/list"
+
+// lru keeps entries in a list ordered by last use, most recent at the front.
+type lru[K comparable, V any] struct {
+	order list.List
+}
+
+func (p *lru[K, V]) add(e *entry[K, V])    { e.elem = p.order.PushFront(e) }
+func (p *lru[K, V]) touch(e *entry[K, V])  { p.order.MoveToFront(e.elem) }
+func (p *lru[K, V]) remove(e *entry[K, V]) { p.order.Remove(e.elem) }
+
+func (p *lru[K, V]) victim() *entry[K, V] {
+	return p.order.Back().Value.(*entry[K, V])
+}
+
+// lfu keeps one list per use count, each ordered by last use, so add, touch
+// and remove are O(1). victim takes the least recently used entry of the
+// lowest count.
+type lfu[K comparable, V any] struct {
+	buckets map[int]*list.List
+	min     int // lowest count with a bucket; may be stale after remove
+}
+
+func newLFU[K comparable, V any]() *lfu[K, V] {
+	return &lfu[K, V]{buckets: make(map[int]*list.List)}
+}
+
+func (p *lfu[K, V]) push(e *entry[K, V]) {
+	b := p.buckets[e.freq]
+	if b == nil {
+		b = list.New()
+		p.buckets[e.freq] = b
+	}
+	e.elem = b.PushFront(e)
+}
+
+func (p *lfu[K, V]) unlink(e *entry[K, V]) {
+	b := p.buckets[e.freq]
+	b.Remove(e.elem)
+	if b.Len() == 0 {
+		delete(p.buckets, e.freq)
+	}
+}
+
+func (p *lfu[K, V]) add(e *entry[K, V]) {
+	e.freq = 1
+	p.push(e)
+	p.min = 1
+}
+
+func (p *lfu[K, V]) touch(e *entry[K, V]) {
+	p.unlink(e)
+	if e.freq == p.min && p.buckets[e.freq] == nil {
+		p.min++
+	}
+	e.freq++
+	p.push(e)
+}
+
+func (p *lfu[K, V]) remove(e *entry[K, V]) { p.unlink(e) }
+
+func (p *lfu[K, V]) victim() *entry[K, V] {
+	if p.buckets[p.min] == nil {
+		// The lowest bucket was emptied by remove: rescan. There are only as
+		// many buckets as distinct use counts.
+		p.min = 0
+		for f := range p.buckets {
+			if p.min == 0 || f < p.min {
+				p.min = f
+			}
+		}
+	}
+	return p.buckets[p.min].Back().Value.(*entry[K, V])
+}
@@ -0,0 +1,167 @@
+/*
+cachedemo exercises the cache package.
+
+	go run ./cmd/cachedemo                      all sections
+	go run ./cmd/cachedemo -size 200 -keys 5000
+
+Sections:
+
+	hit rate    LRU vs LFU on a Zipf workload, then on the same workload with a
+	            periodic scan over cold keys that flushes LRU but not LFU
+	limits      MaxEntries and MaxCost evictions reported through OnEvict
+	ttl         expiry on a simulated clock, lazily on Get and by DeleteExpired
+	load        many goroutines missing on one key share a single slow load
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang/cache"
+)
+
+func hitRates(size, keys, ops int) {
+	fmt.Printf("hit rate: cache of %d, %d keys, %d requests\n", size, keys, ops)
+	for _, scan := range []bool{false, true} {
+		for _, p := range []cache.Policy{cache.LRU, cache.LFU} {
+			c := cache.New(cache.Options[int, int]{Policy: p, MaxEntries: size})
+			r := rand.New(rand.NewPCG(1, 1))
+			z := rand.NewZipf(r, 1.1, 1, uint64(keys-1))
+			cold := keys
+			for i := range ops {
+				k := int(z.Uint64())
+				if scan && i%1000 < 300 {
+					// 30% of traffic is a sequential scan of keys read once.
+					k = cold
+					cold++
+				}
+				if _, ok := c.Get(k); !ok {
+					c.Set(k, k)
+				}
+			}
+			label := "zipf"
+			if scan {
+				label = "zipf+scan"
+			}
+			s := c.Stats()
+			fmt.Printf("  %-10s %s  %5.1f%%  evictions %d\n", label, p, 100*s.HitRate(), s.Evictions[cache.Capacity])
+		}
+	}
+}
+
+func limits() {
+	fmt.Println("limits: MaxEntries 3, MaxCost 10 bytes")
+	c := cache.New(cache.Options[string, string]{
+		MaxEntries: 3,
+		MaxCost:    10,
+		Cost:       func(_, v string) int64 { return int64(len(v)) },
+		OnEvict: func(k, v string, r cache.Reason) {
+			fmt.Printf("  evict %s=%q (%s)\n", k, v, r)
+		},
+	})
+	c.Set("a", "aa")
+	c.Set("b", "bb")
+	c.Set("c", "cc")
+	c.Get("a")                   // a is now the most recent
+	c.Set("d", "dd")             // 4 entries: b, the least recent, goes
+	c.Set("e", "eeeeeee")        // 4 entries again: c goes; then 11 bytes: a goes
+	c.Set("d", "d2")             // replacing reports the old value
+	c.Set("big", "xxxxxxxxxxxx") // costs more than the whole budget: rejected
+	c.Delete("e")
+	s := c.Stats()
+	fmt.Printf("  left %d entries, cost %d\n", s.Entries, s.Cost)
+}
+
+// clock is a simulated time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func ttl() {
+	fmt.Println("ttl: default 1m on a simulated clock")
+	clk := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := cache.New(cache.Options[string, int]{
+		TTL: time.Minute,
+		Now: clk.Now,
+		OnEvict: func(k string, _ int, r cache.Reason) {
+			fmt.Printf("  %s: evict %s (%s)\n", clk.Now().Format("15:04:05"), k, r)
+		},
+	})
+	c.Set("session", 1)
+	c.SetWithTTL("token", 2, 10*time.Second)
+	c.SetWithTTL("config", 3, 0)
+	for i := range 3 {
+		c.Set(fmt.Sprint("tmp", i), i)
+	}
+	clk.Advance(30 * time.Second)
+	_, ok := c.Get("token")
+	fmt.Println("  token after 30s:", ok)
+	clk.Advance(time.Minute)
+	fmt.Printf("  after 90s: %d entries before sweep, %d expired\n", c.Len(), c.DeleteExpired())
+	_, ok = c.Get("config")
+	fmt.Println("  config (no ttl) still there:", ok)
+}
+
+func load() {
+	fmt.Println("load: 100 goroutines miss on the same key")
+	c := cache.New(cache.Options[string, string]{MaxEntries: 10})
+	var calls atomic.Int32
+	slow := func(k string) (string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "value of " + k, nil
+	}
+	var wg sync.WaitGroup
+	results := make([]string, 100)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.GetOrLoad("user:42", slow)
+		}()
+	}
+	wg.Wait()
+	s := c.Stats()
+	fmt.Printf("  loader calls %d, coalesced %d, result %q\n", calls.Load(), s.Coalesced, results[99])
+
+	failing := func(string) (string, error) { return "", errors.New("backend down") }
+	_, err := c.GetOrLoad("user:7", failing)
+	_, cached := c.Peek("user:7")
+	fmt.Printf("  failing load: err=%v, cached=%v\n", err, cached)
+	s = c.Stats()
+	fmt.Printf("  stats: hits %d misses %d loads %d errors %d\n", s.Hits, s.Misses, s.Loads, s.LoadErrors)
+}
+
+func main() {
+	size := flag.Int("size", 100, "cache entries for the hit rate comparison")
+	keys := flag.Int("keys", 10000, "distinct keys in the Zipf workload")
+	ops := flag.Int("ops", 200000, "requests per hit rate run")
+	flag.Parse()
+
+	hitRates(*size, *keys, *ops)
+	fmt.Println()
+	limits()
+	fmt.Println()
+	ttl()
+	fmt.Println()
+	load()
+}
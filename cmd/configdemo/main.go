@@ -0,0 +1,251 @@
+/*
+configdemo loads its settings with the config package and runs the
+producer/consumer from channels/rangeAndClose.go with them.
+
+	go run ./cmd/configdemo                              defaults
+	CONFIGDEMO_CAPACITY=8 go run ./cmd/configdemo -n 20  environment and flags
+	go run ./cmd/configdemo -config app.toml             a settings file
+	go run ./cmd/configdemo -h                           the generated help
+	go run ./cmd/configdemo -check                       verify precedence, parsing and errors
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang/config"
+	"golang/dump"
+)
+
+type Settings struct {
+	Capacity int           `flag:"capacity" env:"CAPACITY" default:"2" usage:"channel buffer size"`
+	N        int           `flag:"n" env:"N" default:"5" usage:"values to send"`
+	Delay    time.Duration `flag:"delay" env:"DELAY" default:"1ms" usage:"pause between sends"`
+	Buffer   config.Size   `flag:"buffer" default:"4KiB" usage:"scratch buffer per consumer"`
+	Tags     []string      `flag:"tag" env:"TAGS" usage:"labels for the log lines"`
+	Verbose  bool          `flag:"v" usage:"print every value"`
+	Check    bool          `flag:"check" usage:"run the self check instead"`
+}
+
+func run(s Settings) {
+	ch := make(chan int, s.Capacity)
+	go func() {
+		for i := range s.N {
+			ch <- i
+			time.Sleep(s.Delay)
+		}
+		close(ch)
+	}()
+	buf := make([]byte, 0, s.Buffer)
+	sum := 0
+	for v := range ch {
+		if s.Verbose {
+			fmt.Println(strings.Join(s.Tags, " "), "got", v)
+		}
+		sum += v
+	}
+	fmt.Printf("received %d values, sum %d, buffer cap %s\n", s.N, sum, config.Size(cap(buf)))
+}
+
+type checker struct{ failed bool }
+
+func (c *checker) report(name string, ok bool, detail string) {
+	status := "ok  "
+	if !ok {
+		status = "FAIL"
+		c.failed = true
+	}
+	fmt.Printf("%s  %-34s %s\n", status, name, detail)
+}
+
+type DB struct {
+	DSN     string        `flag:"dsn" env:"DSN" required:"true"`
+	Pool    int           `default:"4"`
+	Timeout time.Duration `flag:"timeout" default:"2s"`
+}
+
+type App struct {
+	Listen   string      `flag:"listen" env:"LISTEN" default:":8080" usage:"address to serve on"`
+	MaxBody  config.Size `env:"MAX_BODY" default:"1MiB"`
+	Peers    []string    `flag:"peer" env:"PEERS"`
+	Ratio    float64     `default:"0.5"`
+	HTTPPort uint16      `default:"80"`
+	Debug    bool        `flag:"debug" env:"DEBUG"`
+	DB       DB          `env:"DB_" flag:"db."`
+	Replica  DB          `env:"REPLICA_" flag:"replica." key:"replica"`
+}
+
+func env(m map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := m[k]
+		return v, ok
+	}
+}
+
+func checkPrecedence(c *checker, dir string) {
+	toml := filepath.Join(dir, "app.toml")
+	os.WriteFile(toml, []byte(`# service settings
+listen = ":9000"      # overridden by the flag
+max_body = 2MiB
+peers = ["a:1", "b:2"]
+http_port = 8080
+
+[db]
+dsn = "postgres://file"
+pool = 8
+
+[replica]
+dsn = 'postgres://replica'
+timeout = 500ms
+`), 0o644)
+	js := filepath.Join(dir, "override.json")
+	os.WriteFile(js, []byte(`{"ratio": 0.75, "db": {"pool": 16}, "replica": {"pool": null}}`), 0o644)
+
+	var a App
+	l := &config.Loader{
+		Files:     []string{toml, filepath.Join(dir, "missing.toml")},
+		FileFlag:  "config",
+		EnvPrefix: "APP_",
+		LookupEnv: env(map[string]string{"APP_DB_DSN": "postgres://env", "APP_PEERS": "c:3", "APP_DEBUG": "true"}),
+		Args:      []string{"-listen", ":7000", "-config", js, "-db.timeout=3s", "-debug=false", "serve", "-x"},
+	}
+	err := l.Load(&a)
+	c.report("load", err == nil, fmt.Sprint(err))
+	c.report("flag beats file", a.Listen == ":7000", a.Listen)
+	c.report("file beats default", a.MaxBody == 2*config.MiB && a.HTTPPort == 8080, a.MaxBody.String())
+	c.report("env beats file", a.DB.DSN == "postgres://env" && slices.Equal(a.Peers, []string{"c:3"}), a.DB.DSN)
+	c.report("flag beats env", !a.Debug, "")
+	c.report("later file beats earlier", a.DB.Pool == 16 && a.Ratio == 0.75, fmt.Sprint(a.DB.Pool))
+	c.report("null keeps the value", a.Replica.Pool == 4, fmt.Sprint(a.Replica.Pool))
+	c.report("nested flag prefix", a.DB.Timeout == 3*time.Second && a.Replica.Timeout == 500*time.Millisecond, "")
+	c.report("remaining arguments", slices.Equal(l.Rest, []string{"serve", "-x"}), strings.Join(l.Rest, " "))
+
+	a = App{}
+	l = &config.Loader{LookupEnv: env(nil), Args: []string{"-peer", "x", "-peer", "y,z", "-db.dsn", "d", "-replica.dsn", "r"}}
+	err = l.Load(&a)
+	c.report("repeated list flag", err == nil && slices.Equal(a.Peers, []string{"x", "y", "z"}), fmt.Sprint(a.Peers))
+}
+
+func checkErrors(c *checker, dir string) {
+	bad := filepath.Join(dir, "bad.toml")
+	os.WriteFile(bad, []byte("listen = \":1\"\n\nlisten_adr = \":2\"\n"), 0o644)
+	badJSON := filepath.Join(dir, "bad.json")
+	os.WriteFile(badJSON, []byte(`{"db": {"pool": "many"}}`), 0o644)
+
+	for _, tc := range []struct {
+		name string
+		l    config.Loader
+		want string
+	}{
+		{"unknown key has a line", config.Loader{Files: []string{bad}},
+			`bad.toml:3: unknown key "listen_adr"`},
+		{"bad value names the key", config.Loader{Files: []string{badJSON}},
+			`bad.json: db.pool: invalid int "many"`},
+		{"bad env value", config.Loader{LookupEnv: env(map[string]string{"MAX_BODY": "lots"})},
+			`$MAX_BODY: invalid size "lots"`},
+		{"bad flag value", config.Loader{Args: []string{"-db.timeout", "soon"}},
+			`-db.timeout: invalid duration "soon"`},
+		{"flag file must exist", config.Loader{FileFlag: "config", Args: []string{"-config", filepath.Join(dir, "nope.toml")}},
+			"no such file"},
+		{"missing required", config.Loader{},
+			"missing required db.dsn (-db.dsn, $DB_DSN), replica.dsn (-replica.dsn, $REPLICA_DSN)"},
+	} {
+		var a App
+		if tc.l.LookupEnv == nil {
+			tc.l.LookupEnv = env(map[string]string{"DB_DSN": "x", "REPLICA_DSN": "y"})
+			if tc.name == "missing required" {
+				tc.l.LookupEnv = env(nil)
+			}
+		}
+		if tc.l.Args == nil {
+			tc.l.Args = []string{}
+		}
+		err := tc.l.Load(&a)
+		c.report(tc.name, err != nil && strings.Contains(err.Error(), tc.want), fmt.Sprint(err))
+	}
+
+	var missing *config.MissingError
+	err := (&config.Loader{Args: []string{}, LookupEnv: env(nil)}).Load(&App{})
+	c.report("MissingError type", errors.As(err, &missing) && len(missing.Settings) == 2, "")
+
+	var out strings.Builder
+	err = (&config.Loader{Args: []string{"-h"}, Output: &out, Name: "app", EnvPrefix: "APP_"}).Load(&App{})
+	help := out.String()
+	c.report("help", errors.Is(err, flag.ErrHelp) &&
+		strings.Contains(help, "-listen string") &&
+		strings.Contains(help, "APP_DB_DSN") &&
+		strings.Contains(help, "-db.timeout duration") &&
+		strings.Contains(help, "-peer string,...") &&
+		strings.Contains(help, "required"), "")
+	if c.failed {
+		fmt.Print(help)
+	}
+
+	type unsupported struct{ M map[string]int }
+	c.report("unsupported type", (&config.Loader{Args: []string{}}).Load(&unsupported{}) != nil, "")
+	type dupFlag struct {
+		A string `flag:"x"`
+		B string `flag:"x"`
+	}
+	err = (&config.Loader{Args: []string{}}).Load(&dupFlag{})
+	c.report("duplicate flag", err != nil, fmt.Sprint(err))
+}
+
+func checkSize(c *checker) {
+	for _, tc := range []struct {
+		in   string
+		want config.Size
+	}{
+		{"0", 0}, {"512", 512}, {"512B", 512}, {"64KB", 64_000}, {"64KiB", 65536}, {"64k", 65536},
+		{"1.5GiB", 3 << 29}, {"2 mb", 2_000_000}, {"1TiB", 1 << 40},
+	} {
+		got, err := config.ParseSize(tc.in)
+		back, err2 := config.ParseSize(got.String())
+		c.report("size "+tc.in, err == nil && got == tc.want && err2 == nil && back == got, got.String())
+	}
+	for _, in := range []string{"", "MB", "1.2.3", "5 parsecs", "-1", "9999999999TiB"} {
+		_, err := config.ParseSize(in)
+		c.report("size rejects "+fmt.Sprintf("%q", in), err != nil, fmt.Sprint(err))
+	}
+}
+
+func check() bool {
+	dir, err := os.MkdirTemp("", "configdemo")
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	defer os.RemoveAll(dir)
+	c := &checker{}
+	checkPrecedence(c, dir)
+	checkErrors(c, dir)
+	checkSize(c)
+	return !c.failed
+}
+
+func main() {
+	var s Settings
+	l := &config.Loader{EnvPrefix: "CONFIGDEMO_", FileFlag: "config"}
+	if err := l.Load(&s); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if s.Check {
+		if !check() {
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println(dump.Sdump(s))
+	run(s)
+}
@@ -0,0 +1,111 @@
+/*
+cpusched runs the scheduling algorithms from the cpusched package over a process
+list and prints a Gantt chart and metrics for each one, followed by a comparison
+table.
+
+	go run ./cmd/cpusched -in cmd/cpusched/processes.csv
+	go run ./cmd/cpusched -in procs.json -algo rr,mlfq -quantum 3 -svg gantt.svg
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang/cpusched"
+)
+
+func main() {
+	in := flag.String("in", "", "process list (.csv or .json), stdin when empty")
+	format := flag.String("format", "", "input format: csv or json (default: from file extension, csv for stdin)")
+	algos := flag.String("algo", "fcfs,sjf,srtf,rr,priority,priority-p,mlfq", "comma separated algorithms to run")
+	quantum := flag.Int("quantum", 2, "round robin time quantum")
+	aging := flag.Int("aging", 0, "priority aging interval in ticks, 0 disables aging")
+	levels := flag.String("mlfq", "2,4,8", "MLFQ quantum per level")
+	boost := flag.Int("boost", 0, "MLFQ priority boost interval in ticks, 0 disables it")
+	scale := flag.Int("scale", 3, "characters per tick in the ASCII chart")
+	svg := flag.String("svg", "", "write an SVG Gantt chart of all runs to this file")
+	flag.Parse()
+
+	procs, err := load(*in, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	quanta, err := parseInts(*levels)
+	if err != nil {
+		log.Fatalf("-mlfq: %v", err)
+	}
+
+	var results []cpusched.Result
+	for _, name := range strings.Split(*algos, ",") {
+		s, err := cpusched.Parse(strings.TrimSpace(name), *quantum, *aging, quanta, *boost)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err := s.Schedule(procs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		results = append(results, r)
+
+		cpusched.WriteASCII(os.Stdout, r, *scale)
+		cpusched.WriteStats(os.Stdout, r)
+		fmt.Println()
+	}
+
+	fmt.Printf("%-22s %10s %8s %8s %8s\n", "algorithm", "turnaround", "waiting", "response", "switches")
+	for _, r := range results {
+		fmt.Printf("%-22s %10.2f %8.2f %8.2f %8d\n", r.Algorithm, r.AvgTurnaround(), r.AvgWaiting(), r.AvgResponse(), r.ContextSwitches)
+	}
+
+	if *svg != "" {
+		f, err := os.Create(*svg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err := cpusched.WriteSVG(f, results...); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func load(path, format string) ([]cpusched.Process, error) {
+	var r io.Reader = os.Stdin
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(path), ".")
+		}
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		return cpusched.LoadJSON(r)
+	case "", "csv":
+		return cpusched.LoadCSV(r)
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+/*
+ctxlab measures how quickly a goroutine blocked in conn.Read notices that its
+context is done, over real localhost sockets.
+
+The server accepts connections and never writes, so every client read blocks in
+the netpoller. Each trial cancels the context after a random delay and records
+the time between cancel() and Read returning, for several strategies:
+
+	poke     ctxnet: cancellation moves the socket deadline into the past
+	timeout  ctxnet: context.WithTimeout, measured from the deadline
+	close    a watcher goroutine closes the conn on ctx.Done()
+	poll     short SetReadDeadline slices, checking ctx.Err() between them
+
+	go run ./cmd/ctxlab -trials 500 -poll 10ms
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/bits"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang/ctxnet"
+)
+
+func main() {
+	trials := flag.Int("trials", 300, "trials per strategy")
+	pollEvery := flag.Duration("poll", 10*time.Millisecond, "deadline slice for the poll strategy")
+	maxDelay := flag.Duration("delay", 5*time.Millisecond, "maximum random delay before cancel")
+	flag.Parse()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ln.Close()
+	go silentServer(ln)
+	addr := ln.Addr().String()
+
+	strategies := []struct {
+		name string
+		run  func(addr string, delay time.Duration) (time.Duration, error)
+	}{
+		{"poke", poke},
+		{"timeout", timeout},
+		{"close", closeOnDone},
+		{"poll/" + pollEvery.String(), func(addr string, d time.Duration) (time.Duration, error) {
+			return poll(addr, d, *pollEvery)
+		}},
+	}
+	for _, s := range strategies {
+		lat := make([]time.Duration, 0, *trials)
+		for i := 0; i < *trials; i++ {
+			delay := time.Duration(rand.Int64N(int64(*maxDelay))) + time.Millisecond
+			d, err := s.run(addr, delay)
+			if err != nil {
+				log.Fatalf("%s: %v", s.name, err)
+			}
+			lat = append(lat, d)
+		}
+		report(s.name, lat)
+	}
+}
+
+// silentServer holds every connection open without ever writing to it.
+func silentServer(ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			buf := make([]byte, 1)
+			c.Read(buf) // returns when the client goes away
+		}()
+	}
+}
+
+func poke(addr string, delay time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := ctxnet.Dial(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	canceled := make(chan time.Time, 1)
+	time.AfterFunc(delay, func() {
+		canceled <- time.Now()
+		cancel()
+	})
+	_, err = c.Read(make([]byte, 1))
+	end := time.Now()
+	if !errors.Is(err, context.Canceled) {
+		return 0, fmt.Errorf("unexpected read result: %v", err)
+	}
+	return end.Sub(<-canceled), nil
+}
+
+func timeout(addr string, delay time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), delay)
+	defer cancel()
+	c, err := ctxnet.Dial(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	deadline, _ := ctx.Deadline()
+	_, err = c.Read(make([]byte, 1))
+	end := time.Now()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return 0, fmt.Errorf("unexpected read result: %v", err)
+	}
+	return end.Sub(deadline), nil
+}
+
+func closeOnDone(addr string, delay time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	go func() {
+		<-ctx.Done()
+		c.Close()
+	}()
+	canceled := make(chan time.Time, 1)
+	time.AfterFunc(delay, func() {
+		canceled <- time.Now()
+		cancel()
+	})
+	_, err = c.Read(make([]byte, 1))
+	end := time.Now()
+	if !errors.Is(err, net.ErrClosed) {
+		return 0, fmt.Errorf("unexpected read result: %v", err)
+	}
+	return end.Sub(<-canceled), nil
+}
+
+func poll(addr string, delay, every time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	canceled := make(chan time.Time, 1)
+	time.AfterFunc(delay, func() {
+		canceled <- time.Now()
+		cancel()
+	})
+	buf := make([]byte, 1)
+	for ctx.Err() == nil {
+		c.SetReadDeadline(time.Now().Add(every))
+		_, err = c.Read(buf)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return 0, fmt.Errorf("unexpected read result: %v", err)
+		}
+	}
+	return time.Since(<-canceled), nil
+}
+
+func report(name string, lat []time.Duration) {
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	pct := func(p float64) time.Duration { return lat[int(float64(len(lat)-1)*p)] }
+	fmt.Printf("%-12s min %-9v p50 %-9v p90 %-9v p99 %-9v max %v\n", name,
+		lat[0].Round(time.Microsecond), pct(.5).Round(time.Microsecond), pct(.9).Round(time.Microsecond),
+		pct(.99).Round(time.Microsecond), lat[len(lat)-1].Round(time.Microsecond))
+
+	// Power-of-two microsecond buckets.
+	var buckets [32]int
+	first, last := len(buckets), 0
+	for _, d := range lat {
+		us := uint64(max(d.Microseconds(), 0))
+		b := bits.Len64(us)
+		buckets[b]++
+		first, last = min(first, b), max(last, b)
+	}
+	for b := first; b <= last; b++ {
+		lo := 0
+		if b > 0 {
+			lo = 1 << (b - 1)
+		}
+		bar := strings.Repeat("#", (buckets[b]*50+len(lat)-1)/len(lat))
+		fmt.Printf("  %8dµs+ %5d %s\n", lo, buckets[b], bar)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,276 @@
+/*
+dumpdemo prints a few values with the dump package and compares it to fmt.
+
+	go run ./cmd/dumpdemo            side by side with %v, %+v and %#v, in color
+	go run ./cmd/dumpdemo -check     compare dumps against golden output
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang/dump"
+)
+
+type Customer struct {
+	Name    string
+	Email   string
+	tags    []string
+	Manager *Customer
+}
+
+type Order struct {
+	ID       int
+	Customer *Customer
+	Lines    map[string]int
+	Meta     any
+	Notes    []string
+	Raw      []byte
+	Paid     *bool
+	total    float64
+}
+
+type Node struct {
+	Val      int
+	Next     *Node
+	Children []*Node
+}
+
+type Celsius float64
+
+func (c Celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+func order() *Order {
+	return &Order{
+		ID:       7,
+		Customer: &Customer{Name: "ada", Email: "ada@example.com", tags: []string{"vip", "eu"}},
+		Lines:    map[string]int{"pear": 1, "apple": 2, "fig": 12},
+		Meta:     map[string]any{"source": "web", "retries": uint8(2), "ratio": 0.5},
+		Raw:      []byte("{\"id\":7}"),
+		total:    12.5,
+	}
+}
+
+func demo() {
+	o := order()
+	fmt.Printf("%%v:  %v\n%%+v: %+v\n%%#v: %#v\n\n", o, o, o)
+	dump.Config{Color: true}.Fdump(os.Stdout, o)
+
+	a := &Node{Val: 1}
+	b := &Node{Val: 2, Next: a}
+	a.Next = b
+	a.Children = []*Node{b, {Val: 3}}
+	fmt.Println("\na cycle, with addresses:")
+	dump.Config{Color: true, Addresses: true}.Fdump(os.Stdout, a)
+
+	fmt.Println("\nStringers and MaxDepth 1:")
+	dump.Config{Stringers: true, MaxDepth: 1}.Fdump(os.Stdout, map[string]any{
+		"temp":  Celsius(21.5),
+		"at":    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		"err":   errors.New("boom"),
+		"order": o,
+	})
+
+	fmt.Println("\nDiff after changing two fields:")
+	o2 := order()
+	o2.Customer.tags[1] = "us"
+	o2.Lines["fig"] = 11
+	fmt.Print(dump.Diff(order(), o2))
+}
+
+var goldens = []struct {
+	name string
+	cfg  dump.Config
+	v    func() any
+	want string
+}{
+	{"order", dump.Config{}, func() any { return order() }, `&main.Order{
+  ID: 7,
+  Customer: &main.Customer{
+    Name: "ada",
+    Email: "ada@example.com",
+    tags: []string{"vip", "eu"},
+    Manager: nil,
+  },
+  Lines: map[string]int{
+    "apple": 2,
+    "fig": 12,
+    "pear": 1,
+  },
+  Meta: map[string]interface {}{
+    "ratio": 0.5,
+    "retries": uint8(2),
+    "source": "web",
+  },
+  Notes: nil,
+  Raw: []uint8("{\"id\":7}"),
+  Paid: nil,
+  total: 12.5,
+}`},
+	{"exported only", dump.Config{ExportedOnly: true, MaxDepth: 1}, func() any { return order() }, `&main.Order{
+  ID: 7,
+  Customer: &main.Customer{...},
+  Lines: map[string]int{...},
+  Meta: map[string]interface {}{...},
+  Notes: nil,
+  Raw: []uint8("{\"id\":7}"),
+  Paid: nil,
+}`},
+	{"cycle", dump.Config{}, func() any {
+		a := &Node{Val: 1}
+		a.Next = &Node{Val: 2, Next: a}
+		a.Children = []*Node{a.Next, a.Next}
+		return a
+	}, `&main.Node{
+  Val: 1,
+  Next: &main.Node{
+    Val: 2,
+    Next: <cycle *main.Node>,
+    Children: nil,
+  },
+  Children: []*main.Node{
+    &{
+      Val: 2,
+      Next: <cycle *main.Node>,
+      Children: nil,
+    },
+    &{
+      Val: 2,
+      Next: <cycle *main.Node>,
+      Children: nil,
+    },
+  },
+}`},
+	{"self-containing slice", dump.Config{}, func() any {
+		s := make([]any, 2)
+		s[0] = 1
+		s[1] = s
+		return s
+	}, `[]interface {}{
+  1,
+  <cycle []interface {}>,
+}`},
+	{"scalars", dump.Config{}, func() any {
+		t := true
+		return []any{nil, int8(-3), &t, "x", 1.0, math.NaN(), Celsius(3), complex(1, 2), []byte{0, 255},
+			(*int)(nil), []int(nil), map[int]bool{}, struct{}{}}
+	}, `[]interface {}{
+  nil,
+  int8(-3),
+  &bool(true),
+  "x",
+  1,
+  NaN,
+  main.Celsius(3),
+  complex128((1+2i)),
+  []uint8{0x00, 0xff},
+  (*int)(nil),
+  []int(nil),
+  map[int]bool{},
+  struct {}{},
+}`},
+	{"sorted keys", dump.Config{}, func() any {
+		return map[any]int{"b": 1, 10: 2, 9: 3, "a": 4, false: 5, [2]int{1, 2}: 6, [2]int{1, 1}: 7}
+	}, `map[interface {}]int{
+  [2]int{1, 1}: 7,
+  [2]int{1, 2}: 6,
+  false: 5,
+  9: 3,
+  10: 2,
+  "a": 4,
+  "b": 1,
+}`},
+	{"stringers", dump.Config{Stringers: true}, func() any {
+		return struct {
+			T Celsius
+			E error
+			d time.Duration
+		}{21.5, errors.New("boom"), time.Second}
+	}, `struct { T main.Celsius; E error; d time.Duration }{
+  T: "21.5°C",
+  E: *errors.errorString("boom"),
+  d: 1000000000,
+}`},
+	{"long list wraps", dump.Config{}, func() any {
+		s := make([]string, 12)
+		for i := range s {
+			s[i] = strings.Repeat("x", 3*i)
+		}
+		return s[8:]
+	}, `[]string{
+  "xxxxxxxxxxxxxxxxxxxxxxxx",
+  "xxxxxxxxxxxxxxxxxxxxxxxxxxx",
+  "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+  "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+}`},
+}
+
+func check() bool {
+	ok := true
+	for _, g := range goldens {
+		got := g.cfg.Sdump(g.v())
+		status := "ok  "
+		if got != g.want {
+			status = "FAIL"
+			ok = false
+		}
+		fmt.Printf("%s  %s\n", status, g.name)
+		if got != g.want {
+			fmt.Print(dump.Config{}.Diff(g.want, got))
+			fmt.Println(got)
+		}
+	}
+
+	o1, o2 := order(), order()
+	status := "ok  "
+	if d := dump.Diff(o1, o2); d != "" {
+		status, ok = "FAIL", false
+	}
+	fmt.Printf("%s  equal values give an empty diff\n", status)
+
+	o2.Customer.Email = "ada@example.org"
+	o2.Notes = []string{"gift"}
+	want := `@@ line 3 @@
+    Customer: &main.Customer{
+      Name: "ada",
+-     Email: "ada@example.com",
++     Email: "ada@example.org",
+      tags: []string{"vip", "eu"},
+      Manager: nil,
+@@ line 17 @@
+      "source": "web",
+    },
+-   Notes: nil,
++   Notes: []string{"gift"},
+    Raw: []uint8("{\"id\":7}"),
+    Paid: nil,
+`
+	d := dump.Diff(o1, o2)
+	status = "ok  "
+	if d != want {
+		status, ok = "FAIL", false
+	}
+	fmt.Printf("%s  diff hunks\n", status)
+	if d != want {
+		fmt.Print(d)
+	}
+	return ok
+}
+
+func main() {
+	doCheck := flag.Bool("check", false, "compare dumps against golden output")
+	flag.Parse()
+	if *doCheck {
+		if !check() {
+			os.Exit(1)
+		}
+		return
+	}
+	demo()
+}
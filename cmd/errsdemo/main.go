@@ -0,0 +1,234 @@
+/*
+errsdemo shows the errs package in a small user service.
+
+	go run ./cmd/errsdemo          print errors with %v, %+v, JSON, slog and over HTTP
+	go run ./cmd/errsdemo -check   verify wrapping, matching and rendering
+
+The typed-nil trap of concept/Interface/nill_interface.md is covered by the
+tests in errs.
+*/
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"golang/errs"
+)
+
+var ErrUserNotFound = errs.New(errs.NotFound, "user not found").WithCode("user_not_found")
+
+type store struct{ users map[string]string }
+
+func (s *store) find(id string) (string, error) {
+	if id == "" {
+		return "", errs.New(errs.Invalid, "empty user id", "field", "id")
+	}
+	name, ok := s.users[id]
+	if !ok {
+		return "", ErrUserNotFound.With("user", id)
+	}
+	return name, nil
+}
+
+func (s *store) rename(ctx context.Context, id, name string) error {
+	if _, err := s.find(id); err != nil {
+		return errs.Wrap(err, errs.Unknown, "rename", "new_name", name)
+	}
+	select {
+	case <-time.After(50 * time.Millisecond):
+	case <-ctx.Done():
+		return errs.Wrap(ctx.Err(), errs.Unknown, "rename", "user", id)
+	}
+	s.users[id] = name
+	return nil
+}
+
+func (s *store) handler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Millisecond)
+	defer cancel()
+	q := r.URL.Query()
+	if err := s.rename(ctx, q.Get("id"), q.Get("name")); err != nil {
+		errs.WriteHTTP(w, err, slog.Default()) // logs err with its causes
+		return
+	}
+	errs.WriteHTTP(w, nil, nil)
+}
+
+func demo() {
+	s := &store{users: map[string]string{"1": "ada"}}
+	err := s.rename(context.Background(), "42", "bob")
+
+	fmt.Printf("%%v:  %v\n", err)
+	fmt.Printf("%%+v: %+v\n", err)
+	b, _ := json.Marshal(err)
+	fmt.Printf("json: %s\n", b)
+	fmt.Println("errors.Is(err, ErrUserNotFound):", errors.Is(err, ErrUserNotFound))
+	fmt.Println("errors.Is(err, errs.NotFound):  ", errors.Is(err, errs.NotFound))
+
+	fmt.Println("\nwith stacks and slog:")
+	errs.CaptureStacks(true)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+	srv := httptest.NewServer(http.HandlerFunc(s.handler))
+	defer srv.Close()
+	for _, q := range []string{"id=1&name=eve", "id=", "id=42"} {
+		resp, err := http.Get(srv.URL + "/?" + q)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("  GET ?%-14s %d %s", q, resp.StatusCode, body.String())
+	}
+}
+
+type checker struct{ failed bool }
+
+func (c *checker) report(name string, ok bool, detail string) {
+	status := "ok  "
+	if !ok {
+		status = "FAIL"
+		c.failed = true
+	}
+	fmt.Printf("%s  %-36s %s\n", status, name, detail)
+}
+
+func checkMatching(c *checker) {
+	s := &store{users: map[string]string{"1": "ada"}}
+	err := s.rename(context.Background(), "42", "bob")
+	var e *errs.Error
+	c.report("errors.As finds *Error", errors.As(err, &e) && e.Msg == "rename", fmt.Sprint(e))
+	c.report("Is sentinel through With", errors.Is(err, ErrUserNotFound), "")
+	c.report("Is kind through Unknown wrapper", errors.Is(err, errs.NotFound) && !errors.Is(err, errs.Invalid), "")
+	c.report("Is code template", errors.Is(err, &errs.Error{Code: "user_not_found"}) &&
+		!errors.Is(err, &errs.Error{Kind: errs.Invalid, Code: "user_not_found"}), "")
+	c.report("empty template matches nothing", !errors.Is(err, &errs.Error{}), "")
+	c.report("KindOf and CodeOf", errs.KindOf(err) == errs.NotFound && errs.CodeOf(err) == "user_not_found", "")
+
+	outer := errs.Wrap(err, errs.Conflict, "sync")
+	c.report("explicit kind overrides cause", errs.KindOf(outer) == errs.Conflict && errors.Is(outer, errs.NotFound), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err = s.rename(ctx, "1", "eve")
+	c.report("context errors map to kinds", errs.KindOf(err) == errs.Timeout &&
+		errors.Is(err, context.DeadlineExceeded) && errs.HTTPStatus(err) == http.StatusGatewayTimeout, fmt.Sprint(err))
+	_, err = os.Open("/does/not/exist")
+	c.report("fs errors map to kinds", errs.KindOf(errs.Wrap(err, errs.Unknown, "load")) == errs.NotFound, "")
+	c.report("plain errors are internal", errs.KindOf(errors.New("boom")) == errs.Internal &&
+		errs.HTTPStatus(errors.New("boom")) == 500, "")
+	c.report("bare kind in fmt.Errorf", errors.Is(fmt.Errorf("x: %w", errs.Unavailable), errs.Unavailable) &&
+		errs.KindOf(fmt.Errorf("x: %w", errs.Unavailable)) == errs.Unavailable, "")
+
+	joined := errors.Join(errors.New("disk"), errs.New(errs.PermissionDenied, "acl", "path", "/etc"))
+	wrapped := errs.Wrap(joined, errs.Unknown, "save")
+	c.report("Join branches are searched", errors.Is(wrapped, errs.PermissionDenied) &&
+		errs.KindOf(wrapped) == errs.PermissionDenied && len(errs.Fields(wrapped)) == 1, "")
+	c.report("Is(fs.ErrNotExist) unaffected", !errors.Is(wrapped, fs.ErrNotExist), "")
+}
+
+func checkRendering(c *checker) {
+	inner := errs.New(errs.NotFound, "no row", "table", "users", "id", 7).WithCode("row_missing")
+	err := errs.Wrap(errs.With(inner, "id", 8), errs.Unknown, "get user", "op", "get")
+	c.report("Error text", err.Error() == "get user: no row", err.Error())
+	f := errs.Fields(err)
+	keys := make([]string, len(f))
+	for i, a := range f {
+		keys[i] = a.String()
+	}
+	c.report("Fields outermost wins", strings.Join(keys, " ") == "op=get id=8 table=users", strings.Join(keys, " "))
+
+	b, jerr := json.Marshal(err)
+	want := `{"message":"get user","fields":{"op":"get"},"cause":{"fields":{"id":8},"cause":` +
+		`{"kind":"not_found","code":"row_missing","message":"no row","fields":{"id":7,"table":"users"}}}}`
+	c.report("JSON", jerr == nil && string(b) == want, string(b))
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})).Error("failed", "err", err)
+	want = `{"level":"ERROR","msg":"failed","err":{"msg":"get user: no row","kind":"not_found",` +
+		`"code":"row_missing","op":"get","id":8,"table":"users"}}`
+	c.report("slog group", strings.TrimSpace(buf.String()) == want, strings.TrimSpace(buf.String()))
+
+	buf.Reset()
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	rec := httptest.NewRecorder()
+	errs.WriteHTTP(rec, err, logger)
+	c.report("WriteHTTP", rec.Code == 404 && strings.Contains(rec.Body.String(), `"code":"row_missing"`), strings.TrimSpace(rec.Body.String()))
+	c.report("WriteHTTP sends the kind's message", strings.Contains(rec.Body.String(), `"message":"no row"`) &&
+		!strings.Contains(rec.Body.String(), "get user") && strings.Contains(buf.String(), "get user: no row"), strings.TrimSpace(buf.String()))
+	buf.Reset()
+	rec = httptest.NewRecorder()
+	errs.WriteHTTP(rec, fmt.Errorf("pq: password authentication failed for user %q", "svc"), logger)
+	c.report("WriteHTTP hides internal details", rec.Code == 500 && !strings.Contains(rec.Body.String(), "pq") &&
+		strings.Contains(buf.String(), "level=ERROR") && strings.Contains(buf.String(), "pq"), strings.TrimSpace(rec.Body.String()))
+}
+
+func checkStacks(c *checker) {
+	errs.CaptureStacks(false)
+	c.report("no stack by default", errs.Stack(errs.New(errs.Internal, "x")) == nil, "")
+	errs.CaptureStacks(true)
+	defer errs.CaptureStacks(false)
+
+	inner := errs.New(errs.Internal, "deep")
+	outer := errs.Wrap(inner, errs.Unknown, "shallow")
+	st := errs.Stack(outer)
+	c.report("stack starts at caller", len(st) > 0 && strings.HasSuffix(st[0].Function, "main.checkStacks"),
+		func() string {
+			if len(st) == 0 {
+				return "empty"
+			}
+			return st[0].Function
+		}())
+	c.report("Wrap keeps the inner stack", outer.(*errs.Error).StackFrames() == nil, "")
+	c.report("Wrap of a plain error captures", errs.Stack(errs.Wrap(errors.New("x"), errs.Internal, "y")) != nil, "")
+	c.report("%+v prints frames", strings.Contains(fmt.Sprintf("%+v", outer), "main.checkStacks"), "")
+}
+
+func panics(f func()) (p bool) {
+	defer func() { p = recover() != nil }()
+	f()
+	return false
+}
+
+func main() {
+	check := flag.Bool("check", false, "verify the package instead of running the demo")
+	flag.Parse()
+	if !*check {
+		demo()
+		return
+	}
+	c := &checker{}
+	checkMatching(c)
+	checkRendering(c)
+	checkStacks(c)
+	if c.failed {
+		os.Exit(1)
+	}
+}
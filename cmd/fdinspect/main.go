@@ -0,0 +1,170 @@
+//go:build linux
+
+/*
+fdinspect prints the open file descriptors of a process, grouped by kind,
+with sockets resolved to addresses and states.
+
+	go run ./cmd/fdinspect -pid 1234
+	go run ./cmd/fdinspect -pid 1234 -json
+	go run ./cmd/fdinspect -demo   # open a few fds of every kind and inspect itself
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"golang/fdinspect"
+)
+
+func main() {
+	pid := flag.Int("pid", os.Getpid(), "process to inspect")
+	asJSON := flag.Bool("json", false, "print a JSON snapshot")
+	demo := flag.Bool("demo", false, "open sample fds in this process and show the HTTP handler output")
+	flag.Parse()
+
+	if *demo {
+		cleanup := openSamples()
+		defer cleanup()
+		*pid = os.Getpid()
+	}
+
+	if *asJSON {
+		s, err := fdinspect.Take(*pid, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(s)
+		return
+	}
+
+	fds, err := fdinspect.List(*pid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FD\tKIND\tTARGET\tDETAIL")
+	for _, f := range fds {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", f.Num, f.Kind, f.Target, detail(f))
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d fds:", len(fds))
+	for _, kv := range sorted(fdinspect.Count(fds)) {
+		fmt.Printf(" %s=%d", kv.k, kv.v)
+	}
+	fmt.Println()
+
+	if *demo {
+		srv := httptest.NewServer(fdinspect.Handler())
+		defer srv.Close()
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		fmt.Println("\nGET /debug/fds (note the extra sockets of the request itself):")
+		var s fdinspect.Snapshot
+		json.NewDecoder(resp.Body).Decode(&s)
+		fmt.Printf("total %d of limit %d, by kind %v, sockets %v\n", s.Total, s.Limit, s.ByKind, s.Sockets)
+	}
+}
+
+func detail(f fdinspect.FD) string {
+	var parts []string
+	if s := f.Socket; s != nil {
+		switch {
+		case s.Proto == "unix":
+			parts = append(parts, strings.TrimSpace("unix "+s.State+" "+s.Path))
+		case s.Remote != "" && !strings.HasSuffix(s.Remote, ":0"):
+			parts = append(parts, fmt.Sprintf("%s %s -> %s %s", s.Proto, s.Local, s.Remote, s.State))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %s %s", s.Proto, s.Local, s.State))
+		}
+	}
+	if f.Kind == fdinspect.File {
+		parts = append(parts, fmt.Sprintf("pos=%d", f.Pos))
+	}
+	for _, kv := range sortedInfo(f.Info) {
+		parts = append(parts, kv)
+	}
+	if !f.CloseOnExec() {
+		parts = append(parts, "no-cloexec")
+	}
+	return strings.Join(parts, " ")
+}
+
+type kv struct {
+	k fdinspect.Kind
+	v int
+}
+
+func sorted(m map[fdinspect.Kind]int) []kv {
+	out := make([]kv, 0, len(m))
+	for k, v := range m {
+		out = append(out, kv{k, v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].v > out[j].v || out[i].v == out[j].v && out[i].k < out[j].k })
+	return out
+}
+
+func sortedInfo(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k, v := range m {
+		out = append(out, k+"="+v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// openSamples opens one fd of several kinds so the listing has something to show.
+func openSamples() func() {
+	var closers []func()
+	must := func(err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	f, err := os.Open("/etc/hostname")
+	must(err)
+	closers = append(closers, func() { f.Close() })
+
+	r, w, err := os.Pipe()
+	must(err)
+	closers = append(closers, func() { r.Close(); w.Close() })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	must(err)
+	closers = append(closers, func() { ln.Close() })
+	c, err := net.Dial("tcp", ln.Addr().String())
+	must(err)
+	closers = append(closers, func() { c.Close() })
+
+	sock := fmt.Sprintf("%s/fdinspect-%d.sock", os.TempDir(), os.Getpid())
+	uln, err := net.Listen("unix", sock)
+	must(err)
+	closers = append(closers, func() { uln.Close() })
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	must(err)
+	closers = append(closers, func() { syscall.Close(epfd) })
+
+	return func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+genbench prints a few values from each gen sequence: the Fibonacci numbers
+of range_and_close.go over a channel and through Pull, primes, Collatz and a
+random walk, and two generators advanced in lock step.
+
+	go run ./cmd/genbench
+
+The cost of each way of consuming a sequence is measured by
+BenchmarkConsume in the gen package:
+
+	go test -bench Consume -cpu 1,4 ./gen
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"golang/gen"
+	"golang/itx"
+)
+
+func demo() {
+	ctx := context.Background()
+
+	// The channel version of range_and_close.go, with cancellation.
+	ctx1, cancel := context.WithCancel(ctx)
+	fmt.Print("fibonacci (chan):")
+	for v := range gen.Chan(ctx1, gen.Fibonacci(), 0) {
+		fmt.Print(" ", v)
+		if v.BitLen() > 10 {
+			break
+		}
+	}
+	cancel()
+	fmt.Println()
+
+	next, stop := gen.Pull(ctx, gen.Fibonacci())
+	var f100 string
+	for range 101 {
+		v, _ := next()
+		f100 = v.String()
+	}
+	stop()
+	fmt.Println("fibonacci(100) (pull):", f100)
+
+	fmt.Println("primes:", slices.Collect(itx.Take(gen.Primes(), 15)))
+	var p10k uint64
+	for i, p := range itx.Enumerate(gen.Primes()) {
+		if i == 9999 {
+			p10k = p
+			break
+		}
+	}
+	fmt.Println("10000th prime:", p10k)
+
+	fmt.Println("collatz(27) length:", len(slices.Collect(gen.Collatz(27))),
+		"peak:", slices.Max(slices.Collect(gen.Collatz(27))))
+	fmt.Println("random walk:", slices.Collect(itx.Take(gen.RandomWalk(rand.New(rand.NewPCG(1, 2)), 0), 12)))
+
+	// Two generators advanced in lock step, which nested range loops cannot do.
+	np, stopP := gen.Pull(ctx, gen.Primes())
+	nf, stopF := gen.Pull(ctx, gen.Fibonacci())
+	fmt.Print("(prime, fib) pairs:")
+	for range 6 {
+		p, _ := np()
+		f, _ := nf()
+		fmt.Printf(" (%d, %s)", p, f)
+	}
+	stopP()
+	stopF()
+	fmt.Println()
+}
+
+func main() {
+	demo()
+}
@@ -0,0 +1,227 @@
+/*
+geodemo indexes locations with the geo package and compares it with the
+linear scan a map[string]Vertex forces (see map/main.go).
+
+	go run ./cmd/geodemo
+	go run ./cmd/geodemo -n 500000 -precision 4
+	go run ./cmd/geodemo -in places.geojson -near 40.68,-74.40 -k 3
+	go run ./cmd/geodemo -out near.geojson
+
+The comparison places -n random points over a region the size of a country,
+runs the same radius and nearest-neighbour queries both ways and checks that
+the answers match before printing timings.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang/geo"
+)
+
+var places = map[string]geo.Point{
+	"Bell Labs":         {Lat: 40.68433, Long: -74.39967},
+	"Google":            {Lat: 37.42202, Long: -122.08408},
+	"Empire State":      {Lat: 40.74844, Long: -73.98566},
+	"Statue of Liberty": {Lat: 40.68925, Long: -74.04450},
+	"Golden Gate":       {Lat: 37.81993, Long: -122.47825},
+	"Eiffel Tower":      {Lat: 48.85837, Long: 2.29448},
+	"Big Ben":           {Lat: 51.50073, Long: -0.12463},
+	"Tokyo Tower":       {Lat: 35.65858, Long: 139.74543},
+	"Opera House":       {Lat: -33.85678, Long: 151.21530},
+	"Fiji":              {Lat: -17.71337, Long: 178.06503},
+	"Samoa":             {Lat: -13.75903, Long: -172.10463},
+}
+
+func parsePoint(s string) (geo.Point, error) {
+	lat, long, ok := strings.Cut(s, ",")
+	if !ok {
+		return geo.Point{}, fmt.Errorf("want lat,long, got %q", s)
+	}
+	var p geo.Point
+	var err1, err2 error
+	p.Lat, err1 = strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	p.Long, err2 = strconv.ParseFloat(strings.TrimSpace(long), 64)
+	if err1 != nil || err2 != nil || !p.Valid() {
+		return geo.Point{}, fmt.Errorf("invalid point %q", s)
+	}
+	return p, nil
+}
+
+func loadPlaces(path string, precision int) *geo.Index[string] {
+	ix := geo.NewIndex[string](precision)
+	if path == "" {
+		for name, p := range places {
+			ix.Add(p, name)
+		}
+		return ix
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	features, skipped, err := geo.ReadGeoJSON(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, ft := range features {
+		name, _ := ft.Properties["name"].(string)
+		if name == "" {
+			name = "#" + strconv.Itoa(i)
+		}
+		ix.Add(ft.Point, name)
+	}
+	fmt.Printf("loaded %d points from %s (%d features skipped)\n", len(features), path, skipped)
+	return ix
+}
+
+func lookup(ix *geo.Index[string], near geo.Point, k int, out string) {
+	fmt.Printf("%d nearest to %v (geohash %s):\n", k, near, geo.Encode(near, 7))
+	res := ix.Nearest(near, k)
+	for _, r := range res {
+		fmt.Printf("  %-18s %9.1f km  %s\n", r.Value, r.Distance/1000, geo.Encode(r.Point, 7))
+	}
+	fmt.Println("within 2000 km of Fiji, across the antimeridian:")
+	for _, r := range ix.Radius(places["Fiji"], 2_000_000) {
+		fmt.Printf("  %-18s %9.1f km\n", r.Value, r.Distance/1000)
+	}
+	if out == "" {
+		return
+	}
+	fs := []geo.Feature{{Point: near, Properties: map[string]any{"name": "query", "marker-color": "#d00"}}}
+	for _, r := range res {
+		fs = append(fs, geo.Feature{Point: r.Point, Properties: map[string]any{"name": r.Value, "distance_m": r.Distance}})
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := geo.WriteGeoJSON(f, fs); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("wrote", out)
+}
+
+func geohashDemo() {
+	p := places["Bell Labs"]
+	fmt.Println("geohash of Bell Labs by precision:")
+	for _, n := range []int{1, 3, 5, 7, 9} {
+		h := geo.Encode(p, n)
+		b, _ := geo.Decode(h)
+		fmt.Printf("  %-9s cell %.5f..%.5f x %.5f..%.5f, center off by %.1f m\n",
+			h, b.Min.Lat, b.Max.Lat, b.Min.Long, b.Max.Long, geo.Distance(p, b.Center()))
+	}
+}
+
+// linearRadius is the map scan the index replaces.
+func linearRadius(items []geo.Item[int], c geo.Point, r float64) []int {
+	var out []int
+	for _, it := range items {
+		if geo.Distance(c, it.Point) <= r {
+			out = append(out, it.Value)
+		}
+	}
+	return out
+}
+
+func linearNearest(items []geo.Item[int], c geo.Point, k int) []float64 {
+	d := make([]float64, len(items))
+	for i, it := range items {
+		d[i] = geo.Distance(c, it.Point)
+	}
+	slices.Sort(d)
+	return d[:min(k, len(d))]
+}
+
+func compare(n, precision, queries, k int, radius float64) {
+	r := rand.New(rand.NewPCG(7, 7))
+	// Roughly the continental United States.
+	randPoint := func() geo.Point {
+		return geo.Point{Lat: 25 + r.Float64()*24, Long: -125 + r.Float64()*58}
+	}
+	items := make([]geo.Item[int], n)
+	ix := geo.NewIndex[int](precision)
+	start := time.Now()
+	for i := range items {
+		items[i] = geo.Item[int]{Point: randPoint(), Value: i}
+		ix.Add(items[i].Point, i)
+	}
+	fmt.Printf("\n%d points, geohash precision %d, indexed in %v\n", n, precision, time.Since(start).Round(time.Millisecond))
+
+	centers := make([]geo.Point, queries)
+	for i := range centers {
+		centers[i] = randPoint()
+	}
+
+	var linT, ixT time.Duration
+	mismatches := 0
+	for _, c := range centers {
+		t := time.Now()
+		want := linearRadius(items, c, radius)
+		linT += time.Since(t)
+		t = time.Now()
+		got := ix.Radius(c, radius)
+		ixT += time.Since(t)
+		ids := make([]int, len(got))
+		for i, g := range got {
+			ids[i] = g.Value
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, want) {
+			mismatches++
+		}
+	}
+	fmt.Printf("  radius %3.0f km  linear %9v/query  index %9v/query  %5.0fx  mismatches %d\n",
+		radius/1000, linT/time.Duration(queries), ixT/time.Duration(queries), float64(linT)/float64(ixT), mismatches)
+
+	linT, ixT, mismatches = 0, 0, 0
+	for _, c := range centers {
+		t := time.Now()
+		want := linearNearest(items, c, k)
+		linT += time.Since(t)
+		t = time.Now()
+		got := ix.Nearest(c, k)
+		ixT += time.Since(t)
+		for i := range want {
+			if i >= len(got) || got[i].Distance != want[i] {
+				mismatches++
+				break
+			}
+		}
+	}
+	fmt.Printf("  nearest %-5d  linear %9v/query  index %9v/query  %5.0fx  mismatches %d\n",
+		k, linT/time.Duration(queries), ixT/time.Duration(queries), float64(linT)/float64(ixT), mismatches)
+}
+
+func main() {
+	in := flag.String("in", "", "GeoJSON file of named points, default a few landmarks")
+	out := flag.String("out", "", "write the nearest results as GeoJSON")
+	nearFlag := flag.String("near", "40.7128,-74.0060", "query point lat,long")
+	k := flag.Int("k", 4, "neighbours to return")
+	n := flag.Int("n", 200000, "random points for the comparison, 0 skips it")
+	precision := flag.Int("precision", 5, "geohash precision of the index")
+	queries := flag.Int("queries", 200, "queries per comparison")
+	radius := flag.Float64("radius", 25, "radius query size in km")
+	flag.Parse()
+
+	near, err := parsePoint(*nearFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	geohashDemo()
+	fmt.Println()
+	lookup(loadPlaces(*in, *precision), near, *k, *out)
+	if *n > 0 {
+		compare(*n, *precision, *queries, *k, *radius*1000)
+	}
+}
@@ -0,0 +1,325 @@
+/*
+geomcheck checks geometric identities of the geom package on random input
+mixed with numeric edge cases: zeros, negative zero, subnormals, values near
+the float64 limits, infinities and NaN.
+
+	go run ./cmd/geomcheck
+	go run ./cmd/geomcheck -n 100000 -seed 3
+
+Each property states what must hold for "reasonable" inputs and what must at
+least not panic or produce nonsense for extreme ones. A failure prints the
+inputs as %#v so they can be pasted into a reproduction.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"golang/geom"
+)
+
+var specials = []float64{
+	0, math.Copysign(0, -1), 1, -1, 0.5,
+	math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64,
+	1e-300, 1e300, -1e300, math.MaxFloat64, -math.MaxFloat64,
+	math.Inf(1), math.Inf(-1), math.NaN(),
+}
+
+type gen struct{ r *rand.Rand }
+
+// float returns mostly ordinary values and sometimes a special one.
+func (g gen) float() float64 {
+	if g.r.IntN(10) == 0 {
+		return specials[g.r.IntN(len(specials))]
+	}
+	return (g.r.Float64()*2 - 1) * math.Pow(10, float64(g.r.IntN(7)-3))
+}
+
+// finite returns ordinary values in [-1000, 1000].
+func (g gen) finite() float64 { return (g.r.Float64()*2 - 1) * 1000 }
+
+func (g gen) vec() geom.Vec2    { return geom.V(g.float(), g.float()) }
+func (g gen) finVec() geom.Vec2 { return geom.V(g.finite(), g.finite()) }
+
+func finite(vs ...geom.Vec2) bool {
+	for _, v := range vs {
+		if math.IsNaN(v.X) || math.IsNaN(v.Y) || math.IsInf(v.X, 0) || math.IsInf(v.Y, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func approx(a, b, tol float64) bool { return math.Abs(a-b) <= tol*max(1, math.Abs(a), math.Abs(b)) }
+
+type property struct {
+	name string
+	fn   func(g gen) string // "" when the property holds
+}
+
+var properties = []property{
+	{"normalize gives unit or zero", func(g gen) string {
+		v := g.vec()
+		n := v.Normalize()
+		l := n.Abs()
+		if !(n.IsZero() || approx(l, 1, 1e-12)) {
+			return fmt.Sprintf("%#v.Normalize() = %#v, length %v", v, n, l)
+		}
+		return ""
+	}},
+	{"abs does not overflow", func(g gen) string {
+		v := geom.V(math.MaxFloat64/2, math.MaxFloat64/2).Scale(g.r.Float64())
+		if math.IsInf(v.Abs(), 0) {
+			return fmt.Sprintf("%#v.Abs() overflowed", v)
+		}
+		return ""
+	}},
+	{"rotate preserves length and inverts", func(g gen) string {
+		v, th := g.finVec(), g.finite()
+		w := v.Rotate(th)
+		if !approx(w.Abs(), v.Abs(), 1e-9) || w.Rotate(-th).Dist(v) > 1e-9*max(1, v.Abs()) {
+			return fmt.Sprintf("%#v.Rotate(%v) = %#v", v, th, w)
+		}
+		return ""
+	}},
+	{"cross is anti-symmetric, dot symmetric", func(g gen) string {
+		a, b := g.vec(), g.vec()
+		if !finite(a, b) {
+			return ""
+		}
+		c1, c2 := a.Cross(b), b.Cross(a)
+		if !(c1 == -c2 || math.IsNaN(c1) && math.IsNaN(c2)) {
+			return fmt.Sprintf("Cross %#v %#v: %v vs %v", a, b, c1, c2)
+		}
+		d1, d2 := a.Dot(b), b.Dot(a)
+		if !(d1 == d2 || math.IsNaN(d1) && math.IsNaN(d2)) {
+			return fmt.Sprintf("Dot %#v %#v: %v vs %v", a, b, d1, d2)
+		}
+		return ""
+	}},
+	{"lerp hits endpoints", func(g gen) string {
+		a, b := g.finVec(), g.finVec()
+		if a.Lerp(b, 0) != a || !a.Lerp(b, 1).Eq(b) {
+			return fmt.Sprintf("Lerp %#v %#v: %#v %#v", a, b, a.Lerp(b, 0), a.Lerp(b, 1))
+		}
+		return ""
+	}},
+	{"transform inverse round trips", func(g gen) string {
+		t := geom.Rotate(g.finite()).
+			Then(geom.Scale(g.finite()/100, g.finite()/100)).
+			Then(geom.Shear(g.r.Float64(), g.r.Float64())).
+			Then(geom.Translate(g.finite(), g.finite()))
+		inv, ok := t.Invert()
+		if !ok {
+			return ""
+		}
+		p := g.finVec()
+		back := inv.Apply(t.Apply(p))
+		if back.Dist(p) > 1e-6*max(1, p.Abs()) {
+			return fmt.Sprintf("%v then inverse moved %#v to %#v", t, p, back)
+		}
+		return ""
+	}},
+	{"singular transforms have no inverse", func(g gen) string {
+		k := g.finite()
+		t := geom.Transform{A: 1, B: k, C: 2, D: 2 * k, E: g.finite(), F: g.finite()}
+		if _, ok := t.Invert(); ok {
+			return fmt.Sprintf("%v inverted", t)
+		}
+		if _, ok := (geom.Transform{A: math.NaN(), D: 1}).Invert(); ok {
+			return "NaN transform inverted"
+		}
+		return ""
+	}},
+	{"transform scales area by det", func(g gen) string {
+		poly := geom.ConvexHull(points(g, 8))
+		t := geom.Rotate(g.finite()).Then(geom.Scale(g.finite()/100, g.finite()/100))
+		got := poly.Transform(t).SignedArea()
+		want := poly.SignedArea() * t.Det()
+		if !approx(got, want, 1e-6) {
+			return fmt.Sprintf("area %v after %v, want %v", got, t, want)
+		}
+		return ""
+	}},
+	{"hull is convex and contains every point", func(g gen) string {
+		pts := points(g, 3+g.r.IntN(40))
+		hull := geom.ConvexHull(pts)
+		if len(hull) >= 3 && (!hull.IsConvex() || hull.SignedArea() <= 0) {
+			return fmt.Sprintf("hull %v not convex/ccw", hull)
+		}
+		for _, p := range pts {
+			if len(hull) >= 3 && !hull.Contains(p) {
+				return fmt.Sprintf("hull %v misses %#v", hull, p)
+			}
+		}
+		return ""
+	}},
+	{"hull survives special values", func(g gen) string {
+		pts := make([]geom.Vec2, 10)
+		for i := range pts {
+			pts[i] = g.vec()
+		}
+		hull := geom.ConvexHull(pts)
+		for _, v := range hull {
+			if math.IsNaN(v.X) || math.IsNaN(v.Y) {
+				return fmt.Sprintf("NaN in hull of %#v", pts)
+			}
+		}
+		return ""
+	}},
+	{"centroid of convex polygon is inside", func(g gen) string {
+		hull := geom.ConvexHull(points(g, 3+g.r.IntN(20)))
+		if len(hull) < 3 || hull.Area() < 1e-6 {
+			return ""
+		}
+		if c := hull.Centroid(); !hull.Contains(c) {
+			return fmt.Sprintf("centroid %v outside %v", c, hull)
+		}
+		return ""
+	}},
+	{"area is orientation independent", func(g gen) string {
+		hull := geom.ConvexHull(points(g, 10))
+		rev := make(geom.Polygon, len(hull))
+		for i, v := range hull {
+			rev[len(hull)-1-i] = v
+		}
+		if !approx(hull.SignedArea(), -rev.SignedArea(), 1e-12) {
+			return fmt.Sprintf("%v vs reversed: %v %v", hull, hull.SignedArea(), rev.SignedArea())
+		}
+		return ""
+	}},
+	{"area far from the origin", func(g gen) string {
+		// A unit square moved far away must keep area 1; the naive shoelace
+		// formula loses every digit at 1e8.
+		off := geom.V(1e8*g.r.Float64(), 1e8*g.r.Float64())
+		sq := geom.Polygon{off, off.Add(geom.V(1, 0)), off.Add(geom.V(1, 1)), off.Add(geom.V(0, 1))}
+		if !approx(sq.Area(), 1, 1e-6) {
+			return fmt.Sprintf("unit square at %v has area %v", off, sq.Area())
+		}
+		return ""
+	}},
+	{"intersection lies on both segments", func(g gen) string {
+		s := geom.Segment{A: g.finVec(), B: g.finVec()}
+		o := geom.Segment{A: g.finVec(), B: g.finVec()}
+		p, ok := s.Intersect(o)
+		q, ok2 := o.Intersect(s)
+		if ok != ok2 {
+			return fmt.Sprintf("asymmetric: %v %v -> %v, %v", s, o, ok, ok2)
+		}
+		if ok && (s.DistTo(p) > 1e-6 || o.DistTo(p) > 1e-6 || q.Dist(p) > 1e-6) {
+			return fmt.Sprintf("%v x %v = %#v (other order %#v)", s, o, p, q)
+		}
+		return ""
+	}},
+	{"touching and collinear segments", func(g gen) string {
+		a, dir := g.finVec(), g.finVec()
+		s := geom.Segment{A: a, B: a.Add(dir)}
+		// Overlapping collinear piece.
+		o := geom.Segment{A: a.Add(dir.Scale(0.5)), B: a.Add(dir.Scale(2))}
+		if p, ok := s.Intersect(o); !ok || s.DistTo(p) > 1e-6 {
+			return fmt.Sprintf("overlap %v %v: %v %v", s, o, p, ok)
+		}
+		// Disjoint collinear piece.
+		o = geom.Segment{A: a.Add(dir.Scale(1.5)), B: a.Add(dir.Scale(2))}
+		if p, ok := s.Intersect(o); ok && !dir.IsZero() {
+			return fmt.Sprintf("disjoint collinear %v %v met at %v", s, o, p)
+		}
+		// Sharing an endpoint.
+		o = geom.Segment{A: s.B, B: s.B.Add(dir.Perp())}
+		if _, ok := s.Intersect(o); !ok {
+			return fmt.Sprintf("touching %v %v missed", s, o)
+		}
+		return ""
+	}},
+	{"formatter", func(g gen) string {
+		v := geom.V(1, 2.5)
+		for format, want := range map[string]string{
+			"%v":      "(1, 2.5)",
+			"%+v":     "{X:1 Y:2.5}",
+			"%#v":     "geom.Vec2{X:1, Y:2.5}",
+			"%.2f":    "(1.00, 2.50)",
+			"%6.1f":   "(   1.0,    2.5)",
+			"%+.0f":   "(+1, +2)",
+			"%e":      "(1.000000e+00, 2.500000e+00)",
+			"%d":      "%!d(geom.Vec2=(1, 2.5))",
+			"%v|%.1f": "(1, 2.5)|(1.0, 2.5)",
+		} {
+			args := []any{v}
+			if strings.Count(format, "%") == 2 {
+				args = append(args, v)
+			}
+			if got := fmt.Sprintf(format, args...); got != want {
+				return fmt.Sprintf("Sprintf(%q) = %q, want %q", format, got, want)
+			}
+		}
+		if got := fmt.Sprintf("%v", geom.V(math.NaN(), math.Inf(-1))); got != "(NaN, -Inf)" {
+			return fmt.Sprintf("special values formatted as %q", got)
+		}
+		return ""
+	}},
+	{"point in concave polygon", func(g gen) string {
+		// An L shape: the notch at (1.5, 1.5) is outside.
+		l := geom.Polygon{geom.V(0, 0), geom.V(2, 0), geom.V(2, 1), geom.V(1, 1), geom.V(1, 2), geom.V(0, 2)}
+		p := geom.V(g.r.Float64()*3-0.5, g.r.Float64()*3-0.5)
+		want := p.X >= 0 && p.Y >= 0 && p.X <= 2 && p.Y <= 2 && (p.X <= 1 || p.Y <= 1)
+		if l.Contains(p) != want {
+			return fmt.Sprintf("L.Contains(%#v) = %v", p, !want)
+		}
+		if !l.Contains(geom.V(1, 1.5)) || !l.Contains(geom.V(2, 0)) {
+			return "boundary points not contained"
+		}
+		return ""
+	}},
+}
+
+func points(g gen, n int) []geom.Vec2 {
+	pts := make([]geom.Vec2, n)
+	for i := range pts {
+		pts[i] = g.finVec()
+	}
+	return pts
+}
+
+func main() {
+	n := flag.Int("n", 20000, "cases per property")
+	seed := flag.Uint64("seed", 0, "random seed, 0 picks one")
+	flag.Parse()
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+	fmt.Printf("seed %d, %d cases per property\n", *seed, *n)
+
+	failed := false
+	for i, p := range properties {
+		g := gen{rand.New(rand.NewPCG(*seed, uint64(i)))}
+		msg := run(p, g, *n)
+		if msg == "" {
+			fmt.Printf("ok    %s\n", p.name)
+			continue
+		}
+		failed = true
+		fmt.Printf("FAIL  %s: %s\n", p.name, msg)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run(p property, g gen, n int) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint("panic: ", r)
+		}
+	}()
+	for range n {
+		if msg = p.fn(g); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+/*
+hdrdemo exercises the hdr histogram.
+
+	go run ./cmd/hdrdemo                    accuracy, concurrency, coordinated omission, encoding
+	go run ./cmd/hdrdemo -emit > h.b64      record channel round trips, print the encoded histogram
+	go run ./cmd/hdrdemo -decode < h.b64    print the distribution of a shipped histogram
+
+-emit and -decode show the intended use of Encode: each process records
+locally and ships a few hundred bytes of base64 to whoever aggregates.
+*/
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang/hdr"
+)
+
+const hour = int64(time.Hour)
+
+func accuracy(sigfigs int) bool {
+	h := hdr.MustNew(1, hour, sigfigs)
+	r := rand.New(rand.NewPCG(1, 1))
+	data := make([]int64, 1_000_000)
+	for i := range data {
+		// Log-normal around 1ms with a long tail, in nanoseconds.
+		data[i] = int64(math.Exp(r.NormFloat64()*1.5) * 1e6)
+		h.Record(data[i])
+	}
+	slices.Sort(data)
+	ps := []float64{50, 90, 99, 99.9, 99.99, 100}
+	got := h.ValuesAt(ps...)
+	bound := math.Pow10(-sigfigs)
+	ok := true
+	fmt.Printf("accuracy, %d significant figures, %d values, %d KiB:\n", sigfigs, len(data), h.MemorySize()/1024)
+	for i, p := range ps {
+		exact := data[min(len(data)-1, int(math.Ceil(p/100*float64(len(data))))-1)]
+		rel := float64(got[i]-exact) / float64(exact)
+		status := "ok"
+		if rel < 0 || rel > bound {
+			status = "FAIL"
+			ok = false
+		}
+		fmt.Printf("  p%-6g %14d ns  exact %14d  error %+.5f%%  %s\n", p, got[i], exact, rel*100, status)
+	}
+	var sum float64
+	for _, v := range data {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(data))
+	fmt.Printf("  mean %.0f (exact %.0f), stddev %.0f\n", h.Mean(), mean, h.StdDev())
+	return ok
+}
+
+func concurrency(perWorker int) bool {
+	workers := max(4, runtime.GOMAXPROCS(0))
+	values := func(w int) func(i int) int64 {
+		return func(i int) int64 { return int64((i*7919+w*104729)%1_000_000 + 1) }
+	}
+
+	shared := hdr.MustNew(1, hour, 3)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := values(w)
+			for i := range perWorker {
+				shared.Record(v(i))
+			}
+		}()
+	}
+	wg.Wait()
+	sharedT := time.Since(start)
+
+	start = time.Now()
+	locals := make([]*hdr.Histogram, workers)
+	for w := range workers {
+		locals[w] = hdr.MustNew(1, hour, 3)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := values(w)
+			for i := range perWorker {
+				locals[w].Record(v(i))
+			}
+		}()
+	}
+	wg.Wait()
+	merged := hdr.MustNew(1, hour, 3)
+	for _, l := range locals {
+		merged.Merge(l)
+	}
+	localT := time.Since(start)
+
+	n := float64(workers * perWorker)
+	fmt.Printf("\nconcurrency, %d goroutines x %d records:\n", workers, perWorker)
+	fmt.Printf("  one shared histogram     %6.1f ns/record\n", float64(sharedT.Nanoseconds())/n)
+	fmt.Printf("  per goroutine + merge    %6.1f ns/record\n", float64(localT.Nanoseconds())/n)
+	same := shared.TotalCount() == merged.TotalCount() &&
+		slices.Equal(shared.ValuesAt(50, 99, 100), merged.ValuesAt(50, 99, 100)) &&
+		shared.Min() == merged.Min() && shared.Max() == merged.Max()
+	fmt.Printf("  identical distributions: %v (count %d)\n", same, merged.TotalCount())
+
+	// A histogram with a different layout is merged value by value.
+	coarse := hdr.MustNew(1000, hour, 2)
+	if err := coarse.Merge(merged); err != nil {
+		fmt.Println("  merge into coarse:", err)
+		return false
+	}
+	fmt.Printf("  merged into 2-digit histogram: p99 %d vs %d\n", coarse.ValueAt(99), merged.ValueAt(99))
+	return same && coarse.TotalCount() == merged.TotalCount()
+}
+
+// omission simulates a closed-loop load generator sending every 10ms to a
+// service that answers in 1ms but stalls for 2s once.
+func omission() {
+	const interval = 10 * int64(time.Millisecond)
+	naive := hdr.MustNew(1, hour, 3)
+	corrected := hdr.MustNew(1, hour, 3)
+	for i := range 10_000 {
+		v := int64(time.Millisecond)
+		if i == 5000 {
+			v = 2 * int64(time.Second)
+		}
+		naive.Record(v)
+		corrected.RecordCorrected(v, interval)
+	}
+	fmt.Println("\ncoordinated omission, 10k requests every 10ms, one 2s stall:")
+	for _, p := range []float64{50, 99, 99.9} {
+		fmt.Printf("  p%-5g naive %8.1f ms   corrected %8.1f ms\n", p,
+			float64(naive.ValueAt(p))/1e6, float64(corrected.ValueAt(p))/1e6)
+	}
+}
+
+func encoding() bool {
+	h := hdr.MustNew(1, hour, 3)
+	for i := range 100_000 {
+		h.Record(int64(math.Exp(float64(i%1000)/100) * 1000))
+	}
+	bin, _ := h.MarshalBinary()
+	s, _ := h.Encode()
+	back, err := hdr.Decode(s)
+	ok := err == nil && back.TotalCount() == h.TotalCount() &&
+		slices.Equal(back.ValuesAt(0, 50, 90, 99, 100), h.ValuesAt(0, 50, 90, 99, 100)) &&
+		back.Min() == h.Min() && back.Max() == h.Max() && back.Mean() == h.Mean()
+	fmt.Printf("\nencoding: %d KiB of counts -> %d bytes binary, %d base64; round trip equal: %v\n",
+		h.MemorySize()/1024, len(bin), len(s), ok)
+	_, err = hdr.Decode("not a histogram")
+	fmt.Println("  garbage rejected:", err)
+	return ok && err != nil
+}
+
+func emit(n int) {
+	h := hdr.MustNew(1, hour, 3)
+	ping, pong := make(chan struct{}), make(chan struct{})
+	go func() {
+		for range ping {
+			pong <- struct{}{}
+		}
+	}()
+	for range n {
+		start := time.Now()
+		ping <- struct{}{}
+		<-pong
+		h.Record(time.Since(start).Nanoseconds())
+	}
+	close(ping)
+	s, err := h.Encode()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(s)
+}
+
+func decode(r io.Reader) {
+	in, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		log.Fatal(err)
+	}
+	h, err := hdr.Decode(strings.TrimSpace(string(in)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("values in microseconds")
+	h.WritePercentiles(os.Stdout, 5, 1e3)
+}
+
+func main() {
+	doEmit := flag.Bool("emit", false, "record channel round trips and print the base64 histogram")
+	doDecode := flag.Bool("decode", false, "read a base64 histogram from stdin and print its percentiles")
+	n := flag.Int("n", 200_000, "records per goroutine, or round trips for -emit")
+	flag.Parse()
+
+	switch {
+	case *doEmit:
+		emit(*n)
+	case *doDecode:
+		decode(os.Stdin)
+	default:
+		ok := accuracy(3)
+		ok = accuracy(2) && ok
+		ok = concurrency(*n) && ok
+		omission()
+		ok = encoding() && ok
+		if !ok {
+			os.Exit(1)
+		}
+	}
+}
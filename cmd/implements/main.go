@@ -0,0 +1,223 @@
+/*
+implements lists, for each type a package declares, which interfaces in
+scope its value and pointer method sets satisfy, and why not where it comes
+close.
+
+interface/main.go fails to compile at
+
+	a = v
+
+because Abs is declared on *Vertex, so the method set of Vertex is empty
+and only &v is an Abser. The compiler says so for that one assignment; this
+tool says it for every type and interface at once:
+
+	go run ./cmd/implements ./interface/main.go
+
+	MyFloat  Abser  MyFloat and *MyFloat
+	Vertex   Abser  *Vertex only: Abs has a pointer receiver
+
+and for near misses
+
+	MyFloat  fmt.Stringer   neither: String has type func() int, want func() string
+	Circle   Shape          neither: missing Scale
+
+The interfaces in scope are those the package declares, those exported by
+the packages it imports, and error. Empty interfaces and constraints are
+left out. A type that satisfies neither form of an interface is listed
+when it has every method by name and some with the wrong signature, or
+when it lacks just one method of an interface that has several.
+
+	go run ./cmd/implements ./geom ./matrix
+
+go test ./cmd/implements compares the output for testdata/shapes with
+testdata/shapes.golden.
+
+Packages are loaded and type checked from source, so a package with errors,
+such as interface/main.go, is still reported; the errors go to stderr.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// iface is an interface in scope, with the name it is written as.
+type iface struct {
+	name string
+	typ  *types.Interface
+}
+
+func load(dir string, patterns []string, errs io.Writer) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pkgs {
+		for _, e := range p.Errors {
+			fmt.Fprintln(errs, e)
+		}
+	}
+	return pkgs, nil
+}
+
+// scope collects the interfaces visible in pkg, sorted by name.
+func scope(pkg *types.Package) []iface {
+	var out []iface
+	add := func(obj types.Object, name string) {
+		tn, ok := obj.(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			return
+		}
+		if n, ok := tn.Type().(*types.Named); ok && n.TypeParams().Len() > 0 {
+			return
+		}
+		it, ok := tn.Type().Underlying().(*types.Interface)
+		if !ok || it.NumMethods() == 0 || !it.IsMethodSet() {
+			return
+		}
+		out = append(out, iface{name, it})
+	}
+	for _, name := range pkg.Scope().Names() {
+		add(pkg.Scope().Lookup(name), name)
+	}
+	for _, imp := range pkg.Imports() {
+		for _, name := range imp.Scope().Names() {
+			if obj := imp.Scope().Lookup(name); obj.Exported() {
+				add(obj, imp.Name()+"."+name)
+			}
+		}
+	}
+	add(types.Universe.Lookup("error"), "error")
+	slices.SortFunc(out, func(a, b iface) int { return strings.Compare(a.name, b.name) })
+	return out
+}
+
+// verdict says whether t and *t satisfy it, or why not, and whether the
+// line is worth printing.
+func verdict(t *types.Named, it *types.Interface, qf types.Qualifier) (string, bool) {
+	name := t.Obj().Name()
+	ptr := types.NewPointer(t)
+	switch {
+	case types.Implements(t, it):
+		return name + " and *" + name, true
+	case types.Implements(ptr, it):
+		var recv []string
+		values := types.NewMethodSet(t)
+		for i := range it.NumMethods() {
+			m := it.Method(i)
+			if values.Lookup(m.Pkg(), m.Name()) == nil {
+				recv = append(recv, m.Name())
+			}
+		}
+		return fmt.Sprintf("*%s only: %s %s", name, list(recv), plural(len(recv), "has a pointer receiver", "have pointer receivers")), true
+	}
+
+	// Near misses: look each method up by name in the larger method set.
+	all := types.NewMethodSet(ptr)
+	var missing, wrong []string
+	for i := range it.NumMethods() {
+		m := it.Method(i)
+		sel := all.Lookup(m.Pkg(), m.Name())
+		if sel == nil {
+			missing = append(missing, m.Name())
+			continue
+		}
+		if !types.Identical(sel.Type(), m.Type()) {
+			wrong = append(wrong, fmt.Sprintf("%s has type %s, want %s",
+				m.Name(), types.TypeString(sel.Type(), qf), types.TypeString(m.Type(), qf)))
+		}
+	}
+	n := it.NumMethods()
+	if !(len(missing) == 0 || n > 1 && len(missing) == 1 && len(wrong) == 0) {
+		return "", false
+	}
+	var why []string
+	if len(missing) > 0 {
+		why = append(why, "missing "+list(missing))
+	}
+	why = append(why, wrong...)
+	return "neither: " + strings.Join(why, "; "), true
+}
+
+func list(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// report writes one table per package: type, interface, verdict.
+func report(w io.Writer, pkgs []*packages.Package) {
+	for i, p := range pkgs {
+		if p.Types == nil {
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\n", p.PkgPath)
+		qf := types.RelativeTo(p.Types)
+		ifaces := scope(p.Types)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, name := range p.Types.Scope().Names() {
+			tn, ok := p.Types.Scope().Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			t, ok := tn.Type().(*types.Named)
+			if !ok || t.TypeParams().Len() > 0 {
+				continue
+			}
+			switch t.Underlying().(type) {
+			case *types.Interface, *types.Pointer:
+				continue
+			}
+			for _, it := range ifaces {
+				if v, ok := verdict(t, it.typ, qf); ok {
+					fmt.Fprintf(tw, "  %s\t%s\t%s\n", name, it.name, v)
+				}
+			}
+		}
+		tw.Flush()
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: implements packages...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	pkgs, err := load("", flag.Args(), os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	report(os.Stdout, pkgs)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShapesGolden(t *testing.T) {
+	var out, errs strings.Builder
+	pkgs, err := load("", []string{"./testdata/shapes"}, &errs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs.Len() > 0 {
+		t.Errorf("load errors:\n%s", errs.String())
+	}
+	report(&out, pkgs)
+	want, err := os.ReadFile(filepath.Join("testdata", "shapes.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != string(want) {
+		t.Errorf("report differs from testdata/shapes.golden\n--- got:\n%s--- want:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,58 @@
+// Package shapes is the fixture for implements -check.
+package shapes
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+type Abser interface {
+	Abs() float64
+}
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+	Scale(f float64)
+}
+
+// Vertex is interface/main.go's: Abs has a pointer receiver.
+type Vertex struct{ X, Y float64 }
+
+func (v *Vertex) Abs() float64 { return math.Hypot(v.X, v.Y) }
+
+type MyFloat float64
+
+func (f MyFloat) Abs() float64 { return math.Abs(float64(f)) }
+func (f MyFloat) String() int  { return int(f) }
+
+// Rect is a Shape only through a pointer, and a Stringer either way.
+type Rect struct{ W, H float64 }
+
+func (r Rect) Area() float64      { return r.W * r.H }
+func (r Rect) Perimeter() float64 { return 2 * (r.W + r.H) }
+func (r *Rect) Scale(f float64)   { r.W *= f; r.H *= f }
+func (r Rect) String() string     { return fmt.Sprintf("%gx%g", r.W, r.H) }
+
+// Circle has two of Shape's three methods.
+type Circle struct{ R float64 }
+
+func (c Circle) Area() float64      { return math.Pi * c.R * c.R }
+func (c Circle) Perimeter() float64 { return 2 * math.Pi * c.R }
+
+// Buffer reads with the wrong signature and writes correctly.
+type Buffer struct{ b []byte }
+
+func (b *Buffer) Read() []byte { return b.b }
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.b = append(b.b, p...)
+	return len(p), nil
+}
+
+// Failure is an error either way.
+type Failure string
+
+func (f Failure) Error() string { return string(f) }
+
+var _ io.Writer = (*Buffer)(nil)
@@ -0,0 +1,291 @@
+/*
+itxdemo walks through the itx combinators and then checks their behaviour.
+
+	go run ./cmd/itxdemo          examples followed by the checks
+	go run ./cmd/itxdemo -check   checks only, exit status 1 on failure
+
+The checks count how many values each combinator pulls from an instrumented
+infinite source: after a break, or once Take has its n values, no further
+values may be requested. A yield called after it returned false would also
+make the runtime panic, which the checks recover and report.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"iter"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang/itx"
+)
+
+// naturals yields 0, 1, 2, ... forever and counts how many it produced.
+func naturals(pulled *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func examples() {
+	var n int
+	evens := itx.Filter(naturals(&n), func(v int) bool { return v%2 == 0 })
+	squares := itx.Map(evens, func(v int) int { return v * v })
+	fmt.Println("first 5 even squares:", slices.Collect(itx.Take(squares, 5)))
+	fmt.Println("  values pulled from the source:", n)
+
+	words := slices.Values(strings.Fields("the quick brown fox jumps over the lazy dog"))
+	total := itx.Reduce(words, 0, func(acc int, w string) int { return acc + len(w) })
+	fmt.Println("letters in sentence:", total)
+
+	for i, w := range itx.Zip(naturals(new(int)), itx.Skip(words, 6)) {
+		fmt.Printf("  word %d: %s\n", i, w)
+	}
+
+	fmt.Println("chunks of 4:", slices.Collect(itx.Chunk(itx.Take(naturals(new(int)), 10), 4)))
+	fmt.Println("windows of 3:", slices.Collect(itx.Window(itx.Take(naturals(new(int)), 6), 3)))
+
+	nested := slices.Values([]iter.Seq[int]{slices.Values([]int{1, 2}), slices.Values([]int{}), slices.Values([]int{3})})
+	fmt.Println("flatten:", slices.Collect(itx.Flatten(nested)))
+
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := range 5 {
+			ch <- i * 10
+		}
+	}()
+	fmt.Println("from channel:", slices.Collect(itx.FromChan(ch)))
+
+	// Slow work on 4 workers still comes back in order.
+	delay := func(v int) time.Duration { return time.Duration(10-v) * 10 * time.Millisecond }
+	slow := func(v int) string {
+		time.Sleep(delay(v))
+		return fmt.Sprint("r", v)
+	}
+	input := itx.Take(naturals(new(int)), 8)
+	sequential := itx.Reduce(input, time.Duration(0), func(d time.Duration, v int) time.Duration { return d + delay(v) })
+	start := time.Now()
+	res := slices.Collect(itx.MapOrdered(input, 4, slow))
+	fmt.Printf("MapOrdered: %v in %v (sequential would take %v)\n",
+		res, time.Since(start).Round(10*time.Millisecond), sequential)
+}
+
+type result struct {
+	name string
+	err  string
+}
+
+func check(name string, fn func() string) (r result) {
+	r.name = name
+	defer func() {
+		if p := recover(); p != nil {
+			r.err = fmt.Sprint("panic: ", p)
+		}
+	}()
+	r.err = fn()
+	return r
+}
+
+// pulls runs seq (built over an instrumented source), breaks after k values
+// and reports how many source values were produced.
+func pulls[T any](build func(iter.Seq[int]) iter.Seq[T], k int) (got []T, pulled int) {
+	for v := range build(naturals(&pulled)) {
+		if len(got) == k {
+			break
+		}
+		got = append(got, v)
+	}
+	return got, pulled
+}
+
+func checks() []result {
+	expectPulled := func(pulled, want int) string {
+		if pulled != want {
+			return fmt.Sprintf("pulled %d values, want %d", pulled, want)
+		}
+		return ""
+	}
+	return []result{
+		check("Map stops on break", func() string {
+			_, p := pulls(func(s iter.Seq[int]) iter.Seq[int] {
+				return itx.Map(s, func(v int) int { return v + 1 })
+			}, 3)
+			return expectPulled(p, 4)
+		}),
+		check("Filter stops on break", func() string {
+			got, p := pulls(func(s iter.Seq[int]) iter.Seq[int] {
+				return itx.Filter(s, func(v int) bool { return v%3 == 0 })
+			}, 3)
+			if !slices.Equal(got, []int{0, 3, 6}) {
+				return fmt.Sprint("got ", got)
+			}
+			return expectPulled(p, 10)
+		}),
+		check("Take does not over-pull", func() string {
+			var p int
+			got := slices.Collect(itx.Take(naturals(&p), 5))
+			if len(got) != 5 {
+				return fmt.Sprint("got ", got)
+			}
+			return expectPulled(p, 5)
+		}),
+		check("Skip then break", func() string {
+			got, p := pulls(func(s iter.Seq[int]) iter.Seq[int] { return itx.Skip(s, 5) }, 2)
+			if !slices.Equal(got, []int{5, 6}) {
+				return fmt.Sprint("got ", got)
+			}
+			return expectPulled(p, 8)
+		}),
+		check("Chunk stops on break", func() string {
+			got, p := pulls(func(s iter.Seq[int]) iter.Seq[[]int] { return itx.Chunk(s, 3) }, 2)
+			if fmt.Sprint(got) != "[[0 1 2] [3 4 5]]" {
+				return fmt.Sprint("got ", got)
+			}
+			return expectPulled(p, 9)
+		}),
+		check("Window slices are independent", func() string {
+			got, _ := pulls(func(s iter.Seq[int]) iter.Seq[[]int] { return itx.Window(s, 3) }, 3)
+			if fmt.Sprint(got) != "[[0 1 2] [1 2 3] [2 3 4]]" {
+				return fmt.Sprint("got ", got)
+			}
+			return ""
+		}),
+		check("Zip stops the pulled side", func() string {
+			stopped := false
+			b := func(yield func(string) bool) {
+				defer func() { stopped = true }()
+				for _, s := range []string{"a", "b", "c", "d"} {
+					if !yield(s) {
+						return
+					}
+				}
+			}
+			n := 0
+			for range itx.Zip(slices.Values([]int{1, 2}), b) {
+				n++
+			}
+			if n != 2 || !stopped {
+				return fmt.Sprintf("yielded %d pairs, b stopped: %v", n, stopped)
+			}
+			return ""
+		}),
+		check("Flatten stops inside inner seq", func() string {
+			var p int
+			outer := func(yield func(iter.Seq[int]) bool) {
+				for range 3 {
+					if !yield(itx.Take(naturals(&p), 4)) {
+						return
+					}
+				}
+			}
+			got := slices.Collect(itx.Take(itx.Flatten(outer), 6))
+			if len(got) != 6 {
+				return fmt.Sprint("got ", got)
+			}
+			return expectPulled(p, 6)
+		}),
+		check("ToChan goroutine exits on cancel", func() string {
+			before := runtime.NumGoroutine()
+			ctx, cancel := context.WithCancel(context.Background())
+			ch := itx.ToChan(ctx, naturals(new(int)), 0)
+			for v := range itx.FromChan(ch) {
+				if v == 3 {
+					break
+				}
+			}
+			cancel()
+			return settle(before)
+		}),
+		check("MapOrdered keeps order", func() string {
+			got := slices.Collect(itx.MapOrdered(slices.Values([]int{5, 1, 4, 2, 3}), 3, func(v int) int {
+				time.Sleep(time.Duration(v) * time.Millisecond)
+				return v * 2
+			}))
+			if !slices.Equal(got, []int{10, 2, 8, 4, 6}) {
+				return fmt.Sprint("got ", got)
+			}
+			return ""
+		}),
+		check("MapOrdered bounds concurrency", func() string {
+			var (
+				mu           sync.Mutex
+				active, peak int
+			)
+			track := func(d int) {
+				mu.Lock()
+				active += d
+				peak = max(peak, active)
+				mu.Unlock()
+			}
+			var p int
+			for range itx.Take(itx.MapOrdered(naturals(&p), 4, func(v int) int {
+				track(1)
+				time.Sleep(time.Millisecond)
+				track(-1)
+				return v
+			}), 50) {
+			}
+			if peak > 4 {
+				return fmt.Sprintf("peak concurrency %d, want <= 4", peak)
+			}
+			if p > 50+2*4 {
+				return fmt.Sprintf("source read %d values ahead for 50 results", p)
+			}
+			return ""
+		}),
+		check("MapOrdered leaves no goroutines", func() string {
+			before := runtime.NumGoroutine()
+			for v := range itx.MapOrdered(naturals(new(int)), 8, func(v int) int { return v }) {
+				if v == 100 {
+					break
+				}
+			}
+			return settle(before)
+		}),
+	}
+}
+
+// settle waits briefly for goroutines to exit back to the before count.
+func settle(before int) string {
+	for range 50 {
+		if runtime.NumGoroutine() <= before {
+			return ""
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	return fmt.Sprintf("%d goroutines, want %d", runtime.NumGoroutine(), before)
+}
+
+func main() {
+	onlyCheck := flag.Bool("check", false, "run the checks only")
+	flag.Parse()
+
+	if !*onlyCheck {
+		examples()
+		fmt.Println()
+	}
+	failed := 0
+	for _, r := range checks() {
+		if r.err == "" {
+			fmt.Printf("ok    %s\n", r.name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s: %s\n", r.name, r.err)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+lessonlint runs the analyzers under lint/, which look for the mistakes some
+of the lessons make on purpose, or by accident:
+
+	sleepsync    time.Sleep waiting for goroutines     concurrency/mutex.go
+	crossunlock  Unlock in a goroutine the locker started  goroutin/mutex.go
+	deferloop    defer inside a loop                   defer/main.go
+	afterloop    time.After in a select in a loop      leak pattern #7
+	sendleak     return leaving a sender blocked       leak pattern #2
+	mixedrecv    value and pointer receivers mixed     method/pointer.go
+	largecopy    large values copied by value          concept/Pass_By_Value_And_pointer
+
+It is a multichecker, so it takes packages and the usual analysis flags:
+
+	go run ./cmd/lessonlint ./sse/... ./ws/...     report
+	go run ./cmd/lessonlint -fix ./...              apply the suggested fixes
+	go run ./cmd/lessonlint -largecopy.threshold=1024 ./...
+	go run ./cmd/lessonlint -sleepsync ./...        run one analyzer
+	go run ./cmd/lessonlint -check                  run each analyzer over the
+	                                                fixtures in its testdata
+
+-check compares the diagnostics with the // want comments in the fixtures,
+and sendleak's fixes with the .golden file, as analysistest does in a test.
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"golang/lint/afterloop"
+	"golang/lint/crossunlock"
+	"golang/lint/deferloop"
+	"golang/lint/largecopy"
+	"golang/lint/mixedrecv"
+	"golang/lint/sendleak"
+	"golang/lint/sleepsync"
+)
+
+var analyzers = []*analysis.Analyzer{
+	sleepsync.Analyzer,
+	crossunlock.Analyzer,
+	deferloop.Analyzer,
+	afterloop.Analyzer,
+	sendleak.Analyzer,
+	mixedrecv.Analyzer,
+	largecopy.Analyzer,
+}
+
+type checker struct{ failed bool }
+
+func (c *checker) report(name string, ok bool, detail string) {
+	status := "ok  "
+	if !ok {
+		status = "FAIL"
+		c.failed = true
+	}
+	fmt.Printf("%s  %-16s %s\n", status, name, detail)
+}
+
+// recorder stands in for *testing.T: analysistest only needs Errorf.
+type recorder struct{ errs []string }
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+// lintDir finds lint/ from this file's path, so -check works from any
+// directory under go run.
+func lintDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "lint"
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..", "lint")
+}
+
+func check() bool {
+	c := &checker{}
+	for _, a := range analyzers {
+		testdata := filepath.Join(lintDir(), a.Name, "testdata")
+		r := &recorder{}
+		var results []*analysistest.Result
+		if _, err := os.Stat(filepath.Join(testdata, "src", a.Name, a.Name+".go.golden")); err == nil {
+			results = analysistest.RunWithSuggestedFixes(r, testdata, a, a.Name)
+		} else {
+			results = analysistest.Run(r, testdata, a, a.Name)
+		}
+		n := 0
+		for _, res := range results {
+			n += len(res.Diagnostics)
+		}
+		detail := fmt.Sprintf("%d diagnostics match the fixtures", n)
+		if len(r.errs) > 0 {
+			detail = strings.Join(r.errs, "\n    ")
+		}
+		c.report(a.Name, len(r.errs) == 0 && n > 0, detail)
+	}
+	return !c.failed
+}
+
+func main() {
+	if len(os.Args) == 2 && os.Args[1] == "-check" {
+		if !check() {
+			os.Exit(1)
+		}
+		return
+	}
+	multichecker.Main(analyzers...)
+}
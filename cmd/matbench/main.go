@@ -0,0 +1,109 @@
+/*
+matbench benchmarks the matrix package's multiplications across sizes and
+tile sizes.
+
+	go run ./cmd/matbench
+	go run ./cmd/matbench -sizes 256,512,1024 -blocks 16,32,64,128
+
+The multiply benchmark runs MulNaive, MulBlocked and MulParallel on float64
+matrices of each size through testing.Benchmark and prints GFLOP/s (2n³
+floating point operations per product). Small matrices fit in cache and the
+naive loop order costs little; once a column of B no longer fits, MulNaive
+drops sharply while MulBlocked holds its speed. The block sweep shows the
+trade-off in tile size: too small wastes loop overhead, too large no longer
+fits in cache.
+
+The correctness tests and per-function benchmarks live in the package:
+
+	go test ./matrix
+	go test -bench Mul ./matrix
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang/matrix"
+)
+
+func random(r *rand.Rand, rows, cols int) *matrix.Matrix[float64] {
+	m := matrix.New[float64](rows, cols)
+	for i := range m.Data {
+		m.Data[i] = r.Float64()*2 - 1
+	}
+	return m
+}
+
+func gflops(n int, res testing.BenchmarkResult) float64 {
+	return 2 * math.Pow(float64(n), 3) * float64(res.N) / float64(res.T.Nanoseconds())
+}
+
+func bench(sizes, blocks []int, workers int) {
+	r := rand.New(rand.NewPCG(3, 4))
+	fmt.Printf("\nmultiply, GFLOP/s (GOMAXPROCS=%d, parallel workers=%d)\n", runtime.GOMAXPROCS(0), workers)
+	fmt.Printf("%6s %10s %10s %10s\n", "n", "naive", "blocked", "parallel")
+	for _, n := range sizes {
+		a, b := random(r, n, n), random(r, n, n)
+		naive := testing.Benchmark(func(tb *testing.B) {
+			for range tb.N {
+				matrix.MulNaive(a, b)
+			}
+		})
+		blocked := testing.Benchmark(func(tb *testing.B) {
+			for range tb.N {
+				matrix.MulBlocked(a, b, 0)
+			}
+		})
+		par := testing.Benchmark(func(tb *testing.B) {
+			for range tb.N {
+				matrix.MulParallel(a, b, 0, workers)
+			}
+		})
+		fmt.Printf("%6d %10.2f %10.2f %10.2f\n", n, gflops(n, naive), gflops(n, blocked), gflops(n, par))
+	}
+
+	n := sizes[len(sizes)-1]
+	a, b := random(r, n, n), random(r, n, n)
+	fmt.Printf("\nblock size sweep, n=%d, GFLOP/s\n", n)
+	for _, bs := range blocks {
+		res := testing.Benchmark(func(tb *testing.B) {
+			for range tb.N {
+				matrix.MulBlocked(a, b, bs)
+			}
+		})
+		fmt.Printf("  block %4d  %6.2f\n", bs, gflops(n, res))
+	}
+}
+
+func ints(s string) []int {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || v <= 0 {
+			log.Fatalf("bad list %q", s)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func main() {
+	sizes := flag.String("sizes", "64,256,512", "matrix sizes to benchmark")
+	blocks := flag.String("blocks", "8,16,32,64,128,256", "tile sizes for the sweep")
+	workers := flag.Int("workers", 0, "goroutines for MulParallel, 0 uses GOMAXPROCS")
+	flag.Parse()
+
+	w := *workers
+	if w <= 0 {
+		w = runtime.GOMAXPROCS(0)
+	}
+	bench(ints(*sizes), ints(*blocks), w)
+}
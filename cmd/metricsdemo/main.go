@@ -0,0 +1,301 @@
+/*
+metricsdemo instruments an HTTP server with the metrics package.
+
+	go run ./cmd/metricsdemo                 load a demo server, print /metrics
+	go run ./cmd/metricsdemo -serve :8080    keep serving; scrape /metrics yourself
+	go run ./cmd/metricsdemo -check          verify the package's guarantees
+
+-check verifies that the hot path does not allocate (testing.AllocsPerRun),
+that concurrent increments are not lost, that the P² summary stays close to
+the exact quantiles on a few distributions, and that the text output matches
+the exposition format byte for byte.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang/metrics"
+)
+
+// instrument wraps next with the usual RED metrics: rate, errors, duration.
+func instrument(reg *metrics.Registry, next http.Handler) http.Handler {
+	requests := metrics.NewCounterVec(metrics.Opts{
+		Name: "http_requests_total",
+		Help: "Requests served, by method and status code.",
+	}, "method", "code")
+	duration := metrics.NewHistogramVec(metrics.HistogramOpts{
+		Opts:    metrics.Opts{Name: "http_request_duration_seconds", Help: "Request latency."},
+		Buckets: metrics.ExponentialBuckets(0.001, 2, 10),
+	}, "method")
+	latency := metrics.NewSummary(metrics.SummaryOpts{
+		Opts: metrics.Opts{Name: "http_request_latency_seconds", Help: "Request latency quantiles since start."},
+	})
+	inFlight := metrics.NewGauge(metrics.Opts{Name: "http_requests_in_flight", Help: "Requests being served."})
+	reg.MustRegister(requests, duration, latency, inFlight)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Inc()
+		defer inFlight.Dec()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		d := time.Since(start).Seconds()
+		requests.With(r.Method, strconv.Itoa(rec.code)).Inc()
+		duration.With(r.Method).Observe(d)
+		latency.Observe(d)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func app(w http.ResponseWriter, r *http.Request) {
+	// Log-normal latency with a slow tail, and some errors.
+	time.Sleep(time.Duration(math.Exp(rand.NormFloat64()*0.7+1)) * time.Millisecond)
+	switch n := rand.IntN(100); {
+	case n < 3:
+		http.Error(w, "boom", http.StatusInternalServerError)
+	case n < 8:
+		http.NotFound(w, r)
+	default:
+		io.WriteString(w, "ok\n")
+	}
+}
+
+func demo(addr string, duration time.Duration) {
+	reg := metrics.NewRegistry()
+	reg.MustRegister(metrics.NewGaugeFunc(metrics.Opts{Name: "go_goroutines", Help: "Number of goroutines."},
+		func() float64 { return float64(runtime.NumGoroutine()) }))
+	mux := http.NewServeMux()
+	mux.Handle("/", instrument(reg, http.HandlerFunc(app)))
+	mux.Handle("/metrics", reg.Handler())
+
+	if addr != "" {
+		log.Printf("serving on %s, metrics at /metrics", addr)
+		log.Fatal(http.ListenAndServe(addr, mux))
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	deadline := time.Now().Add(duration)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				method := http.MethodGet
+				if rand.IntN(4) == 0 {
+					method = http.MethodPost
+				}
+				req, _ := http.NewRequest(method, srv.URL+"/", nil)
+				if resp, err := http.DefaultClient.Do(req); err == nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	fmt.Println("Content-Type:", resp.Header.Get("Content-Type"))
+	io.Copy(os.Stdout, resp.Body)
+}
+
+type checker struct{ failed bool }
+
+func (c *checker) report(name string, ok bool, detail string) {
+	status := "ok  "
+	if !ok {
+		status = "FAIL"
+		c.failed = true
+	}
+	fmt.Printf("%s  %-34s %s\n", status, name, detail)
+}
+
+func checkAllocs(c *checker) {
+	cnt := metrics.NewCounter(metrics.Opts{Name: "c"})
+	g := metrics.NewGauge(metrics.Opts{Name: "g"})
+	h := metrics.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{Name: "h"}})
+	s := metrics.NewSummary(metrics.SummaryOpts{Opts: metrics.Opts{Name: "s"}})
+	vec := metrics.NewCounterVec(metrics.Opts{Name: "v"}, "method", "code")
+	vec.With("GET", "200")
+	method, code := "GET", "200"
+	for _, tc := range []struct {
+		name string
+		fn   func()
+	}{
+		{"Counter.Inc", cnt.Inc},
+		{"Counter.Add", func() { cnt.Add(2.5) }},
+		{"Gauge.Set/Add", func() { g.Set(3); g.Add(1) }},
+		{"Histogram.Observe", func() { h.Observe(0.3) }},
+		{"Summary.Observe", func() { s.Observe(0.3) }},
+		{"CounterVec.With(existing).Inc", func() { vec.With(method, code).Inc() }},
+	} {
+		a := testing.AllocsPerRun(1000, tc.fn)
+		c.report(tc.name+" allocs", a == 0, fmt.Sprint(a))
+	}
+}
+
+func checkConcurrency(c *checker) {
+	cnt := metrics.NewCounter(metrics.Opts{Name: "c"})
+	g := metrics.NewGauge(metrics.Opts{Name: "g"})
+	h := metrics.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{Name: "h"}, Buckets: []float64{1, 2}})
+	vec := metrics.NewCounterVec(metrics.Opts{Name: "v"}, "worker")
+	var wg sync.WaitGroup
+	const workers, each = 16, 10000
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			label := strconv.Itoa(i % 4)
+			for range each {
+				cnt.Inc()
+				cnt.Add(0.5)
+				g.Add(1)
+				g.Sub(0.5)
+				h.Observe(1.5)
+				vec.With(label).Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	total := 0.0
+	for i := range 4 {
+		total += vec.With(strconv.Itoa(i)).Value()
+	}
+	_, cum := h.Buckets()
+	n := float64(workers * each)
+	c.report("no lost updates", cnt.Value() == 1.5*n && g.Value() == n/2 && cum[1] == uint64(n) && total == n,
+		fmt.Sprintf("counter %v gauge %v bucket %d vec %v", cnt.Value(), g.Value(), cum[1], total))
+}
+
+func checkSummary(c *checker) {
+	r := rand.New(rand.NewPCG(5, 5))
+	for _, dist := range []struct {
+		name string
+		gen  func() float64
+	}{
+		{"uniform", r.Float64},
+		{"normal", r.NormFloat64},
+		{"exponential", r.ExpFloat64},
+		{"log-normal", func() float64 { return math.Exp(r.NormFloat64()) }},
+	} {
+		s := metrics.NewSummary(metrics.SummaryOpts{Opts: metrics.Opts{Name: "s"}, Quantiles: []float64{0.5, 0.9, 0.99}})
+		data := make([]float64, 100000)
+		for i := range data {
+			data[i] = dist.gen()
+			s.Observe(data[i])
+		}
+		slices.Sort(data)
+		qs, est := s.Quantiles()
+		worst, detail := 0.0, ""
+		for i, q := range qs {
+			// Error measured in rank: where the estimate falls in the data.
+			rank := float64(sortSearch(data, est[i])) / float64(len(data))
+			worst = max(worst, math.Abs(rank-q))
+			detail += fmt.Sprintf(" p%g=%.3f(exact %.3f)", q*100, est[i], data[int(q*float64(len(data)))])
+		}
+		c.report("summary "+dist.name, worst < 0.01, fmt.Sprintf("rank error %.4f%s", worst, detail))
+	}
+}
+
+func sortSearch(data []float64, v float64) int {
+	i, _ := slices.BinarySearch(data, v)
+	return i
+}
+
+const golden = `# HELP jobs_total Jobs "done".\nwith newline
+# TYPE jobs_total counter
+jobs_total{queue="a\\b",state="ok"} 3
+jobs_total{queue="q\"1\"",state="fail"} 1.5
+# TYPE size_bytes histogram
+size_bytes_bucket{le="10"} 1
+size_bytes_bucket{le="100"} 2
+size_bytes_bucket{le="+Inf"} 3
+size_bytes_sum 1055
+size_bytes_count 3
+# HELP temp Current temperature.
+# TYPE temp gauge
+temp -4.25
+`
+
+func checkExposition(c *checker) {
+	reg := metrics.NewRegistry()
+	jobs := metrics.NewCounterVec(metrics.Opts{Name: "jobs_total", Help: "Jobs \"done\".\nwith newline"}, "queue", "state")
+	size := metrics.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{Name: "size_bytes"}, Buckets: []float64{10, 100}})
+	temp := metrics.NewGauge(metrics.Opts{Name: "temp", Help: "Current temperature."})
+	reg.MustRegister(temp, jobs, size)
+	jobs.With(`a\b`, "ok").Add(3)
+	jobs.With(`q"1"`, "fail").Add(1.5)
+	size.Observe(5)
+	size.Observe(50)
+	size.Observe(1000)
+	temp.Set(-4.25)
+	var buf bytes.Buffer
+	reg.WriteText(&buf)
+	c.report("exposition format", buf.String() == golden, "")
+	if buf.String() != golden {
+		fmt.Print(buf.String())
+	}
+
+	err := reg.Register(metrics.NewGauge(metrics.Opts{Name: "temp"}))
+	c.report("duplicate name rejected", err != nil, fmt.Sprint(err))
+	err = reg.Register(jobs.With("x", "y"))
+	c.report("vec child rejected", err != nil, fmt.Sprint(err))
+	panicked := func(fn func()) (p bool) {
+		defer func() { p = recover() != nil }()
+		fn()
+		return false
+	}
+	c.report("invalid names panic", panicked(func() { metrics.NewCounter(metrics.Opts{Name: "1bad"}) }) &&
+		panicked(func() { metrics.NewHistogramVec(metrics.HistogramOpts{Opts: metrics.Opts{Name: "h"}}, "le") }) &&
+		panicked(func() { metrics.NewCounterVec(metrics.Opts{Name: "c"}, "__x") }), "")
+	c.report("wrong label count panics", panicked(func() { jobs.With("only-one") }), "")
+}
+
+func main() {
+	serve := flag.String("serve", "", "listen address; serve until interrupted instead of running the demo load")
+	duration := flag.Duration("duration", 2*time.Second, "demo load duration")
+	check := flag.Bool("check", false, "run the checks and exit")
+	flag.Parse()
+
+	if *check {
+		var c checker
+		checkAllocs(&c)
+		checkConcurrency(&c)
+		checkSummary(&c)
+		checkExposition(&c)
+		if c.failed {
+			os.Exit(1)
+		}
+		return
+	}
+	demo(*serve, *duration)
+}
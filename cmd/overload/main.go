@@ -0,0 +1,185 @@
+/*
+overload reproduces goroutine exhaustion in net/http on one machine.
+
+It starts a server whose handler is slow and holds some memory, drives it with
+an open-loop load generator (requests are sent at a fixed rate whether or not
+earlier ones have finished) and prints goroutines, heap and client-side results
+every second. Run it twice to see the before/after:
+
+	go run ./cmd/overload -rps 2000 -work 500ms                      # unlimited
+	go run ./cmd/overload -rps 2000 -work 500ms -limit 200 -queue 200 # limited
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang/overload"
+)
+
+func main() {
+	rps := flag.Int("rps", 1000, "requests per second sent by the load generator")
+	duration := flag.Duration("duration", 10*time.Second, "how long to generate load")
+	work := flag.Duration("work", 500*time.Millisecond, "time each handler spends per request")
+	alloc := flag.Int("alloc", 64<<10, "bytes each handler keeps alive while working")
+	limit := flag.Int("limit", 0, "max in-flight handlers, 0 disables the limiter")
+	queue := flag.Int("queue", 0, "requests allowed to wait for a slot")
+	queueTimeout := flag.Duration("queue-timeout", 100*time.Millisecond, "max wait for a slot")
+	clientTimeout := flag.Duration("client-timeout", 5*time.Second, "client request timeout")
+	flag.Parse()
+
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, *alloc)
+		select {
+		case <-time.After(*work):
+		case <-r.Context().Done():
+		}
+		buf[len(buf)-1] = 1
+		fmt.Fprintf(w, "done %d\n", len(buf))
+	})
+	var lim *overload.Limiter
+	if *limit > 0 {
+		lim = overload.NewLimiter(*limit, *queue, *queueTimeout)
+		h = lim.Wrap(h)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Handler: h}
+	go srv.Serve(ln)
+	defer srv.Close()
+	url := "http://" + ln.Addr().String() + "/"
+
+	if lim != nil {
+		fmt.Printf("limiter: %d in flight, queue %d, queue timeout %v\n", *limit, *queue, *queueTimeout)
+	} else {
+		fmt.Println("limiter: off")
+	}
+	fmt.Printf("load: %d req/s for %v, handler work %v\n\n", *rps, *duration, *work)
+	fmt.Printf("%5s %10s %9s %8s %8s %8s %8s %10s %10s\n",
+		"t", "goroutines", "heap MB", "ok", "503", "errors", "pending", "p50", "p99")
+
+	client := &http.Client{
+		Timeout: *clientTimeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        *rps,
+			MaxIdleConnsPerHost: *rps,
+		},
+	}
+	gen := &generator{client: client, url: url}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	go gen.run(ctx, *rps)
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	start := time.Now()
+	for done := false; !done; {
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			done = true
+		}
+		gen.report(time.Since(start))
+	}
+
+	fmt.Println("\ndraining in-flight requests...")
+	gen.wg.Wait()
+	gen.report(time.Since(start))
+	if lim != nil {
+		s := lim.Stats()
+		fmt.Printf("\nlimiter: served %d, rejected (queue full) %d, queue timeouts %d, client canceled %d\n",
+			s.Served, s.Rejected, s.TimedOut, s.Canceled)
+	}
+}
+
+// generator sends requests at a fixed rate and aggregates results per window.
+type generator struct {
+	client *http.Client
+	url    string
+	wg     sync.WaitGroup
+
+	pending atomic.Int64
+	ok      atomic.Int64
+	shed    atomic.Int64
+	errs    atomic.Int64
+
+	mu  sync.Mutex
+	lat []time.Duration // latencies of successful requests since the last report
+}
+
+func (g *generator) run(ctx context.Context, rps int) {
+	interval := time.Second / time.Duration(rps)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			g.wg.Add(1)
+			g.pending.Add(1)
+			go g.one()
+		}
+	}
+}
+
+func (g *generator) one() {
+	defer g.wg.Done()
+	defer g.pending.Add(-1)
+	start := time.Now()
+	resp, err := g.client.Get(g.url)
+	if err != nil {
+		g.errs.Add(1)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		g.ok.Add(1)
+		g.mu.Lock()
+		g.lat = append(g.lat, time.Since(start))
+		g.mu.Unlock()
+	case http.StatusServiceUnavailable:
+		g.shed.Add(1)
+	default:
+		g.errs.Add(1)
+	}
+}
+
+// report prints one line. Goroutines and heap cover client and server since
+// both live in this process; the client side is a fixed ~2 goroutines per
+// pending request, so growth beyond that is the server.
+func (g *generator) report(elapsed time.Duration) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	g.mu.Lock()
+	lat := g.lat
+	g.lat = nil
+	g.mu.Unlock()
+	p50, p99 := "-", "-"
+	if len(lat) > 0 {
+		sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+		p50 = lat[len(lat)/2].Round(time.Millisecond).String()
+		p99 = lat[(len(lat)-1)*99/100].Round(time.Millisecond).String()
+	}
+	fmt.Printf("%5.0f %10d %9.1f %8d %8d %8d %8d %10s %10s\n",
+		elapsed.Seconds(), runtime.NumGoroutine(), float64(ms.HeapAlloc)/(1<<20),
+		g.ok.Load(), g.shed.Load(), g.errs.Load(), g.pending.Load(), p50, p99)
+}
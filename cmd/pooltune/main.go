@@ -0,0 +1,165 @@
+/*
+pooltune sweeps http.Transport pool settings against a local httptest server
+and reports how they change connection reuse and latency.
+
+Every configuration runs the same workload: -concurrency workers each sending
+-requests requests, pausing -think between them. A think time longer than
+-idle-timeouts shows evictions; a concurrency above -per-host shows idle
+connections being rejected and re-dialed.
+
+	go run ./cmd/pooltune
+	go run ./cmd/pooltune -tls -concurrency 64 -per-host 2,16,64
+	go run ./cmd/pooltune -think 30ms -idle-timeouts 10ms,90s
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang/pooltrace"
+)
+
+type config struct {
+	perHost     int
+	idleTimeout time.Duration
+	keepAlive   bool
+}
+
+func (c config) String() string {
+	if !c.keepAlive {
+		return "keep-alive off"
+	}
+	return fmt.Sprintf("perHost=%d idle=%v", c.perHost, c.idleTimeout)
+}
+
+func main() {
+	concurrency := flag.Int("concurrency", 32, "concurrent client goroutines")
+	requests := flag.Int("requests", 100, "requests per goroutine")
+	think := flag.Duration("think", 0, "pause between requests of one goroutine")
+	serverDelay := flag.Duration("server-delay", time.Millisecond, "handler latency")
+	useTLS := flag.Bool("tls", false, "serve over TLS so handshakes show up")
+	perHost := flag.String("per-host", "0,2,8,32,128", "MaxIdleConnsPerHost values (0 means the default of 2)")
+	idle := flag.String("idle-timeouts", "90s", "IdleConnTimeout values")
+	flag.Parse()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(*serverDelay)
+		io.WriteString(w, "ok\n")
+	})
+	var srv *httptest.Server
+	if *useTLS {
+		srv = httptest.NewTLSServer(handler)
+	} else {
+		srv = httptest.NewServer(handler)
+	}
+	defer srv.Close()
+
+	var configs []config
+	for _, ph := range ints(*perHost) {
+		for _, it := range durations(*idle) {
+			configs = append(configs, config{perHost: ph, idleTimeout: it, keepAlive: true})
+		}
+	}
+	configs = append(configs, config{})
+
+	fmt.Printf("%d workers x %d requests, think %v, server delay %v, tls %v\n\n",
+		*concurrency, *requests, *think, *serverDelay, *useTLS)
+	fmt.Printf("%-28s %6s %6s %7s %8s %8s %9s %9s %9s %9s %9s\n",
+		"config", "new", "reused", "reuse%", "rejected", "evicted", "dial", "tls", "wait", "p50", "p99")
+	for _, cfg := range configs {
+		base := srv.Client().Transport.(*http.Transport).Clone()
+		base.MaxIdleConns = 0 // no global cap, only the per-host one under test
+		base.MaxIdleConnsPerHost = cfg.perHost
+		base.IdleConnTimeout = cfg.idleTimeout
+		base.DisableKeepAlives = !cfg.keepAlive
+		tr := pooltrace.New(base)
+
+		lat := run(&http.Client{Transport: tr}, srv.URL, *concurrency, *requests, *think)
+		snap := tr.Snapshot() // before closing, so only real evictions count
+		tr.CloseIdleConnections()
+		for _, s := range snap {
+			fmt.Printf("%-28s %6d %6d %6.1f%% %8d %8d %9v %9v %9v %9v %9v\n", cfg,
+				s.NewConns, s.ReusedConns, s.ReuseRatio()*100, s.IdleRejected, s.IdleEvicted,
+				round(s.Dial.Mean()), round(s.TLS.Mean()), round(s.Wait.Mean()),
+				round(lat[len(lat)/2]), round(lat[(len(lat)-1)*99/100]))
+		}
+	}
+}
+
+// run drives the workload and returns sorted request latencies.
+func run(client *http.Client, url string, workers, requests int, think time.Duration) []time.Duration {
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		all []time.Duration
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lat := make([]time.Duration, 0, requests)
+			for j := 0; j < requests; j++ {
+				start := time.Now()
+				resp, err := client.Get(url)
+				if err != nil {
+					log.Fatal(err)
+				}
+				// Draining and closing the body is what returns the
+				// connection to the pool.
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				lat = append(lat, time.Since(start))
+				if think > 0 {
+					time.Sleep(think)
+				}
+			}
+			mu.Lock()
+			all = append(all, lat...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
+
+func round(d time.Duration) time.Duration {
+	if d > time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+func ints(s string) []int {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			log.Fatalf("bad integer %q: %v", f, err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func durations(s string) []time.Duration {
+	var out []time.Duration
+	for _, f := range strings.Split(s, ",") {
+		v, err := time.ParseDuration(strings.TrimSpace(f))
+		if err != nil {
+			log.Fatalf("bad duration %q: %v", f, err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
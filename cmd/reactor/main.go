@@ -0,0 +1,252 @@
+//go:build linux
+
+/*
+reactor serves the epoll reactor echo or HTTP handler, or benchmarks it against
+an equivalent server written with the net package.
+
+	go run ./cmd/reactor -serve echo -addr 127.0.0.1:9000 -trigger et
+	go run ./cmd/reactor -serve http -addr 127.0.0.1:8080
+	go run ./cmd/reactor -bench -conns 64 -rounds 2000 -size 128
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"time"
+
+	"golang/reactor"
+)
+
+func main() {
+	serve := flag.String("serve", "", "run a server: echo or http")
+	addr := flag.String("addr", "127.0.0.1:9000", "listen address for -serve")
+	trigger := flag.String("trigger", "lt", "epoll trigger mode: lt or et")
+	bench := flag.Bool("bench", false, "benchmark reactor vs net servers on localhost")
+	conns := flag.Int("conns", 32, "concurrent client connections")
+	rounds := flag.Int("rounds", 2000, "request/response round trips per connection")
+	size := flag.Int("size", 64, "echo payload size in bytes")
+	flag.Parse()
+
+	mode := reactor.LevelTriggered
+	if *trigger == "et" {
+		mode = reactor.EdgeTriggered
+	}
+
+	switch {
+	case *serve != "":
+		r, err := reactor.Listen(*addr, mode, handler(*serve))
+		if err != nil {
+			log.Fatal(err)
+		}
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		go func() { <-sig; r.Close() }()
+		log.Printf("%s server on %s (%s)", *serve, r.Addr(), mode)
+		if err := r.Run(); err != nil {
+			log.Fatal(err)
+		}
+	case *bench:
+		runBench(*conns, *rounds, *size)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+var hello = []byte("hello from the reactor\n")
+
+func handler(name string) reactor.Handler {
+	switch name {
+	case "echo":
+		return reactor.Echo{}
+	case "http":
+		return reactor.HTTP{Serve: func(*reactor.Request) reactor.Response {
+			return reactor.Response{Header: map[string]string{"Content-Type": "text/plain"}, Body: hello}
+		}}
+	}
+	log.Fatalf("unknown server %q", name)
+	return nil
+}
+
+// server is a running server under benchmark.
+type server struct {
+	name  string
+	addr  string
+	close func()
+}
+
+func runBench(conns, rounds, size int) {
+	fmt.Printf("%d conns x %d round trips, %d byte payload\n\n", conns, rounds, size)
+	fmt.Printf("%-32s %12s %10s %10s %10s\n", "server", "req/s", "p50", "p99", "max")
+
+	for _, mk := range []func() server{
+		func() server { return reactorServer("echo", reactor.LevelTriggered) },
+		func() server { return reactorServer("echo", reactor.EdgeTriggered) },
+		netEchoServer,
+	} {
+		s := mk()
+		report(s.name, load(s.addr, conns, rounds, func(c net.Conn, r *bufio.Reader) error {
+			return echoRound(c, r, size)
+		}))
+		s.close()
+	}
+	for _, mk := range []func() server{
+		func() server { return reactorServer("http", reactor.LevelTriggered) },
+		func() server { return reactorServer("http", reactor.EdgeTriggered) },
+		netHTTPServer,
+	} {
+		s := mk()
+		report(s.name, load(s.addr, conns, rounds, httpRound))
+		s.close()
+	}
+}
+
+func reactorServer(kind string, mode reactor.Mode) server {
+	r, err := reactor.Listen("127.0.0.1:0", mode, handler(kind))
+	if err != nil {
+		log.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := r.Run(); err != nil {
+			log.Print(err)
+		}
+	}()
+	return server{
+		name:  fmt.Sprintf("reactor %s (%s)", kind, mode),
+		addr:  r.Addr().String(),
+		close: func() { r.Close(); <-done },
+	}
+}
+
+func netEchoServer() server {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+	return server{name: "net echo", addr: ln.Addr().String(), close: func() { ln.Close() }}
+}
+
+func netHTTPServer() server {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(hello)
+	})}
+	go srv.Serve(ln)
+	return server{name: "net/http", addr: ln.Addr().String(), close: func() { srv.Close() }}
+}
+
+type result struct {
+	elapsed   time.Duration
+	latencies []time.Duration
+	err       error
+}
+
+// load opens conns connections and runs rounds sequential round trips on each.
+func load(addr string, conns, rounds int, round func(net.Conn, *bufio.Reader) error) result {
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		res result
+	)
+	start := time.Now()
+	for i := 0; i < conns; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := net.Dial("tcp4", addr)
+			if err != nil {
+				mu.Lock()
+				res.err = err
+				mu.Unlock()
+				return
+			}
+			defer c.Close()
+			br := bufio.NewReader(c)
+			lat := make([]time.Duration, 0, rounds)
+			for j := 0; j < rounds; j++ {
+				t := time.Now()
+				if err := round(c, br); err != nil {
+					mu.Lock()
+					res.err = err
+					mu.Unlock()
+					return
+				}
+				lat = append(lat, time.Since(t))
+			}
+			mu.Lock()
+			res.latencies = append(res.latencies, lat...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	res.elapsed = time.Since(start)
+	return res
+}
+
+func echoRound(c net.Conn, r *bufio.Reader, size int) error {
+	msg := bytes.Repeat([]byte{'x'}, size)
+	if _, err := c.Write(msg); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(r, msg)
+	return err
+}
+
+var httpReq = []byte("GET / HTTP/1.1\r\nHost: bench\r\n\r\n")
+
+func httpRound(c net.Conn, r *bufio.Reader) error {
+	if _, err := c.Write(httpReq); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return err
+}
+
+func report(name string, r result) {
+	if r.err != nil {
+		fmt.Printf("%-32s error: %v\n", name, r.err)
+		return
+	}
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	n := len(r.latencies)
+	if n == 0 {
+		return
+	}
+	pct := func(p float64) time.Duration { return r.latencies[int(float64(n-1)*p)] }
+	fmt.Printf("%-32s %12.0f %10s %10s %10s\n", name, float64(n)/r.elapsed.Seconds(),
+		pct(0.50).Round(time.Microsecond), pct(0.99).Round(time.Microsecond), r.latencies[n-1].Round(time.Microsecond))
+}
@@ -0,0 +1,165 @@
+/*
+ssedemo publishes a tick every few milliseconds and attaches three clients:
+
+	steady   reads everything as it arrives
+	flaky    has its connection cut every 50 events and resumes with Last-Event-ID
+	slow     takes 20ms per event and is handled by the chosen backpressure policy
+
+At the end it checks that steady and flaky saw every event exactly once, in
+order, and prints the broker counters.
+
+	go run ./cmd/ssedemo -policy disconnect
+	go run ./cmd/ssedemo -policy drop-events -queue 8
+	go run ./cmd/ssedemo -serve 127.0.0.1:8080   # curl -N 'http://127.0.0.1:8080/events?topic=ticks'
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang/sse"
+)
+
+var errCut = errors.New("cut")
+
+func main() {
+	serve := flag.String("serve", "", "only run the server on this address")
+	events := flag.Int("events", 300, "events to publish")
+	every := flag.Duration("every", 2*time.Millisecond, "publish interval")
+	policy := flag.String("policy", "disconnect", "slow client policy: disconnect, wait or drop-events")
+	queue := flag.Int("queue", 16, "per-subscriber queue size")
+	size := flag.Int("size", 32<<10, "payload bytes per event, large enough to fill socket buffers")
+	flag.Parse()
+
+	opts := sse.Options{QueueSize: *queue, Retry: 10 * time.Millisecond, Heartbeat: time.Second, SendTimeout: 50 * time.Millisecond}
+	switch *policy {
+	case "disconnect":
+		opts.Policy = sse.Disconnect
+	case "wait":
+		opts.Policy = sse.Wait
+	case "drop-events":
+		opts.Policy = sse.DropEvents
+	default:
+		log.Fatalf("unknown policy %q", *policy)
+	}
+	broker := sse.NewBroker(opts)
+
+	if *serve != "" {
+		http.Handle("/events", broker)
+		go func() {
+			for i := 1; ; i++ {
+				broker.Publish("ticks", "tick", []byte(strconv.Itoa(i)))
+				time.Sleep(time.Second)
+			}
+		}()
+		log.Printf("serving on http://%s/events?topic=ticks", *serve)
+		log.Fatal(http.ListenAndServe(*serve, nil))
+	}
+
+	srv := httptest.NewServer(broker)
+	defer srv.Close()
+	url := srv.URL + "/?topic=ticks"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	results := map[string]*[]int{"steady": new([]int), "flaky": new([]int), "slow": new([]int)}
+	clients := map[string]*sse.Client{}
+	for name, got := range results {
+		c := &sse.Client{URL: url}
+		clients[name] = c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// errCut ends Stream like a dropped connection would; calling it
+			// again resumes from c.LastEventID.
+			for {
+				err := c.Stream(ctx, func(ev sse.Event) error {
+					num, _, _ := strings.Cut(string(ev.Data), " ")
+					n, _ := strconv.Atoi(num)
+					mu.Lock()
+					*got = append(*got, n)
+					mu.Unlock()
+					switch {
+					case name == "slow":
+						time.Sleep(20 * time.Millisecond)
+					case name == "flaky" && len(*got)%50 == 0:
+						return errCut
+					}
+					return nil
+				})
+				if !errors.Is(err, errCut) {
+					return
+				}
+				c.Reconnects++
+			}
+		}()
+	}
+	for broker.Stats().Subscribers < len(results) {
+		time.Sleep(time.Millisecond)
+	}
+
+	pad := " " + strings.Repeat("x", *size)
+	for i := 1; i <= *events; i++ {
+		broker.Publish("ticks", "tick", []byte(strconv.Itoa(i)+pad))
+		time.Sleep(*every)
+	}
+	// Give every client up to 10s to catch up.
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		mu.Lock()
+		caughtUp := true
+		for _, got := range results {
+			if g := *got; len(g) == 0 || g[len(g)-1] != *events {
+				caughtUp = false
+			}
+		}
+		mu.Unlock()
+		if caughtUp {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	for _, name := range []string{"steady", "flaky", "slow"} {
+		got := *results[name]
+		fmt.Printf("%-7s received %4d events, reconnects %2d, %s\n", name, len(got), clients[name].Reconnects, check(got, *events))
+	}
+	s := broker.Stats()
+	fmt.Printf("\nbroker (%s): published %d, dropped %d, slow disconnects %d\n", opts.Policy, s.Published, s.Dropped, s.Disconnected)
+}
+
+// check reports whether got is exactly 1..n.
+func check(got []int, n int) string {
+	for i, v := range got {
+		if v != i+1 {
+			return fmt.Sprintf("gap or duplicate at event %d (got %d), %d unique", i+1, v, unique(got))
+		}
+	}
+	if len(got) != n {
+		return fmt.Sprintf("%d missing", n-len(got))
+	}
+	return "complete and in order"
+}
+
+func unique(got []int) int {
+	seen := map[int]bool{}
+	for _, v := range got {
+		seen[v] = true
+	}
+	return len(seen)
+}
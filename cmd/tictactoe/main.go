@@ -0,0 +1,114 @@
+/*
+tictactoe plays m,n,k-games in the terminal.
+
+	go run ./cmd/tictactoe                       you (X) against the AI
+	go run ./cmd/tictactoe -x ai -o human        the AI opens
+	go run ./cmd/tictactoe -x ai -o ai -n 4 -k 3 -depth 6
+
+Moves are entered as "row col" counting from 1, e.g. "2 2" for the centre.
+
+go test ./tictactoe proves the AI never loses on 3x3 by playing it against
+every possible opponent, as X and as O.
+*/
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang/tictactoe"
+)
+
+type player interface {
+	move(b *tictactoe.Board) tictactoe.Move
+}
+
+type human struct{ in *bufio.Scanner }
+
+func (h human) move(b *tictactoe.Board) tictactoe.Move {
+	for {
+		fmt.Printf("%s to move (row col): ", b.Turn())
+		if !h.in.Scan() {
+			fmt.Println()
+			os.Exit(0)
+		}
+		f := strings.Fields(h.in.Text())
+		if len(f) != 2 {
+			fmt.Println("enter two numbers, e.g. 2 2")
+			continue
+		}
+		r, err1 := strconv.Atoi(f[0])
+		c, err2 := strconv.Atoi(f[1])
+		if err1 != nil || err2 != nil {
+			fmt.Println("enter two numbers, e.g. 2 2")
+			continue
+		}
+		m := tictactoe.Move{Row: r - 1, Col: c - 1}
+		// Validate on a copy so the caller's Play is the only real move.
+		if err := b.Clone().Play(m); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return m
+	}
+}
+
+type computer struct{ ai *tictactoe.AI }
+
+func (c computer) move(b *tictactoe.Board) tictactoe.Move {
+	start := time.Now()
+	m, score, _ := c.ai.Best(b)
+	fmt.Printf("%s plays %s  (score %d, %d nodes, %v)\n", b.Turn(), m, score, c.ai.Nodes, time.Since(start).Round(time.Microsecond))
+	return m
+}
+
+func play(n, k int, x, o player) {
+	b, err := tictactoe.NewBoard(n, k)
+	if err != nil {
+		log.Fatal(err)
+	}
+	players := map[tictactoe.Player]player{tictactoe.X: x, tictactoe.O: o}
+	fmt.Print(b)
+	for !b.Over() {
+		if err := b.Play(players[b.Turn()].move(b)); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print("\n", b)
+	}
+	if w := b.Winner(); w != tictactoe.Empty {
+		fmt.Println(w, "wins")
+	} else {
+		fmt.Println("draw")
+	}
+}
+
+func main() {
+	n := flag.Int("n", 3, "board size")
+	k := flag.Int("k", 0, "marks in a row to win, default n")
+	xFlag := flag.String("x", "human", "who plays X: human or ai")
+	oFlag := flag.String("o", "ai", "who plays O: human or ai")
+	depth := flag.Int("depth", 0, "AI search depth, 0 for perfect play (only practical up to 4x4)")
+	flag.Parse()
+
+	if *k == 0 {
+		*k = *n
+	}
+	in := bufio.NewScanner(os.Stdin)
+	mk := func(kind string) player {
+		switch kind {
+		case "human":
+			return human{in}
+		case "ai":
+			return computer{&tictactoe.AI{MaxDepth: *depth}}
+		}
+		log.Fatalf("unknown player %q, want human or ai", kind)
+		return nil
+	}
+	play(*n, *k, mk(*xFlag), mk(*oFlag))
+}
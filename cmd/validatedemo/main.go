@@ -0,0 +1,271 @@
+/*
+validatedemo validates JSON request bodies with the validate package.
+
+	go run ./cmd/validatedemo          validate a few bodies, print the errors as JSON
+	go run ./cmd/validatedemo -check   verify rules, paths, caching and tag errors
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang/validate"
+)
+
+type Address struct {
+	Street  string `json:"street" validate:"required"`
+	Country string `json:"country" validate:"required,len=2,upper"`
+}
+
+type Item struct {
+	SKU string `json:"sku" validate:"required,sku"`
+	Qty int    `json:"qty" validate:"min=1,max=99"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by" validate:"omitempty,email"`
+}
+
+type Order struct {
+	Audit
+	Email    string            `json:"email" validate:"required,email"`
+	Age      int               `json:"age" validate:"min=13,max=120"`
+	Plan     string            `json:"plan" validate:"oneof=free|pro|team"`
+	Ship     *Address          `json:"ship" validate:"required"`
+	Bill     *Address          `json:"bill,omitempty"`
+	Items    []Item            `json:"items" validate:"min=1,max=10"`
+	Tags     []string          `json:"tags" validate:"max=3,dive,min=2,max=10"`
+	Labels   map[string]string `json:"labels" validate:"dive,max=8"`
+	Callback string            `json:"callback" validate:"omitempty,url"`
+	Timeout  time.Duration     `json:"timeout" validate:"omitempty,min=1s,max=1m"`
+	internal string            // unexported fields are never looked at
+}
+
+var skuRE = regexp.MustCompile(`^[A-Z]{3}-\d{4}$`)
+
+func init() {
+	validate.Register("sku", func(v reflect.Value, _ string) bool { return skuRE.MatchString(v.String()) })
+	validate.Register("upper", func(v reflect.Value, _ string) bool { return v.String() == strings.ToUpper(v.String()) })
+}
+
+const good = `{
+	"email": "ada@example.com", "age": 36, "plan": "pro",
+	"ship": {"street": "1 Main St", "country": "GB"},
+	"items": [{"sku": "ABC-1234", "qty": 2}],
+	"tags": ["gift"], "labels": {"src": "web"}, "timeout": 5000000000
+}`
+
+const bad = `{
+	"created_by": "not-an-email",
+	"email": "ada@localhost", "age": 7, "plan": "enterprise",
+	"ship": {"street": "", "country": "gb"},
+	"bill": {"street": "2 High St", "country": "FRA"},
+	"items": [{"sku": "ABC-1234", "qty": 2}, {"sku": "abc", "qty": 0}],
+	"tags": ["a", "ok", "x", "y"], "labels": {"src": "a-very-long-value", "a.b": "fine"},
+	"callback": "/relative", "timeout": 100
+}`
+
+func demo() {
+	for _, body := range []string{good, bad, `{}`} {
+		var o Order
+		if err := json.Unmarshal([]byte(body), &o); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		err := validate.Struct(&o)
+		var verrs validate.Errors
+		switch {
+		case err == nil:
+			fmt.Println("valid")
+		case errors.As(err, &verrs):
+			b, _ := json.MarshalIndent(map[string]any{"errors": verrs}, "", "  ")
+			fmt.Println(string(b))
+		default:
+			fmt.Println("bad rules:", err)
+		}
+	}
+}
+
+type checker struct{ failed bool }
+
+func (c *checker) report(name string, ok bool, detail string) {
+	status := "ok  "
+	if !ok {
+		status = "FAIL"
+		c.failed = true
+	}
+	fmt.Printf("%s  %-32s %s\n", status, name, detail)
+}
+
+func paths(err error) []string {
+	var verrs validate.Errors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	var out []string
+	for _, e := range verrs {
+		out = append(out, e.Path+" "+e.Rule)
+	}
+	return out
+}
+
+func checkOrder(c *checker) {
+	var o Order
+	json.Unmarshal([]byte(good), &o)
+	err := validate.Struct(o)
+	c.report("valid order", err == nil, fmt.Sprint(err))
+
+	o = Order{}
+	json.Unmarshal([]byte(bad), &o)
+	got := paths(validate.Struct(&o))
+	want := []string{
+		"created_by email",
+		"email email",
+		"age min",
+		"plan oneof",
+		"ship.street required",
+		"ship.country upper",
+		"bill.country len",
+		"items[1].sku sku",
+		"items[1].qty min",
+		"tags max",
+		`labels.src max`,
+		"callback url",
+		"timeout min",
+	}
+	c.report("every bad field, in order", slices.Equal(got, want), strings.Join(got, ", "))
+
+	var verrs validate.Errors
+	errors.As(validate.Struct(&o), &verrs)
+	msgs := verrs.ByPath()
+	c.report("messages", msgs["age"] == "must be at least 13" &&
+		msgs["bill.country"] == "must be exactly 2 characters" &&
+		msgs["tags"] == "must have at most 3 items" &&
+		msgs["plan"] == "must be one of free, pro, team" &&
+		msgs["timeout"] == "must be at least 1s", fmt.Sprint(msgs["age"], " | ", msgs["timeout"]))
+	c.report("Go paths", verrs[7].Field == "Items[1].SKU" && verrs[0].Field == "CreatedBy", verrs[7].Field)
+
+	got = paths(validate.Struct(Order{}))
+	c.report("empty order", slices.Equal(got, []string{"email required", "age min", "plan oneof", "ship required", "items min"}),
+		strings.Join(got, ", "))
+
+	o = Order{}
+	json.Unmarshal([]byte(good), &o)
+	o.Tags = []string{"ok", "x"}
+	o.Labels = map[string]string{"a.b": "too long value", "z": "ok"}
+	got = paths(validate.Struct(o))
+	c.report("dive into slices and maps", slices.Equal(got, []string{"tags[1] min", `labels["a.b"] max`}), strings.Join(got, ", "))
+}
+
+type Node struct {
+	Name     string  `json:"name" validate:"required"`
+	Children []*Node `json:"children"`
+	Parent   *Node   `json:"-"`
+}
+
+func checkNesting(c *checker) {
+	root := &Node{Name: "root"}
+	root.Children = []*Node{{Name: "a", Parent: root}, {Parent: root, Children: []*Node{{Name: ""}}}}
+	got := paths(validate.Struct(root))
+	c.report("recursive types and cycles", slices.Equal(got, []string{"children[1].name required", "children[1].children[0].name required"}),
+		strings.Join(got, ", "))
+
+	err := validate.Var([]Item{{SKU: "ABC-0001", Qty: 1}, {SKU: "nope", Qty: 1}}, "min=1")
+	c.report("Var walks into structs", slices.Equal(paths(err), []string{"[1].sku sku"}), fmt.Sprint(err))
+	err = validate.Var(map[string]int{"a": 1, "b": 0}, "dive,min=1")
+	c.report("Var with dive", slices.Equal(paths(err), []string{`["b"] min`}), fmt.Sprint(err))
+	err = validate.Var("x", "required,min=2")
+	c.report("Var on a scalar", err != nil && err.Error() == "must be at least 2 characters", fmt.Sprint(err))
+	var nilAny any
+	err = validate.Var(nilAny, "required")
+	c.report("Var nil required", err != nil, fmt.Sprint(err))
+}
+
+func checkBadTags(c *checker) {
+	// The same mistake as struct/main.go. Written as a literal, go vet
+	// would reject this file, so the type is built at run time.
+	malformed := reflect.StructOf([]reflect.StructField{{
+		Name: "Name", Type: reflect.TypeFor[string](), Tag: `validate: "required"`,
+	}})
+	type unknown struct {
+		Name string `validate:"required,frobnicate"`
+	}
+	type wrongKind struct {
+		N int `validate:"email"`
+	}
+	type badParam struct {
+		N int `validate:"min=ten"`
+	}
+	type durationOnInt struct {
+		N int `validate:"max=1m"`
+	}
+	type diveOnString struct {
+		S string `validate:"dive,min=1"`
+	}
+	for _, tc := range []struct {
+		name string
+		v    any
+	}{
+		{"malformed tag", reflect.New(malformed).Interface()},
+		{"unknown rule", unknown{}},
+		{"rule on wrong kind", wrongKind{}},
+		{"bad parameter", badParam{}},
+		{"duration on int", durationOnInt{}},
+		{"dive on string", diveOnString{}},
+	} {
+		err := validate.Struct(tc.v)
+		var verrs validate.Errors
+		c.report(tc.name, errors.Is(err, validate.ErrBadTag) && !errors.As(err, &verrs), fmt.Sprint(err))
+	}
+	c.report("not a struct", validate.Struct(42) != nil, fmt.Sprint(validate.Struct(42)))
+}
+
+func checkCache(c *checker) {
+	var o Order
+	json.Unmarshal([]byte(good), &o)
+	validate.Struct(&o)
+	allocs := testing.AllocsPerRun(1000, func() { validate.Struct(&o) })
+	res := testing.Benchmark(func(b *testing.B) {
+		for range b.N {
+			validate.Struct(&o)
+		}
+	})
+	// The walk allocates its visiting map, path stack and map key slices;
+	// what must not happen is a re-parse of the tags on every call.
+	c.report("compiled rules are reused", allocs < 20, fmt.Sprintf("%.0f allocs, %s", allocs, res))
+
+	v := validate.New()
+	v.Register("even", func(v reflect.Value, _ string) bool { return v.Int()%2 == 0 })
+	type T struct {
+		N int `validate:"even"`
+	}
+	c.report("separate validators", v.Struct(T{N: 3}) != nil && v.Struct(T{N: 4}) == nil &&
+		errors.Is(validate.Struct(T{}), validate.ErrBadTag), "")
+}
+
+func main() {
+	check := flag.Bool("check", false, "verify the package instead of running the demo")
+	flag.Parse()
+	if !*check {
+		demo()
+		return
+	}
+	c := &checker{}
+	checkOrder(c)
+	checkNesting(c)
+	checkBadTags(c)
+	checkCache(c)
+	if c.failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+wschat is a broadcast chat room on top of the ws package.
+
+	go run ./cmd/wschat -serve -addr 127.0.0.1:8080      # open http://127.0.0.1:8080
+	go run ./cmd/wschat -connect ws://127.0.0.1:8080/ws  # type lines to send
+
+go test ./ws runs the protocol end to end over localhost.
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"golang/ws"
+)
+
+func main() {
+	serve := flag.Bool("serve", false, "run the chat server")
+	addr := flag.String("addr", "127.0.0.1:8080", "listen address for -serve")
+	connect := flag.String("connect", "", "connect to a chat server URL")
+	flag.Parse()
+
+	switch {
+	case *serve:
+		hub := ws.NewHub()
+		mux := http.NewServeMux()
+		mux.Handle("/ws", hub.Handler(nil))
+		mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) { fmt.Fprint(w, page) })
+		log.Printf("chat on http://%s", *addr)
+		log.Fatal(http.ListenAndServe(*addr, mux))
+	case *connect != "":
+		client(*connect)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func client(url string) {
+	c, err := ws.Dial(context.Background(), url, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		for {
+			m, err := c.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("disconnected: %v", err)
+				os.Exit(0)
+			}
+			fmt.Printf("< %s\n", m.Data)
+		}
+	}()
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		if err := c.WriteMessage(context.Background(), ws.Text, []byte(sc.Text())); err != nil {
+			log.Fatal(err)
+		}
+	}
+	c.Close(ws.CloseNormal, "bye")
+}
+
+const page = `<!doctype html>
+<title>wschat</title>
+<pre id="log"></pre>
+<input id="msg" autofocus placeholder="say something">
+<script>
+const log = document.getElementById("log");
+const sock = new WebSocket("ws://" + location.host + "/ws");
+sock.onmessage = e => log.textContent += e.data + "\n";
+sock.onclose = e => log.textContent += "closed " + e.code + " " + e.reason + "\n";
+document.getElementById("msg").onkeydown = e => {
+  if (e.key === "Enter") { sock.send(e.target.value); e.target.value = ""; }
+};
+</script>
+`
@@ -0,0 +1,99 @@
+package collections_test
+
+import (
+	"cmp"
+	"flag"
+	"math/rand/v2"
+	"testing"
+
+	"golang/collections"
+)
+
+// The property tests apply random operation sequences to a container and to
+// a naive reference built from plain slices and maps, compare every result,
+// then compare the full contents as seen through the iterators.
+//
+//	go test ./collections -seed 42 -ops 500
+
+var (
+	seed = flag.Uint64("seed", 1, "random seed for the property tests")
+	ops  = flag.Int("ops", 200, "operations per random sequence")
+)
+
+// rounds runs check over many random sequences, fewer with -short, and
+// names the round that failed so it can be replayed.
+func rounds(t *testing.T, check func(*testing.T, *rand.Rand, int)) {
+	n := 500
+	if testing.Short() {
+		n = 50
+	}
+	round := 0
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("seed %d, round %d", *seed, round)
+		}
+	})
+	for round = range n {
+		check(t, rand.New(rand.NewPCG(*seed, uint64(round))), *ops)
+	}
+}
+
+func expect(t *testing.T, ok bool, format string, args ...any) {
+	t.Helper()
+	if !ok {
+		t.Fatalf(format, args...)
+	}
+}
+
+// index is the linear search from generic/main.go, used as the reference for
+// SortedSlice.Index.
+func index[T comparable](s []T, x T) int {
+	for i, v := range s {
+		if v == x {
+			return i
+		}
+	}
+	return -1
+}
+
+// TestEarlyStop breaks out of every iterator after one element; a yield
+// called after it returned false panics with "range function continued
+// iteration after function for loop body returned false".
+func TestEarlyStop(t *testing.T) {
+	var d collections.Deque[int]
+	om := collections.NewOrderedMap[int, int]()
+	mm := collections.NewMultiMap[int, int]()
+	for i := range 10 {
+		d.PushBack(i)
+		om.Set(i, i)
+		mm.Add(i%2, i)
+	}
+	h := collections.NewHeap(cmp.Less[int], 5, 3, 8, 1)
+	ss := collections.NewSortedSlice(4, 2, 9, 7)
+	set := collections.NewSet(1, 2, 3)
+
+	first := func(seq func(func(int) bool)) {
+		for range seq {
+			break
+		}
+	}
+	first2 := func(seq func(func(int, int) bool)) {
+		for range seq {
+			break
+		}
+	}
+	first(set.All())
+	first(om.Keys())
+	first(om.Values())
+	first2(om.All())
+	first2(om.Backward())
+	first(d.Values())
+	first2(d.All())
+	first(h.All())
+	first(h.Drain())
+	first(ss.Values())
+	first(ss.Range(0, 10))
+	first(mm.Keys())
+	first2(mm.All())
+	expect(t, h.Len() == 3, "Drain break left %d elements, want 3", h.Len())
+}
@@ -0,0 +1,115 @@
+package collections
+
+import "iter"
+
+// Deque is a double-ended queue backed by a ring buffer that doubles when
+// full, so pushes and pops at both ends are amortised O(1). The zero value is
+// an empty deque.
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front element
+	n    int
+}
+
+// Len returns the number of elements.
+func (d *Deque[T]) Len() int { return d.n }
+
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	nb := make([]T, max(8, 2*len(d.buf)))
+	// Unroll the ring so the front lands at index 0.
+	k := copy(nb, d.buf[d.head:])
+	copy(nb[k:], d.buf[:d.head])
+	d.buf, d.head = nb, 0
+}
+
+// PushBack appends v at the back.
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[(d.head+d.n)%len(d.buf)] = v
+	d.n++
+}
+
+// PushFront inserts v at the front.
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = v
+	d.n++
+}
+
+// PopFront removes and returns the front element.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero // let the GC reclaim what v points to
+	d.head = (d.head + 1) % len(d.buf)
+	d.n--
+	return v, true
+}
+
+// PopBack removes and returns the back element.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	i := (d.head + d.n - 1) % len(d.buf)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	return v, true
+}
+
+// Front returns the front element without removing it.
+func (d *Deque[T]) Front() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back element without removing it.
+func (d *Deque[T]) Back() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.n-1)%len(d.buf)], true
+}
+
+// At returns the i-th element from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.n {
+		panic("collections: Deque index out of range")
+	}
+	return d.buf[(d.head+i)%len(d.buf)]
+}
+
+// All yields index/value pairs from front to back.
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.n; i++ {
+			if !yield(i, d.buf[(d.head+i)%len(d.buf)]) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields the elements from front to back.
+func (d *Deque[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range d.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package collections_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+func TestDequeProperties(t *testing.T) { rounds(t, checkDeque) }
+
+func checkDeque(t *testing.T, r *rand.Rand, ops int) {
+	var d collections.Deque[int]
+	var ref []int
+	for i := 0; i < ops; i++ {
+		switch r.IntN(4) {
+		case 0:
+			d.PushBack(i)
+			ref = append(ref, i)
+		case 1:
+			d.PushFront(i)
+			ref = slices.Insert(ref, 0, i)
+		case 2:
+			v, ok := d.PopFront()
+			expect(t, ok == (len(ref) > 0), "op %d PopFront ok", i)
+			if len(ref) > 0 {
+				expect(t, v == ref[0], "op %d PopFront %d, want %d", i, v, ref[0])
+				ref = ref[1:]
+			}
+		default:
+			v, ok := d.PopBack()
+			expect(t, ok == (len(ref) > 0), "op %d PopBack ok", i)
+			if len(ref) > 0 {
+				expect(t, v == ref[len(ref)-1], "op %d PopBack %d", i, v)
+				ref = ref[:len(ref)-1]
+			}
+		}
+		expect(t, d.Len() == len(ref), "op %d Len", i)
+		if len(ref) > 0 {
+			f, _ := d.Front()
+			b, _ := d.Back()
+			j := r.IntN(len(ref))
+			expect(t, f == ref[0] && b == ref[len(ref)-1] && d.At(j) == ref[j], "op %d Front/Back/At", i)
+		}
+	}
+	got := slices.Collect(d.Values())
+	expect(t, slices.Equal(got, ref), "Values %v, want %v", got, ref)
+}
@@ -0,0 +1,17 @@
+/*
+Package collections grows the generic Index[T comparable] from generic/main.go
+into the containers the standard library leaves out.
+
+	Set[T]              unordered set on top of a map
+	OrderedMap[K, V]    map that remembers insertion order
+	Deque[T]            double-ended queue on a growable ring buffer
+	Heap[T]             binary heap ordered by a caller supplied less
+	SortedSlice[T]      slice kept sorted, with binary search Index
+	MultiMap[K, V]      map from a key to several values
+
+Every container can be ranged over with Go 1.23 iterators (iter.Seq and
+iter.Seq2), and every iterator stops as soon as the loop body breaks. None of
+the containers are safe for concurrent use; guard them with a mutex as in
+concurrency/mutex.go.
+*/
+package collections
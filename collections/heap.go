@@ -0,0 +1,105 @@
+package collections
+
+import "iter"
+
+// Heap is a binary heap: Pop always returns the element for which less
+// reports true against every other element (the minimum for cmp.Less).
+type Heap[T any] struct {
+	data []T
+	less func(a, b T) bool
+}
+
+// NewHeap returns a heap ordered by less, initialised with items in O(n).
+func NewHeap[T any](less func(a, b T) bool, items ...T) *Heap[T] {
+	h := &Heap[T]{data: append([]T(nil), items...), less: less}
+	for i := len(h.data)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+// Len returns the number of elements.
+func (h *Heap[T]) Len() int { return len(h.data) }
+
+// Push adds v in O(log n).
+func (h *Heap[T]) Push(v T) {
+	h.data = append(h.data, v)
+	h.up(len(h.data) - 1)
+}
+
+// Peek returns the top element without removing it.
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.data) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.data[0], true
+}
+
+// Pop removes and returns the top element in O(log n).
+func (h *Heap[T]) Pop() (T, bool) {
+	var zero T
+	n := len(h.data) - 1
+	if n < 0 {
+		return zero, false
+	}
+	top := h.data[0]
+	h.data[0] = h.data[n]
+	h.data[n] = zero
+	h.data = h.data[:n]
+	h.down(0)
+	return top, true
+}
+
+// Drain yields elements in heap order, removing them as it goes. Breaking out
+// of the loop leaves the remaining elements in the heap.
+func (h *Heap[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for h.Len() > 0 {
+			v, _ := h.Pop()
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All yields the elements in internal array order, which is not sorted.
+func (h *Heap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range h.data {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.data[i], h.data[parent]) {
+			return
+		}
+		h.data[i], h.data[parent] = h.data[parent], h.data[i]
+		i = parent
+	}
+}
+
+func (h *Heap[T]) down(i int) {
+	n := len(h.data)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && h.less(h.data[l], h.data[smallest]) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && h.less(h.data[r], h.data[smallest]) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		h.data[i], h.data[smallest] = h.data[smallest], h.data[i]
+		i = smallest
+	}
+}
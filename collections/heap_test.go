@@ -0,0 +1,53 @@
+package collections_test
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+type job struct {
+	prio int
+	seq  int
+}
+
+// compareJobs orders by priority, then insertion, so the reference pop order
+// is exact even with equal priorities.
+func compareJobs(a, b job) int {
+	return cmp.Or(cmp.Compare(a.prio, b.prio), cmp.Compare(a.seq, b.seq))
+}
+
+func TestHeapProperties(t *testing.T) { rounds(t, checkHeap) }
+
+func checkHeap(t *testing.T, r *rand.Rand, ops int) {
+	less := func(a, b job) bool { return compareJobs(a, b) < 0 }
+	var init []job
+	for i := range r.IntN(16) {
+		init = append(init, job{r.IntN(20), -i - 1})
+	}
+	h := collections.NewHeap(less, init...)
+	ref := slices.Clone(init)
+	for i := 0; i < ops; i++ {
+		if r.IntN(3) > 0 {
+			j := job{r.IntN(20), i}
+			h.Push(j)
+			ref = append(ref, j)
+			continue
+		}
+		slices.SortFunc(ref, compareJobs)
+		p, _ := h.Peek()
+		v, ok := h.Pop()
+		expect(t, ok == (len(ref) > 0), "op %d Pop ok", i)
+		if len(ref) > 0 {
+			expect(t, v == ref[0] && p == v, "op %d Pop %v, want %v", i, v, ref[0])
+			ref = ref[1:]
+		}
+		expect(t, h.Len() == len(ref), "op %d Len", i)
+	}
+	got := slices.Collect(h.Drain())
+	expect(t, slices.IsSortedFunc(got, compareJobs), "Drain not sorted")
+	expect(t, len(got) == len(ref) && h.Len() == 0, "Drain len %d, want %d", len(got), len(ref))
+}
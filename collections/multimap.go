@@ -0,0 +1,90 @@
+package collections
+
+import (
+	"iter"
+	"slices"
+)
+
+// MultiMap maps each key to a list of values, kept in insertion order.
+// The zero value is empty and ready to use.
+type MultiMap[K comparable, V comparable] struct {
+	m map[K][]V
+	n int
+}
+
+// NewMultiMap returns an empty multimap.
+func NewMultiMap[K comparable, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K][]V)}
+}
+
+// Add appends v to the values of k.
+func (mm *MultiMap[K, V]) Add(k K, v V) {
+	if mm.m == nil {
+		mm.m = make(map[K][]V)
+	}
+	mm.m[k] = append(mm.m[k], v)
+	mm.n++
+}
+
+// Get returns the values of k. The slice must not be modified.
+func (mm *MultiMap[K, V]) Get(k K) []V { return mm.m[k] }
+
+// Has reports whether k has at least one value.
+func (mm *MultiMap[K, V]) Has(k K) bool { return len(mm.m[k]) > 0 }
+
+// Remove deletes the first occurrence of v under k and reports whether it
+// was found.
+func (mm *MultiMap[K, V]) Remove(k K, v V) bool {
+	vs := mm.m[k]
+	i := slices.Index(vs, v)
+	if i < 0 {
+		return false
+	}
+	vs = slices.Delete(vs, i, i+1)
+	if len(vs) == 0 {
+		delete(mm.m, k)
+	} else {
+		mm.m[k] = vs
+	}
+	mm.n--
+	return true
+}
+
+// RemoveAll deletes k with all its values and returns how many there were.
+func (mm *MultiMap[K, V]) RemoveAll(k K) int {
+	n := len(mm.m[k])
+	delete(mm.m, k)
+	mm.n -= n
+	return n
+}
+
+// Len returns the total number of values.
+func (mm *MultiMap[K, V]) Len() int { return mm.n }
+
+// KeyLen returns the number of distinct keys.
+func (mm *MultiMap[K, V]) KeyLen() int { return len(mm.m) }
+
+// Keys yields the distinct keys in unspecified order.
+func (mm *MultiMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range mm.m {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// All yields every key/value pair; values of one key come out in insertion
+// order, keys in unspecified order.
+func (mm *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, vs := range mm.m {
+			for _, v := range vs {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package collections_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+func TestMultiMapProperties(t *testing.T) { rounds(t, checkMultiMap) }
+
+func checkMultiMap(t *testing.T, r *rand.Rand, ops int) {
+	mm := collections.NewMultiMap[string, int]()
+	ref := map[string][]int{}
+	total := 0
+	for i := 0; i < ops; i++ {
+		k := string(rune('a' + r.IntN(8)))
+		v := r.IntN(10)
+		switch r.IntN(4) {
+		case 0, 1:
+			mm.Add(k, v)
+			ref[k] = append(ref[k], v)
+			total++
+		case 2:
+			j := index(ref[k], v)
+			expect(t, mm.Remove(k, v) == (j >= 0), "op %d Remove(%s,%d)", i, k, v)
+			if j >= 0 {
+				ref[k] = slices.Delete(ref[k], j, j+1)
+				total--
+			}
+		default:
+			n := len(ref[k])
+			expect(t, mm.RemoveAll(k) == n, "op %d RemoveAll(%s)", i, k)
+			delete(ref, k)
+			total -= n
+		}
+		expect(t, mm.Len() == total, "op %d Len %d, want %d", i, mm.Len(), total)
+		expect(t, slices.Equal(mm.Get(k), ref[k]), "op %d Get(%s) %v, want %v", i, k, mm.Get(k), ref[k])
+	}
+	n := 0
+	for range mm.All() {
+		n++
+	}
+	expect(t, n == total, "All yielded %d, want %d", n, total)
+}
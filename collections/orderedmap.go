@@ -0,0 +1,133 @@
+package collections
+
+import "iter"
+
+// OrderedMap is a map that iterates in insertion order. Re-setting an
+// existing key keeps its position. Get, Set and Delete are O(1): entries live
+// in a doubly linked list indexed by a map.
+type OrderedMap[K comparable, V any] struct {
+	index      map[K]*omEntry[K, V]
+	head, tail *omEntry[K, V]
+}
+
+type omEntry[K comparable, V any] struct {
+	key        K
+	val        V
+	prev, next *omEntry[K, V]
+}
+
+// NewOrderedMap returns an empty map.
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{index: make(map[K]*omEntry[K, V])}
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[K, V]) Len() int { return len(m.index) }
+
+// Get returns the value for k.
+func (m *OrderedMap[K, V]) Get(k K) (V, bool) {
+	if e, ok := m.index[k]; ok {
+		return e.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores v under k, appending k if it is new, and reports whether it was.
+func (m *OrderedMap[K, V]) Set(k K, v V) bool {
+	if e, ok := m.index[k]; ok {
+		e.val = v
+		return false
+	}
+	if m.index == nil {
+		m.index = make(map[K]*omEntry[K, V])
+	}
+	e := &omEntry[K, V]{key: k, val: v, prev: m.tail}
+	if m.tail != nil {
+		m.tail.next = e
+	} else {
+		m.head = e
+	}
+	m.tail = e
+	m.index[k] = e
+	return true
+}
+
+// Delete removes k and reports whether it was present.
+func (m *OrderedMap[K, V]) Delete(k K) bool {
+	e, ok := m.index[k]
+	if !ok {
+		return false
+	}
+	delete(m.index, k)
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		m.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		m.tail = e.prev
+	}
+	return true
+}
+
+// Oldest returns the first inserted entry still present.
+func (m *OrderedMap[K, V]) Oldest() (K, V, bool) {
+	if m.head == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return m.head.key, m.head.val, true
+}
+
+// All yields key/value pairs in insertion order. Deleting the current entry
+// during iteration is allowed.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.head; e != nil; {
+			next := e.next
+			if !yield(e.key, e.val) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Keys yields the keys in insertion order.
+func (m *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields the values in insertion order.
+func (m *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward yields key/value pairs newest first.
+func (m *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.tail; e != nil; {
+			prev := e.prev
+			if !yield(e.key, e.val) {
+				return
+			}
+			e = prev
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package collections_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+func TestOrderedMapProperties(t *testing.T) { rounds(t, checkOrderedMap) }
+
+func checkOrderedMap(t *testing.T, r *rand.Rand, ops int) {
+	m := collections.NewOrderedMap[int, int]()
+	var keys []int
+	vals := map[int]int{}
+	for i := 0; i < ops; i++ {
+		k := r.IntN(32)
+		_, present := vals[k]
+		switch r.IntN(3) {
+		case 0:
+			expect(t, m.Set(k, i) == !present, "op %d Set(%d)", i, k)
+			if !present {
+				keys = append(keys, k)
+			}
+			vals[k] = i
+		case 1:
+			expect(t, m.Delete(k) == present, "op %d Delete(%d)", i, k)
+			if present {
+				keys = slices.Delete(keys, index(keys, k), index(keys, k)+1)
+				delete(vals, k)
+			}
+		default:
+			v, ok := m.Get(k)
+			expect(t, ok == present && v == vals[k], "op %d Get(%d) = %d,%v", i, k, v, ok)
+		}
+		expect(t, m.Len() == len(keys), "op %d Len", i)
+	}
+	got := slices.Collect(m.Keys())
+	expect(t, slices.Equal(got, keys), "Keys %v, want %v", got, keys)
+	for k, v := range m.All() {
+		expect(t, vals[k] == v, "All %d=%d, want %d", k, v, vals[k])
+	}
+	back := slices.Collect(func(yield func(int) bool) {
+		for k := range m.Backward() {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+	slices.Reverse(back)
+	expect(t, slices.Equal(back, keys), "Backward %v", back)
+	if k, _, ok := m.Oldest(); ok {
+		expect(t, k == keys[0], "Oldest %d, want %d", k, keys[0])
+	}
+}
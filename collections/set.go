@@ -0,0 +1,120 @@
+package collections
+
+import (
+	"iter"
+	"maps"
+)
+
+// Set is an unordered collection of distinct values. The zero value is an
+// empty set ready to use.
+type Set[T comparable] struct {
+	m map[T]struct{}
+}
+
+// NewSet returns a set holding items.
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{m: make(map[T]struct{}, len(items))}
+	for _, v := range items {
+		s.m[v] = struct{}{}
+	}
+	return s
+}
+
+// CollectSet builds a set from a sequence.
+func CollectSet[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := NewSet[T]()
+	for v := range seq {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add inserts v and reports whether it was not already present.
+func (s *Set[T]) Add(v T) bool {
+	if s.m == nil {
+		s.m = make(map[T]struct{})
+	}
+	if _, ok := s.m[v]; ok {
+		return false
+	}
+	s.m[v] = struct{}{}
+	return true
+}
+
+// Remove deletes v and reports whether it was present.
+func (s *Set[T]) Remove(v T) bool {
+	if _, ok := s.m[v]; !ok {
+		return false
+	}
+	delete(s.m, v)
+	return true
+}
+
+// Contains reports whether v is in the set.
+func (s *Set[T]) Contains(v T) bool {
+	_, ok := s.m[v]
+	return ok
+}
+
+// Len returns the number of elements.
+func (s *Set[T]) Len() int { return len(s.m) }
+
+// Clear removes every element.
+func (s *Set[T]) Clear() { clear(s.m) }
+
+// All yields the elements in unspecified order.
+func (s *Set[T]) All() iter.Seq[T] { return maps.Keys(s.m) }
+
+// Clone returns a copy of the set.
+func (s *Set[T]) Clone() *Set[T] { return &Set[T]{m: maps.Clone(s.m)} }
+
+// Union returns a new set with the elements of s and o.
+func (s *Set[T]) Union(o *Set[T]) *Set[T] {
+	out := s.Clone()
+	for v := range o.m {
+		out.Add(v)
+	}
+	return out
+}
+
+// Intersect returns a new set with the elements present in both s and o.
+func (s *Set[T]) Intersect(o *Set[T]) *Set[T] {
+	small, big := s, o
+	if small.Len() > big.Len() {
+		small, big = big, small
+	}
+	out := NewSet[T]()
+	for v := range small.m {
+		if big.Contains(v) {
+			out.Add(v)
+		}
+	}
+	return out
+}
+
+// Difference returns a new set with the elements of s that are not in o.
+func (s *Set[T]) Difference(o *Set[T]) *Set[T] {
+	out := NewSet[T]()
+	for v := range s.m {
+		if !o.Contains(v) {
+			out.Add(v)
+		}
+	}
+	return out
+}
+
+// SubsetOf reports whether every element of s is in o.
+func (s *Set[T]) SubsetOf(o *Set[T]) bool {
+	if s.Len() > o.Len() {
+		return false
+	}
+	for v := range s.m {
+		if !o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both sets hold the same elements.
+func (s *Set[T]) Equal(o *Set[T]) bool { return s.Len() == o.Len() && s.SubsetOf(o) }
@@ -0,0 +1,51 @@
+package collections_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+func TestSetProperties(t *testing.T) { rounds(t, checkSet) }
+
+func checkSet(t *testing.T, r *rand.Rand, ops int) {
+	s := collections.NewSet[int]()
+	ref := map[int]bool{}
+	for i := 0; i < ops; i++ {
+		v := r.IntN(64)
+		switch r.IntN(3) {
+		case 0:
+			expect(t, s.Add(v) == !ref[v], "op %d Add(%d)", i, v)
+			ref[v] = true
+		case 1:
+			expect(t, s.Remove(v) == ref[v], "op %d Remove(%d)", i, v)
+			delete(ref, v)
+		default:
+			expect(t, s.Contains(v) == ref[v], "op %d Contains(%d)", i, v)
+		}
+		expect(t, s.Len() == len(ref), "op %d Len %d, want %d", i, s.Len(), len(ref))
+	}
+	got := slices.Sorted(s.All())
+	var want []int
+	for v := range ref {
+		want = append(want, v)
+	}
+	slices.Sort(want)
+	expect(t, slices.Equal(got, want), "All %v, want %v", got, want)
+
+	o := collections.NewSet[int]()
+	for range 32 {
+		o.Add(r.IntN(64))
+	}
+	u, in, d := s.Union(o), s.Intersect(o), s.Difference(o)
+	for v := range 64 {
+		a, b := s.Contains(v), o.Contains(v)
+		expect(t, u.Contains(v) == (a || b), "Union(%d)", v)
+		expect(t, in.Contains(v) == (a && b), "Intersect(%d)", v)
+		expect(t, d.Contains(v) == (a && !b), "Difference(%d)", v)
+	}
+	expect(t, in.SubsetOf(s) && in.SubsetOf(o) && s.SubsetOf(u), "subset laws")
+	expect(t, s.Equal(s.Clone()), "Clone not Equal")
+}
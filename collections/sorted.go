@@ -0,0 +1,89 @@
+package collections
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// SortedSlice keeps its elements in ascending order. Duplicates are allowed.
+// Lookups are O(log n); inserts and removals are O(n) because elements shift,
+// which is still faster than a tree for small and medium sizes thanks to
+// contiguous memory.
+type SortedSlice[T cmp.Ordered] struct {
+	data []T
+}
+
+// NewSortedSlice returns a sorted copy of items.
+func NewSortedSlice[T cmp.Ordered](items ...T) *SortedSlice[T] {
+	s := &SortedSlice[T]{data: slices.Clone(items)}
+	slices.Sort(s.data)
+	return s
+}
+
+// Len returns the number of elements.
+func (s *SortedSlice[T]) Len() int { return len(s.data) }
+
+// At returns the i-th smallest element.
+func (s *SortedSlice[T]) At(i int) T { return s.data[i] }
+
+// Insert adds v after any equal elements and returns its position.
+func (s *SortedSlice[T]) Insert(v T) int {
+	i := s.upper(v)
+	s.data = slices.Insert(s.data, i, v)
+	return i
+}
+
+// Index returns the position of the first element equal to x, or -1 if there
+// is none, like Index in generic/main.go but with a binary search.
+func (s *SortedSlice[T]) Index(x T) int {
+	if i, found := slices.BinarySearch(s.data, x); found {
+		return i
+	}
+	return -1
+}
+
+// Contains reports whether x is present.
+func (s *SortedSlice[T]) Contains(x T) bool { return s.Index(x) >= 0 }
+
+// Remove deletes one element equal to x and reports whether there was one.
+func (s *SortedSlice[T]) Remove(x T) bool {
+	i := s.Index(x)
+	if i < 0 {
+		return false
+	}
+	s.data = slices.Delete(s.data, i, i+1)
+	return true
+}
+
+// Range yields the elements in [lo, hi) in ascending order.
+func (s *SortedSlice[T]) Range(lo, hi T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		start, _ := slices.BinarySearch(s.data, lo)
+		for _, v := range s.data[start:] {
+			if !cmp.Less(v, hi) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// All yields index/value pairs in ascending order.
+func (s *SortedSlice[T]) All() iter.Seq2[int, T] { return slices.All(s.data) }
+
+// Values yields the elements in ascending order.
+func (s *SortedSlice[T]) Values() iter.Seq[T] { return slices.Values(s.data) }
+
+// upper returns the first index whose element is greater than v.
+func (s *SortedSlice[T]) upper(v T) int {
+	lo, hi := 0, len(s.data)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if cmp.Less(v, s.data[mid]) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
@@ -0,0 +1,44 @@
+package collections_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang/collections"
+)
+
+func TestSortedSliceProperties(t *testing.T) { rounds(t, checkSortedSlice) }
+
+func checkSortedSlice(t *testing.T, r *rand.Rand, ops int) {
+	s := collections.NewSortedSlice[int]()
+	var ref []int
+	for i := 0; i < ops; i++ {
+		v := r.IntN(50)
+		switch r.IntN(3) {
+		case 0:
+			s.Insert(v)
+			ref = append(ref, v)
+			slices.Sort(ref)
+		case 1:
+			want := index(ref, v) >= 0
+			expect(t, s.Remove(v) == want, "op %d Remove(%d)", i, v)
+			if want {
+				ref = slices.Delete(ref, index(ref, v), index(ref, v)+1)
+			}
+		default:
+			expect(t, s.Index(v) == index(ref, v), "op %d Index(%d) = %d, want %d", i, v, s.Index(v), index(ref, v))
+		}
+	}
+	got := slices.Collect(s.Values())
+	expect(t, slices.Equal(got, ref), "Values %v, want %v", got, ref)
+	lo, hi := r.IntN(50), r.IntN(50)
+	var want []int
+	for _, v := range ref {
+		if v >= lo && v < hi {
+			want = append(want, v)
+		}
+	}
+	rng := slices.Collect(s.Range(lo, hi))
+	expect(t, slices.Equal(rng, want), "Range(%d,%d) %v, want %v", lo, hi, rng, want)
+}
@@ -0,0 +1,450 @@
+/*
+Package config fills a struct from defaults, files, environment variables and
+command line flags.
+
+The lessons hard-code their knobs: channels/rangeAndClose.go has
+capacityOfChannel = 2 and slice/append.go has PREDICT_SIZE = 5. A program
+that runs somewhere other than a laptop wants to change those without a
+rebuild. Declaring them as a struct keeps them typed and in one place:
+
+	type Config struct {
+		Listen   string        `flag:"listen" env:"LISTEN" default:":8080" usage:"address to serve on"`
+		Capacity int           `flag:"capacity" env:"CAPACITY" default:"2" usage:"channel buffer size"`
+		Timeout  time.Duration `env:"TIMEOUT" default:"5s"`
+		MaxBody  config.Size   `default:"1MiB"`
+		Peers    []string      `env:"PEERS" usage:"comma separated"`
+		DB       struct {
+			DSN  string `env:"DSN" flag:"dsn" required:"true"`
+			Pool int    `default:"4"`
+		} `env:"DB_" flag:"db."`
+	}
+
+Sources are applied in order, each overriding the one before:
+
+ 1. default tags,
+ 2. files, in the order given (JSON, or a TOML-like key = value format),
+ 3. environment variables named by env tags, after Loader.EnvPrefix,
+ 4. flags named by flag tags.
+
+In files every exported field has a key: the key tag, or the field name in
+snake_case (MaxBody is max_body). Nested structs are sections, db.pool or
+[db] pool = 4. On a nested struct field the env and flag tags are prefixes
+for the fields inside it, so the DSN above is DB_DSN and -db.dsn.
+
+Values are parsed according to the field type: strings, bools, integers,
+floats, time.Duration, Size, anything implementing
+encoding.TextUnmarshaler, and slices of those, comma separated in env,
+flags and defaults. A field with required:"true" that no file, variable or
+flag sets is an error; a default does not count. Usage lists every setting
+with its flag, variable, key and default.
+*/
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Loader describes where settings come from. The zero value reads no files,
+// the real environment and os.Args.
+type Loader struct {
+	// Files are read in order when they exist; a missing file is skipped.
+	Files []string
+	// FileFlag, if set, names a flag that adds a file to read after Files.
+	// Those files must exist. The flag may be repeated.
+	FileFlag string
+	// EnvPrefix is prepended to every env tag, e.g. "APP_".
+	EnvPrefix string
+	// Args are the command line arguments without the program name,
+	// default os.Args[1:]. Name is used in usage output, default os.Args[0].
+	Args []string
+	Name string
+	// LookupEnv defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+	// Output receives flag errors and -help output, default os.Stderr.
+	Output io.Writer
+
+	// Rest is set by Load to the arguments left after the flags.
+	Rest []string
+}
+
+// Load fills dst, a pointer to a struct, from os.Args and the environment.
+func Load(dst any) error { return (&Loader{}).Load(dst) }
+
+// MissingError lists required settings that no source provided.
+type MissingError struct {
+	Settings []string // descriptions such as "db.dsn (-db.dsn, $DB_DSN)"
+}
+
+func (e *MissingError) Error() string {
+	return "config: missing required " + strings.Join(e.Settings, ", ")
+}
+
+// field is one settable leaf of the destination struct.
+type field struct {
+	key      string // file key, dotted for nested structs
+	env      string // full variable name, "" for none
+	flag     string // flag name, "" for none
+	def      string
+	hasDef   bool
+	usage    string
+	required bool
+	v        reflect.Value
+	set      bool // some source provided a value
+}
+
+// Load fills dst. It returns flag.ErrHelp after printing usage if the
+// arguments ask for help, a *MissingError if required settings are absent,
+// and otherwise an error naming the source of the first bad value.
+func (l *Loader) Load(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Load wants a pointer to a struct, got %T", dst)
+	}
+	fields, err := l.fields(rv.Elem())
+	if err != nil {
+		return err
+	}
+
+	// Flags are parsed first, for -help and for FileFlag, but applied last.
+	fs, flagFiles, err := l.parseFlags(fields)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.hasDef {
+			if err := f.setString(f.def); err != nil {
+				return fmt.Errorf("config: default for %s: %w", f.key, err)
+			}
+			f.set = false // a default does not satisfy required
+		}
+	}
+
+	byKey := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+	for _, name := range l.Files {
+		if err := loadFile(name, byKey, true); err != nil {
+			return err
+		}
+	}
+	for _, name := range *flagFiles {
+		if err := loadFile(name, byKey, false); err != nil {
+			return err
+		}
+	}
+
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := lookup(f.env); ok {
+			if err := f.setString(s); err != nil {
+				return fmt.Errorf("config: $%s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		if fv, ok := fl.Value.(*flagValue); ok && flagErr == nil {
+			if err := fv.f.setString(fv.raw); err != nil {
+				flagErr = fmt.Errorf("config: -%s: %w", fl.Name, err)
+			}
+		}
+	})
+	if flagErr != nil {
+		return flagErr
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if f.required && !f.set {
+			missing = append(missing, f.describe())
+		}
+	}
+	if missing != nil {
+		return &MissingError{Settings: missing}
+	}
+	return nil
+}
+
+func (l *Loader) parseFlags(fields []*field) (*flag.FlagSet, *fileList, error) {
+	args, name := l.Args, l.Name
+	if args == nil && len(os.Args) > 0 {
+		args = os.Args[1:]
+	}
+	if name == "" && len(os.Args) > 0 {
+		name = os.Args[0]
+	}
+	out := l.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() { l.usage(out, name, fields) }
+	for _, f := range fields {
+		if f.flag != "" {
+			fs.Var(&flagValue{f: f}, f.flag, f.usage)
+		}
+	}
+	files := &fileList{}
+	if l.FileFlag != "" {
+		fs.Var(files, l.FileFlag, "read settings from `file`")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	l.Rest = fs.Args()
+	return fs, files, nil
+}
+
+// flagValue records the raw text; it is parsed when flags are applied so
+// that a bad flag and a bad file report in precedence order. A list flag
+// may be repeated, -peer a -peer b being the same as -peer a,b.
+type flagValue struct {
+	f   *field
+	raw string
+}
+
+func (fv *flagValue) String() string { return fv.raw }
+func (fv *flagValue) Set(s string) error {
+	if fv.raw != "" && fv.f.v.Kind() == reflect.Slice && !isText(fv.f.v) {
+		s = fv.raw + "," + s
+	}
+	fv.raw = s
+	return nil
+}
+
+// IsBoolFlag lets bool settings be given as -verbose.
+func (fv *flagValue) IsBoolFlag() bool {
+	return fv != nil && fv.f != nil && fv.f.v.Kind() == reflect.Bool
+}
+
+type fileList []string
+
+func (fl *fileList) String() string { return strings.Join(*fl, ",") }
+func (fl *fileList) Set(s string) error {
+	*fl = append(*fl, s)
+	return nil
+}
+
+// fields lists the settable leaves of v, recursing into nested structs.
+func (l *Loader) fields(v reflect.Value) ([]*field, error) {
+	var out []*field
+	seenFlag := make(map[string]string)
+	seenKey := make(map[string]bool)
+	var walk func(v reflect.Value, keyPrefix, envPrefix, flagPrefix string) error
+	walk = func(v reflect.Value, keyPrefix, envPrefix, flagPrefix string) error {
+		t := v.Type()
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			key := sf.Tag.Get("key")
+			if key == "-" {
+				continue
+			}
+			if key == "" {
+				key = snake(sf.Name)
+			}
+			key = keyPrefix + key
+			env, hasEnv := sf.Tag.Lookup("env")
+			fl, hasFlag := sf.Tag.Lookup("flag")
+
+			fv := v.Field(i)
+			if sf.Type.Kind() == reflect.Struct && !isText(fv) {
+				ep, fp := "", ""
+				if hasEnv || envPrefix != "" {
+					ep = envPrefix + env
+				}
+				if hasFlag || flagPrefix != "" {
+					fp = flagPrefix + fl
+				}
+				if err := walk(fv, key+".", ep, fp); err != nil {
+					return err
+				}
+				continue
+			}
+			if !settable(fv) {
+				return fmt.Errorf("config: %s: unsupported type %s", key, sf.Type)
+			}
+			f := &field{key: key, usage: sf.Tag.Get("usage"), v: fv}
+			f.def, f.hasDef = sf.Tag.Lookup("default")
+			f.required = sf.Tag.Get("required") == "true"
+			if hasEnv && env != "" {
+				f.env = l.EnvPrefix + envPrefix + env
+			}
+			if hasFlag && fl != "" {
+				f.flag = flagPrefix + fl
+				if other, dup := seenFlag[f.flag]; dup {
+					return fmt.Errorf("config: flag -%s used by both %s and %s", f.flag, other, key)
+				}
+				seenFlag[f.flag] = key
+			}
+			if seenKey[key] {
+				return fmt.Errorf("config: duplicate key %s", key)
+			}
+			seenKey[key] = true
+			out = append(out, f)
+		}
+		return nil
+	}
+	if err := walk(v, "", "", ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (f *field) describe() string {
+	var via []string
+	if f.flag != "" {
+		via = append(via, "-"+f.flag)
+	}
+	if f.env != "" {
+		via = append(via, "$"+f.env)
+	}
+	if len(via) == 0 {
+		return f.key
+	}
+	return f.key + " (" + strings.Join(via, ", ") + ")"
+}
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	textType     = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+func isText(v reflect.Value) bool { return reflect.PointerTo(v.Type()).Implements(textType) }
+
+func settable(v reflect.Value) bool {
+	if isText(v) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return settable(reflect.New(v.Type().Elem()).Elem())
+	}
+	return false
+}
+
+// setString sets f from one piece of text. Slices are comma separated.
+func (f *field) setString(s string) error {
+	if f.v.Kind() == reflect.Slice && !isText(f.v) {
+		var items []string
+		if strings.TrimSpace(s) != "" {
+			items = strings.Split(s, ",")
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+		}
+		return f.setList(items)
+	}
+	if err := setValue(f.v, s); err != nil {
+		return err
+	}
+	f.set = true
+	return nil
+}
+
+// setList replaces a slice field's contents.
+func (f *field) setList(items []string) error {
+	if f.v.Kind() != reflect.Slice || isText(f.v) {
+		return errors.New("a list is not allowed here")
+	}
+	s := reflect.MakeSlice(f.v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setValue(s.Index(i), item); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	f.v.Set(s)
+	f.set = true
+	return nil
+}
+
+func setValue(v reflect.Value, s string) error {
+	if isText(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", v.Type(), s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.ReplaceAll(s, "_", ""), 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", v.Type(), s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", v.Type(), s)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// snake converts a Go name to snake_case, keeping acronyms together:
+// MaxBody is max_body, HTTPPort is http_port, DBHost is db_host.
+func snake(name string) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]))
+			nextLower := i > 0 && i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
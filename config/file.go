@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// entry is one setting read from a file.
+type entry struct {
+	key    string
+	value  string
+	list   []string
+	isList bool
+	line   int // 0 for JSON, which does not report positions per key
+}
+
+// loadFile applies the settings in name. JSON is recognised by the .json
+// extension; anything else is read as the TOML-like format. Unknown keys
+// are errors, since they are almost always typos.
+func loadFile(name string, byKey map[string]*field, optional bool) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("config: %w", err)
+	}
+	var entries []entry
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		entries, err = parseJSON(data)
+	} else {
+		entries, err = parseTOML(bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+	for _, e := range entries {
+		where := name
+		if e.line > 0 {
+			where += ":" + strconv.Itoa(e.line)
+		}
+		f := byKey[e.key]
+		if f == nil {
+			return fmt.Errorf("config: %s: unknown key %q", where, e.key)
+		}
+		if e.isList {
+			err = f.setList(e.list)
+		} else {
+			err = f.setString(e.value)
+		}
+		if err != nil {
+			return fmt.Errorf("config: %s: %s: %w", where, e.key, err)
+		}
+	}
+	return nil
+}
+
+// parseTOML reads the subset of TOML that configuration files use:
+//
+//	# comment
+//	name = "api"          strings, quoted with " (escapes) or ' (literal)
+//	port = 8080           bare numbers, bools, durations and sizes
+//	peers = ["a", "b"]    single-line arrays
+//	[db]                  sections, also dotted: [db.replica]
+//	pool = 4              db.pool
+//	db.pool = 4           dotted keys work outside sections too
+//
+// Unlike TOML, bare values are kept as text and parsed by the field's type,
+// so timeout = 5s needs no quotes.
+func parseTOML(r io.Reader) ([]entry, error) {
+	var out []entry
+	seen := make(map[string]int)
+	section := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: bad section header %q", n, line)
+			}
+			section = strings.TrimSpace(line[1:len(line)-1]) + "."
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key = value", n)
+		}
+		e := entry{key: section + strings.TrimSpace(k), line: n}
+		if prev, dup := seen[e.key]; dup {
+			return nil, fmt.Errorf("line %d: %s already set on line %d", n, e.key, prev)
+		}
+		seen[e.key] = n
+		v = strings.TrimSpace(v)
+		var err error
+		if strings.HasPrefix(v, "[") {
+			if !strings.HasSuffix(v, "]") {
+				return nil, fmt.Errorf("line %d: arrays must be on one line", n)
+			}
+			e.isList = true
+			e.list, err = splitArray(v[1 : len(v)-1])
+		} else {
+			e.value, err = unquote(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+// stripComment removes a # comment that is not inside quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		case quote == 0 && c == '#':
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func unquote(v string) (string, error) {
+	switch {
+	case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+		return strconv.Unquote(v)
+	case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+		return v[1 : len(v)-1], nil
+	case strings.ContainsAny(v, `"'`):
+		return "", fmt.Errorf("unbalanced quotes in %s", v)
+	}
+	return v, nil
+}
+
+// splitArray splits the inside of [a, "b,c", 'd'] on commas outside quotes.
+func splitArray(s string) ([]string, error) {
+	var out []string
+	var quote byte
+	start := 0
+	flush := func(end int) error {
+		item := strings.TrimSpace(s[start:end])
+		if item == "" {
+			return nil // [] and a trailing comma
+		}
+		v, err := unquote(item)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+		return nil
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		case quote == 0 && c == ',':
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated string in array")
+	}
+	if err := flush(len(s)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// parseJSON flattens nested objects into dotted keys. Arrays become lists
+// of their elements' text; numbers keep their exact spelling.
+func parseJSON(data []byte) ([]entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var root map[string]any
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+	var out []entry
+	var walk func(prefix string, m map[string]any) error
+	walk = func(prefix string, m map[string]any) error {
+		for k, v := range m {
+			key := prefix + k
+			switch v := v.(type) {
+			case nil:
+				// null leaves the setting as it is.
+			case map[string]any:
+				if err := walk(key+".", v); err != nil {
+					return err
+				}
+			case []any:
+				e := entry{key: key, isList: true, list: make([]string, 0, len(v))}
+				for _, item := range v {
+					s, ok := jsonScalar(item)
+					if !ok {
+						return fmt.Errorf("%s: arrays may only hold strings, numbers and bools", key)
+					}
+					e.list = append(e.list, s)
+				}
+				out = append(out, e)
+			default:
+				s, _ := jsonScalar(v)
+				out = append(out, entry{key: key, value: s})
+			}
+		}
+		return nil
+	}
+	if err := walk("", root); err != nil {
+		return nil, err
+	}
+	// Map iteration is random; sort so the first error is always the same.
+	slices.SortFunc(out, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+	return out, nil
+}
+
+func jsonScalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
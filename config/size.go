@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size is a byte count that parses human units: 512, 64KB, 1.5GiB.
+// KB, MB, GB and TB are powers of 1000, KiB, MiB, GiB and TiB powers of
+// 1024, as on disk labels and in the IEC standard respectively. A bare K,
+// M, G or T is read as the binary unit, which is what people mean when they
+// write a buffer size.
+type Size int64
+
+const (
+	Byte Size = 1
+	KB   Size = 1000
+	MB        = 1000 * KB
+	GB        = 1000 * MB
+	TB        = 1000 * GB
+	KiB  Size = 1 << 10
+	MiB  Size = 1 << 20
+	GiB  Size = 1 << 30
+	TiB  Size = 1 << 40
+)
+
+var sizeUnits = map[string]Size{
+	"": Byte, "b": Byte,
+	"kb": KB, "mb": MB, "gb": GB, "tb": TB,
+	"kib": KiB, "mib": MiB, "gib": GiB, "tib": TiB,
+	"k": KiB, "m": MiB, "g": GiB, "t": TiB,
+}
+
+// ParseSize parses a number followed by an optional unit, case-insensitively
+// and with optional space between them.
+func ParseSize(s string) (Size, error) {
+	t := strings.TrimSpace(s)
+	i := 0
+	for i < len(t) && (t[i] >= '0' && t[i] <= '9' || t[i] == '.') {
+		i++
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(t[i:]))]
+	if i == 0 || !ok {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if n, err := strconv.ParseInt(t[:i], 10, 64); err == nil {
+		if n > int64(1<<63-1)/int64(unit) {
+			return 0, fmt.Errorf("size %q overflows", s)
+		}
+		return Size(n) * unit, nil
+	}
+	f, err := strconv.ParseFloat(t[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if f*float64(unit) >= 1<<63 {
+		return 0, fmt.Errorf("size %q overflows", s)
+	}
+	return Size(f * float64(unit)), nil
+}
+
+// UnmarshalText lets Size fields be set from files, env and flags.
+func (s *Size) UnmarshalText(b []byte) error {
+	v, err := ParseSize(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// String uses the largest binary unit that divides s exactly, so that it
+// parses back to the same value.
+func (s Size) String() string {
+	for _, u := range []struct {
+		size Size
+		name string
+	}{{TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}} {
+		if s != 0 && s%u.size == 0 {
+			return strconv.FormatInt(int64(s/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatInt(int64(s), 10) + "B"
+}
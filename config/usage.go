@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage writes the settings of dst, a pointer to a struct, as a table:
+//
+//	FLAG              ENV             KEY        DEFAULT  DESCRIPTION
+//	-listen string    APP_LISTEN      listen     :8080    address to serve on
+//	-db.dsn string    APP_DB_DSN      db.dsn              required
+//
+// It is also what -h and -help print.
+func (l *Loader) Usage(w io.Writer, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Usage wants a pointer to a struct, got %T", dst)
+	}
+	fields, err := l.fields(rv.Elem())
+	if err != nil {
+		return err
+	}
+	name := l.Name
+	if name == "" {
+		name = "command"
+	}
+	l.usage(w, name, fields)
+	return nil
+}
+
+func (l *Loader) usage(w io.Writer, name string, fields []*field) {
+	fmt.Fprintf(w, "Usage of %s:\n", name)
+	fmt.Fprintln(w, "Settings are read from defaults, then files, then the environment, then flags.")
+	if len(l.Files) > 0 {
+		fmt.Fprintf(w, "Files: %s\n", strings.Join(l.Files, ", "))
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV\tKEY\tDEFAULT\tDESCRIPTION")
+	if l.FileFlag != "" {
+		fmt.Fprintf(tw, "-%s file\t\t\t\tread settings from file, may be repeated\n", l.FileFlag)
+	}
+	for _, f := range fields {
+		flagCol := ""
+		if f.flag != "" {
+			flagCol = "-" + f.flag
+			if t := typeName(f.v.Type()); t != "" {
+				flagCol += " " + t
+			}
+		}
+		def := f.def
+		if f.hasDef && def == "" {
+			def = `""`
+		}
+		desc := f.usage
+		if f.required {
+			desc = strings.TrimSpace("required " + desc)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", flagCol, f.env, f.key, def, desc)
+	}
+	tw.Flush()
+}
+
+// typeName is the word after a flag in usage, as the flag package prints
+// it; bools take none.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t == reflect.TypeFor[Size]():
+		return "size"
+	case t.Kind() == reflect.Bool:
+		return ""
+	case t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(textType):
+		return typeName(t.Elem()) + ",..."
+	case reflect.PointerTo(t).Implements(textType):
+		return "value"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "float"
+	}
+	return t.Kind().String()
+}
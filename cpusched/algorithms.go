@@ -0,0 +1,230 @@
+package cpusched
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FCFS runs processes to completion in arrival order.
+type FCFS struct{}
+
+func (FCFS) Name() string                             { return "FCFS" }
+func (FCFS) Schedule(procs []Process) (Result, error) { return simulate("FCFS", procs, fcfs{}) }
+
+type fcfs struct{}
+
+func (fcfs) preempt(int, *task, []*task) bool { return false }
+func (fcfs) choose(int, []*task) int          { return 0 }
+func (fcfs) tick(int, *task, []*task)         {}
+
+// SJF is non-preemptive Shortest Job First: whenever the CPU frees up, the
+// ready process with the smallest burst runs to completion.
+type SJF struct{}
+
+func (SJF) Name() string                             { return "SJF" }
+func (SJF) Schedule(procs []Process) (Result, error) { return simulate("SJF", procs, sjf{}) }
+
+type sjf struct{}
+
+func (sjf) preempt(int, *task, []*task) bool { return false }
+func (sjf) choose(_ int, ready []*task) int {
+	return argmin(ready, func(a, b *task) bool { return a.Burst < b.Burst })
+}
+func (sjf) tick(int, *task, []*task) {}
+
+// SRTF is the preemptive form of SJF: a newly arrived process with less
+// remaining work than the running one takes the CPU immediately.
+type SRTF struct{}
+
+func (SRTF) Name() string                             { return "SRTF" }
+func (SRTF) Schedule(procs []Process) (Result, error) { return simulate("SRTF", procs, srtf{}) }
+
+type srtf struct{}
+
+func (srtf) preempt(_ int, cur *task, ready []*task) bool {
+	for _, r := range ready {
+		if r.remaining < cur.remaining {
+			return true
+		}
+	}
+	return false
+}
+func (srtf) choose(_ int, ready []*task) int {
+	return argmin(ready, func(a, b *task) bool { return a.remaining < b.remaining })
+}
+func (srtf) tick(int, *task, []*task) {}
+
+// RoundRobin gives every process a fixed Quantum and then moves it to the back
+// of the ready queue.
+type RoundRobin struct {
+	Quantum int
+}
+
+func (rr RoundRobin) Name() string { return fmt.Sprintf("RR(q=%d)", rr.Quantum) }
+
+func (rr RoundRobin) Schedule(procs []Process) (Result, error) {
+	if rr.Quantum <= 0 {
+		return Result{}, fmt.Errorf("cpusched: round robin quantum must be positive, got %d", rr.Quantum)
+	}
+	return simulate(rr.Name(), procs, roundRobin{quantum: rr.Quantum})
+}
+
+type roundRobin struct{ quantum int }
+
+func (p roundRobin) preempt(_ int, cur *task, _ []*task) bool { return cur.used >= p.quantum }
+func (roundRobin) choose(int, []*task) int                    { return 0 }
+func (roundRobin) tick(int, *task, []*task)                   {}
+
+// Priority runs the most important ready process (lowest Priority value).
+//
+// With AgingInterval > 0 a process that has waited that many ticks in the
+// ready queue has its effective priority improved by one, which is how the
+// notes suggest fixing starvation. The boost lasts while the process runs, so
+// a preemptive scheduler does not hand the CPU straight back to the process it
+// aged past; it is dropped when the process is preempted.
+type Priority struct {
+	Preemptive    bool
+	AgingInterval int
+}
+
+func (p Priority) Name() string {
+	name := "Priority"
+	if p.Preemptive {
+		name += "-P"
+	}
+	if p.AgingInterval > 0 {
+		name += fmt.Sprintf("(aging=%d)", p.AgingInterval)
+	}
+	return name
+}
+
+func (p Priority) Schedule(procs []Process) (Result, error) {
+	return simulate(p.Name(), procs, priority(p))
+}
+
+type priority Priority
+
+func (p priority) preempt(_ int, cur *task, ready []*task) bool {
+	if !p.Preemptive {
+		return false
+	}
+	for _, r := range ready {
+		if r.prio < cur.prio {
+			cur.prio = cur.Priority
+			return true
+		}
+	}
+	return false
+}
+
+func (priority) choose(_ int, ready []*task) int {
+	return argmin(ready, func(a, b *task) bool { return a.prio < b.prio })
+}
+
+func (p priority) tick(_ int, _ *task, ready []*task) {
+	if p.AgingInterval <= 0 {
+		return
+	}
+	for _, r := range ready {
+		if r.waited%p.AgingInterval == 0 {
+			r.prio--
+		}
+	}
+}
+
+// MLFQ is a Multilevel Feedback Queue. New processes enter level 0; a process
+// that uses up its level's quantum is demoted one level. Higher levels always
+// preempt lower ones, and every BoostInterval ticks all processes are moved
+// back to level 0 so long jobs cannot starve.
+type MLFQ struct {
+	Quanta        []int // quantum per level, highest priority first
+	BoostInterval int   // 0 disables the periodic boost
+}
+
+func (m MLFQ) Name() string {
+	q := make([]string, len(m.Quanta))
+	for i, v := range m.Quanta {
+		q[i] = strconv.Itoa(v)
+	}
+	name := "MLFQ(" + strings.Join(q, ",")
+	if m.BoostInterval > 0 {
+		name += fmt.Sprintf(";boost=%d", m.BoostInterval)
+	}
+	return name + ")"
+}
+
+func (m MLFQ) Schedule(procs []Process) (Result, error) {
+	if len(m.Quanta) == 0 {
+		return Result{}, fmt.Errorf("cpusched: mlfq needs at least one level")
+	}
+	for i, q := range m.Quanta {
+		if q <= 0 {
+			return Result{}, fmt.Errorf("cpusched: mlfq level %d quantum must be positive, got %d", i, q)
+		}
+	}
+	return simulate(m.Name(), procs, mlfq(m))
+}
+
+type mlfq MLFQ
+
+func (m mlfq) preempt(_ int, cur *task, ready []*task) bool {
+	if cur.used >= m.Quanta[cur.level] {
+		if cur.level < len(m.Quanta)-1 {
+			cur.level++
+		}
+		return true
+	}
+	for _, r := range ready {
+		if r.level < cur.level {
+			return true
+		}
+	}
+	return false
+}
+
+// choose takes the first task of the highest non-empty level; the ready slice
+// keeps FIFO order inside a level.
+func (mlfq) choose(_ int, ready []*task) int {
+	best := 0
+	for i, r := range ready {
+		if r.level < ready[best].level {
+			best = i
+		}
+	}
+	return best
+}
+
+func (m mlfq) tick(t int, cur *task, ready []*task) {
+	if m.BoostInterval <= 0 || (t+1)%m.BoostInterval != 0 {
+		return
+	}
+	for _, r := range ready {
+		r.level = 0
+	}
+	if cur != nil {
+		cur.level, cur.used = 0, 0
+	}
+}
+
+// Parse builds a Scheduler from a short name as used by the cpusched command:
+// fcfs, sjf, srtf, rr, priority, priority-p, mlfq.
+func Parse(name string, quantum, aging int, quanta []int, boost int) (Scheduler, error) {
+	switch strings.ToLower(name) {
+	case "fcfs":
+		return FCFS{}, nil
+	case "sjf":
+		return SJF{}, nil
+	case "srtf":
+		return SRTF{}, nil
+	case "rr":
+		return RoundRobin{Quantum: quantum}, nil
+	case "priority":
+		return Priority{AgingInterval: aging}, nil
+	case "priority-p":
+		return Priority{Preemptive: true, AgingInterval: aging}, nil
+	case "mlfq":
+		return MLFQ{Quanta: quanta, BoostInterval: boost}, nil
+	}
+	return nil, fmt.Errorf("cpusched: unknown algorithm %q", name)
+}
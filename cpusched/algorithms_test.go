@@ -0,0 +1,121 @@
+package cpusched_test
+
+import (
+	"fmt"
+	"testing"
+
+	"golang/cpusched"
+)
+
+// The process sets and averages are the worked examples of the usual
+// operating systems textbooks.
+var (
+	// Three CPU-bound jobs arriving together, the longest first.
+	convoy = []cpusched.Process{
+		{PID: "P1", Burst: 24},
+		{PID: "P2", Burst: 3},
+		{PID: "P3", Burst: 3},
+	}
+	shortest = []cpusched.Process{
+		{PID: "P1", Burst: 6},
+		{PID: "P2", Burst: 8},
+		{PID: "P3", Burst: 7},
+		{PID: "P4", Burst: 3},
+	}
+	staggered = []cpusched.Process{
+		{PID: "P1", Arrival: 0, Burst: 8},
+		{PID: "P2", Arrival: 1, Burst: 4},
+		{PID: "P3", Arrival: 2, Burst: 9},
+		{PID: "P4", Arrival: 3, Burst: 5},
+	}
+	prioritised = []cpusched.Process{
+		{PID: "P1", Burst: 10, Priority: 3},
+		{PID: "P2", Burst: 1, Priority: 1},
+		{PID: "P3", Burst: 2, Priority: 4},
+		{PID: "P4", Burst: 1, Priority: 5},
+		{PID: "P5", Burst: 5, Priority: 2},
+	}
+)
+
+func TestTextbookAverages(t *testing.T) {
+	for _, tc := range []struct {
+		s          cpusched.Scheduler
+		procs      []cpusched.Process
+		waiting    float64
+		turnaround float64
+		timeline   string
+	}{
+		{cpusched.FCFS{}, convoy, 17, 27, "[P1 0-24] [P2 24-27] [P3 27-30]"},
+		{cpusched.SJF{}, shortest, 7, 13, "[P4 0-3] [P1 3-9] [P3 9-16] [P2 16-24]"},
+		{cpusched.SRTF{}, staggered, 6.5, 13, "[P1 0-1] [P2 1-5] [P4 5-10] [P1 10-17] [P3 17-26]"},
+		{cpusched.RoundRobin{Quantum: 4}, convoy, 17.0 / 3, 47.0 / 3,
+			"[P1 0-4] [P2 4-7] [P3 7-10] [P1 10-30]"},
+		{cpusched.Priority{}, prioritised, 8.2, 12, "[P2 0-1] [P5 1-6] [P1 6-16] [P3 16-18] [P4 18-19]"},
+	} {
+		t.Run(tc.s.Name(), func(t *testing.T) {
+			res, err := tc.s.Schedule(tc.procs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := timeline(res); got != tc.timeline {
+				t.Errorf("timeline %s, want %s", got, tc.timeline)
+			}
+			if !near(res.AvgWaiting(), tc.waiting) || !near(res.AvgTurnaround(), tc.turnaround) {
+				t.Errorf("average waiting %.2f, turnaround %.2f; want %.2f, %.2f",
+					res.AvgWaiting(), res.AvgTurnaround(), tc.waiting, tc.turnaround)
+			}
+		})
+	}
+}
+
+func TestMLFQDemotesAndPreempts(t *testing.T) {
+	procs := []cpusched.Process{
+		{PID: "long", Burst: 30},
+		{PID: "short", Arrival: 10, Burst: 5},
+	}
+	res, err := cpusched.MLFQ{Quanta: []int{8, 16}}.Schedule(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// long uses its level 0 quantum and drops to level 1, where the newly
+	// arrived short job preempts it.
+	if got, want := timeline(res), "[long 0-10] [short 10-15] [long 15-35]"; got != want {
+		t.Errorf("timeline %s, want %s", got, want)
+	}
+	if res.Stats[1].Response != 0 || res.Stats[0].Waiting != 5 {
+		t.Errorf("stats %+v", res.Stats)
+	}
+}
+
+// TestAgingKeepsBoostWhileRunning ages a low priority process past a high
+// priority one. The boost must carry it through its run rather than lapse
+// on dispatch and hand the CPU straight back.
+func TestAgingKeepsBoostWhileRunning(t *testing.T) {
+	procs := []cpusched.Process{
+		{PID: "low", Burst: 10, Priority: 5},
+		{PID: "high", Burst: 20, Priority: 1},
+	}
+	res, err := cpusched.Priority{Preemptive: true, AgingInterval: 2}.Schedule(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := timeline(res), "[high 0-10] [low 10-14] [high 14-24] [low 24-30]"; got != want {
+		t.Errorf("timeline %s, want %s", got, want)
+	}
+	if res.ContextSwitches != 3 {
+		t.Errorf("%d context switches, want 3", res.ContextSwitches)
+	}
+}
+
+func timeline(r cpusched.Result) string {
+	s := ""
+	for i, seg := range r.Timeline {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("[%s %d-%d]", seg.PID, seg.Start, seg.End)
+	}
+	return s
+}
+
+func near(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
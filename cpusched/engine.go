@@ -0,0 +1,203 @@
+package cpusched
+
+import "sort"
+
+// Segment is one contiguous stretch of the Gantt chart.
+// An empty PID means the CPU was idle.
+type Segment struct {
+	PID        string
+	Start, End int
+}
+
+// Stat holds the per-process metrics of a finished simulation.
+type Stat struct {
+	Process
+	FirstRun   int
+	Completion int
+	Turnaround int // Completion - Arrival
+	Waiting    int // Turnaround - Burst
+	Response   int // FirstRun - Arrival
+}
+
+// Result is the outcome of running one algorithm over a process list.
+type Result struct {
+	Algorithm       string
+	Timeline        []Segment
+	Stats           []Stat // in input order
+	ContextSwitches int
+}
+
+// Makespan is the tick at which the last process completed.
+func (r Result) Makespan() int {
+	if len(r.Timeline) == 0 {
+		return 0
+	}
+	return r.Timeline[len(r.Timeline)-1].End
+}
+
+// AvgWaiting returns the mean waiting time.
+func (r Result) AvgWaiting() float64 { return r.avg(func(s Stat) int { return s.Waiting }) }
+
+// AvgTurnaround returns the mean turnaround time.
+func (r Result) AvgTurnaround() float64 { return r.avg(func(s Stat) int { return s.Turnaround }) }
+
+// AvgResponse returns the mean response time.
+func (r Result) AvgResponse() float64 { return r.avg(func(s Stat) int { return s.Response }) }
+
+// Utilization is the fraction of the makespan the CPU was busy.
+func (r Result) Utilization() float64 {
+	busy := 0
+	for _, s := range r.Timeline {
+		if s.PID != "" {
+			busy += s.End - s.Start
+		}
+	}
+	if m := r.Makespan(); m > 0 {
+		return float64(busy) / float64(m)
+	}
+	return 0
+}
+
+func (r Result) avg(f func(Stat) int) float64 {
+	if len(r.Stats) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, s := range r.Stats {
+		sum += f(s)
+	}
+	return float64(sum) / float64(len(r.Stats))
+}
+
+// Scheduler is a scheduling algorithm.
+type Scheduler interface {
+	Name() string
+	Schedule(procs []Process) (Result, error)
+}
+
+// task is the simulator's mutable view of a process.
+type task struct {
+	Process
+	index     int // position in the input, used for stable ordering
+	remaining int
+	started   bool
+	firstRun  int
+	finish    int
+
+	used   int // ticks consumed in the current dispatch
+	waited int // ticks spent in the ready queue since the last dispatch
+	prio   int // effective priority after aging
+	level  int // MLFQ queue level
+}
+
+// policy is the part that differs between algorithms. The engine owns the
+// clock and the ready queue; the policy only answers questions about them.
+type policy interface {
+	// preempt reports whether the running task must give up the CPU at tick t.
+	preempt(t int, cur *task, ready []*task) bool
+	// choose returns the index in ready of the task to dispatch next.
+	choose(t int, ready []*task) int
+	// tick is called once per simulated tick after the CPU ran cur (nil when idle).
+	tick(t int, cur *task, ready []*task)
+}
+
+func simulate(name string, procs []Process, p policy) (Result, error) {
+	if err := Validate(procs); err != nil {
+		return Result{}, err
+	}
+
+	tasks := make([]*task, len(procs))
+	for i, pr := range procs {
+		tasks[i] = &task{Process: pr, index: i, remaining: pr.Burst, prio: pr.Priority}
+	}
+	arrivals := append([]*task(nil), tasks...)
+	sort.SliceStable(arrivals, func(i, j int) bool { return arrivals[i].Arrival < arrivals[j].Arrival })
+
+	res := Result{Algorithm: name}
+	var (
+		ready []*task
+		cur   *task
+		last  *task // last task that actually ran, for context switch counting
+		next  int   // next entry of arrivals to admit
+		done  int
+	)
+	for t := 0; done < len(tasks); t++ {
+		for next < len(arrivals) && arrivals[next].Arrival <= t {
+			ready = append(ready, arrivals[next])
+			next++
+		}
+
+		if cur != nil && p.preempt(t, cur, ready) {
+			ready = append(ready, cur)
+			cur = nil
+		}
+		if cur == nil && len(ready) > 0 {
+			i := p.choose(t, ready)
+			cur = ready[i]
+			ready = append(ready[:i], ready[i+1:]...)
+			cur.used, cur.waited = 0, 0
+			if !cur.started {
+				cur.started, cur.firstRun = true, t
+			}
+		}
+
+		pid := ""
+		if cur != nil {
+			pid = cur.PID
+			cur.remaining--
+			cur.used++
+			if last != nil && last != cur {
+				res.ContextSwitches++
+			}
+			last = cur
+		}
+		res.Timeline = appendSegment(res.Timeline, pid, t)
+		for _, r := range ready {
+			r.waited++
+		}
+		p.tick(t, cur, ready)
+
+		if cur != nil && cur.remaining == 0 {
+			cur.finish = t + 1
+			done++
+			cur = nil
+		}
+	}
+
+	res.Stats = make([]Stat, len(tasks))
+	for i, tk := range tasks {
+		s := Stat{Process: tk.Process, FirstRun: tk.firstRun, Completion: tk.finish}
+		s.Turnaround = s.Completion - s.Arrival
+		s.Waiting = s.Turnaround - s.Burst
+		s.Response = s.FirstRun - s.Arrival
+		res.Stats[i] = s
+	}
+	return res, nil
+}
+
+// appendSegment extends the last segment when pid keeps running, otherwise it
+// opens a new one starting at t.
+func appendSegment(tl []Segment, pid string, t int) []Segment {
+	if n := len(tl); n > 0 && tl[n-1].PID == pid && tl[n-1].End == t {
+		tl[n-1].End = t + 1
+		return tl
+	}
+	return append(tl, Segment{PID: pid, Start: t, End: t + 1})
+}
+
+// argmin returns the index of the smallest element of ready according to less,
+// breaking ties by arrival time and then input order.
+func argmin(ready []*task, less func(a, b *task) bool) int {
+	best := 0
+	for i := 1; i < len(ready); i++ {
+		a, b := ready[i], ready[best]
+		switch {
+		case less(a, b):
+			best = i
+		case less(b, a):
+		case a.Arrival < b.Arrival, a.Arrival == b.Arrival && a.index < b.index:
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,111 @@
+package cpusched
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteASCII draws the timeline as a text Gantt chart, scale characters per tick.
+//
+//	|  P1  |  P2  |idle|  P1  |
+//	0      3      6    8      11
+func WriteASCII(w io.Writer, r Result, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	var bar, axis strings.Builder
+	bar.WriteByte('|')
+	axis.WriteByte('0')
+	for _, s := range r.Timeline {
+		width := (s.End-s.Start)*scale - 1
+		label := s.PID
+		if label == "" {
+			label = "idle"
+		}
+		if len(label) > width {
+			label = label[:max(width, 0)]
+		}
+		pad := width - len(label)
+		bar.WriteString(strings.Repeat(" ", pad/2) + label + strings.Repeat(" ", pad-pad/2) + "|")
+
+		// The axis label sits under the closing '|'; skip it when the previous
+		// one has not finished printing yet.
+		end := strconv.Itoa(s.End)
+		if gap := bar.Len() - 1 - axis.Len(); gap >= 0 {
+			axis.WriteString(strings.Repeat(" ", gap) + end)
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s\n%s\n%s\n", r.Algorithm, bar.String(), axis.String())
+	return err
+}
+
+// palette is used to colour processes in SVG charts, cycling if there are more
+// processes than colours.
+var palette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+// WriteSVG renders one row per result so several algorithms over the same
+// process list can be compared in a single image.
+func WriteSVG(w io.Writer, results ...Result) error {
+	const (
+		tickW   = 24
+		rowH    = 28
+		rowGap  = 22
+		labelW  = 150
+		topPad  = 10
+		fontCSS = "font-family:monospace;font-size:12px"
+	)
+	span := 0
+	colour := map[string]string{}
+	for _, r := range results {
+		span = max(span, r.Makespan())
+		for _, s := range r.Stats {
+			if _, ok := colour[s.PID]; !ok {
+				colour[s.PID] = palette[len(colour)%len(palette)]
+			}
+		}
+	}
+	width := labelW + span*tickW + 20
+	height := topPad + len(results)*(rowH+rowGap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" style="%s">`+"\n", width, height, fontCSS)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	for row, r := range results {
+		y := topPad + row*(rowH+rowGap)
+		fmt.Fprintf(&b, `<text x="4" y="%d">%s</text>`+"\n", y+rowH/2+4, html.EscapeString(r.Algorithm))
+		for _, s := range r.Timeline {
+			x := labelW + s.Start*tickW
+			sw := (s.End - s.Start) * tickW
+			fill, label := "#eeeeee", "idle"
+			if s.PID != "" {
+				fill, label = colour[s.PID], s.PID
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="black"/>`+"\n", x, y, sw, rowH, fill)
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n", x+sw/2, y+rowH/2+4, html.EscapeString(label))
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-size="10">%d</text>`+"\n", x+sw, y+rowH+12, s.End)
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-size="10">0</text>`+"\n", labelW, y+rowH+12)
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteStats prints the per-process table and the averages of one result.
+func WriteStats(w io.Writer, r Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-8s %7s %5s %4s %6s %10s %7s %8s\n", "PID", "arrival", "burst", "prio", "finish", "turnaround", "waiting", "response")
+	for _, s := range r.Stats {
+		fmt.Fprintf(&b, "%-8s %7d %5d %4d %6d %10d %7d %8d\n", s.PID, s.Arrival, s.Burst, s.Priority, s.Completion, s.Turnaround, s.Waiting, s.Response)
+	}
+	fmt.Fprintf(&b, "avg turnaround %.2f  avg waiting %.2f  avg response %.2f  switches %d  cpu %.0f%%\n",
+		r.AvgTurnaround(), r.AvgWaiting(), r.AvgResponse(), r.ContextSwitches, r.Utilization()*100)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
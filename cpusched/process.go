@@ -0,0 +1,117 @@
+/*
+Package cpusched simulates the classic OS CPU scheduling algorithms described in
+concept/OS/CPU_Achirecture/cpu_schduling_ago.md: FCFS, SJF, SRTF, Round Robin,
+Priority (with aging) and a Multilevel Feedback Queue.
+
+Time is discrete: every process arrives at an integer tick and needs an integer
+number of ticks of CPU. A simulation produces a Gantt timeline plus the usual
+waiting / turnaround / response metrics, so the algorithms can be compared side
+by side against the notes on the Go scheduler.
+*/
+package cpusched
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Process is one job handed to the scheduler.
+// A lower Priority value means a more important process.
+type Process struct {
+	PID      string `json:"pid"`
+	Arrival  int    `json:"arrival"`
+	Burst    int    `json:"burst"`
+	Priority int    `json:"priority"`
+}
+
+// Validate checks that the process list can be simulated.
+func Validate(procs []Process) error {
+	if len(procs) == 0 {
+		return fmt.Errorf("cpusched: empty process list")
+	}
+	seen := make(map[string]bool, len(procs))
+	for i, p := range procs {
+		switch {
+		case p.PID == "":
+			return fmt.Errorf("cpusched: process %d has no pid", i)
+		case seen[p.PID]:
+			return fmt.Errorf("cpusched: duplicate pid %q", p.PID)
+		case p.Arrival < 0:
+			return fmt.Errorf("cpusched: %s: negative arrival %d", p.PID, p.Arrival)
+		case p.Burst <= 0:
+			return fmt.Errorf("cpusched: %s: burst must be positive, got %d", p.PID, p.Burst)
+		}
+		seen[p.PID] = true
+	}
+	return nil
+}
+
+// LoadCSV reads processes from CSV with a header row.
+// The pid, arrival and burst columns are required, priority is optional.
+// Column order does not matter.
+func LoadCSV(r io.Reader) ([]Process, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cpusched: read csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("cpusched: csv has no header")
+	}
+
+	col := map[string]int{"pid": -1, "arrival": -1, "burst": -1, "priority": -1}
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := col[name]; ok {
+			col[name] = i
+		}
+	}
+	for _, name := range []string{"pid", "arrival", "burst"} {
+		if col[name] < 0 {
+			return nil, fmt.Errorf("cpusched: csv header is missing %q", name)
+		}
+	}
+
+	procs := make([]Process, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		line := n + 2
+		field := func(name string) (int, error) {
+			i := col[name]
+			if i < 0 || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				return 0, nil
+			}
+			v, err := strconv.Atoi(strings.TrimSpace(row[i]))
+			if err != nil {
+				return 0, fmt.Errorf("cpusched: csv line %d: %s: %w", line, name, err)
+			}
+			return v, nil
+		}
+		p := Process{PID: strings.TrimSpace(row[col["pid"]])}
+		if p.Arrival, err = field("arrival"); err != nil {
+			return nil, err
+		}
+		if p.Burst, err = field("burst"); err != nil {
+			return nil, err
+		}
+		if p.Priority, err = field("priority"); err != nil {
+			return nil, err
+		}
+		procs = append(procs, p)
+	}
+	return procs, Validate(procs)
+}
+
+// LoadJSON reads a JSON array of processes.
+func LoadJSON(r io.Reader) ([]Process, error) {
+	var procs []Process
+	if err := json.NewDecoder(r).Decode(&procs); err != nil {
+		return nil, fmt.Errorf("cpusched: decode json: %w", err)
+	}
+	return procs, Validate(procs)
+}
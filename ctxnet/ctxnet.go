@@ -0,0 +1,168 @@
+/*
+Package ctxnet makes blocking socket I/O honor a context.
+
+net.Conn has no context parameter: a goroutine parked in Read sits in the
+netpoller until the fd becomes readable or its deadline passes
+(advance-concept/19. Async Patterns & Backpressure). ctxnet bridges the two
+worlds the same way net/http does internally: the context deadline becomes the
+socket deadline, and cancellation "pokes" the deadline into the past, which
+makes the runtime timer fire, the netpoller wake the parked goroutine and Read
+return immediately.
+*/
+package ctxnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// aLongTimeAgo is a deadline that has always already passed.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// Conn is a net.Conn whose Read and Write are bound to a context.
+//
+// Like deadlines on a plain net.Conn, the poke affects every pending call in
+// the same direction, so use at most one reader and one writer at a time.
+type Conn struct {
+	net.Conn
+
+	// mu guards ctx, the context used by plain Read and Write.
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// Wrap binds c to ctx. Read and Write on the result fail once ctx is done.
+func Wrap(ctx context.Context, c net.Conn) *Conn {
+	return &Conn{Conn: c, ctx: ctx}
+}
+
+// Dial connects like net.Dialer.DialContext. ctx bounds the dial and stays
+// bound to the returned Conn for later Read and Write calls.
+func Dial(ctx context.Context, network, address string) (*Conn, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(ctx, c), nil
+}
+
+// WithContext rebinds the context used by Read and Write.
+func (c *Conn) WithContext(ctx context.Context) *Conn {
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+	return c
+}
+
+func (c *Conn) context() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx
+}
+
+// Read reads using the bound context.
+func (c *Conn) Read(p []byte) (int, error) { return c.ReadContext(c.context(), p) }
+
+// Write writes using the bound context.
+func (c *Conn) Write(p []byte) (int, error) { return c.WriteContext(c.context(), p) }
+
+// ReadContext reads into p, returning early with the context's error if ctx
+// is canceled or its deadline passes while the read is blocked.
+func (c *Conn) ReadContext(ctx context.Context, p []byte) (int, error) {
+	return c.do(ctx, "read", c.Conn.SetReadDeadline, func() (int, error) { return c.Conn.Read(p) })
+}
+
+// WriteContext is the write counterpart of ReadContext.
+func (c *Conn) WriteContext(ctx context.Context, p []byte) (int, error) {
+	return c.do(ctx, "write", c.Conn.SetWriteDeadline, func() (int, error) { return c.Conn.Write(p) })
+}
+
+func (c *Conn) do(ctx context.Context, op string, setDeadline func(time.Time) error, io func() (int, error)) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, c.opError(op, context.Cause(ctx))
+	}
+	deadline, _ := ctx.Deadline()
+	if err := setDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	poked := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		setDeadline(aLongTimeAgo)
+		close(poked)
+	})
+	n, err := io()
+	if !stop() {
+		// The poke ran (or is running). Wait for it so that resetting the
+		// deadline below cannot be overtaken by it.
+		<-poked
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			err = c.opError(op, context.Cause(ctx))
+		}
+	} else if errors.Is(err, os.ErrDeadlineExceeded) && !deadline.IsZero() {
+		// The socket timer fired a hair before the context's own timer.
+		err = c.opError(op, context.DeadlineExceeded)
+	}
+	setDeadline(time.Time{})
+	return n, err
+}
+
+func (c *Conn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: c.LocalAddr().Network(), Source: c.LocalAddr(), Addr: c.RemoteAddr(), Err: err}
+}
+
+// Listener accepts connections with a context.
+type Listener struct {
+	net.Listener
+}
+
+// Listen announces on the local network address.
+func Listen(ctx context.Context, network, address string) (*Listener, error) {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{ln}, nil
+}
+
+// deadliner is implemented by *net.TCPListener and *net.UnixListener.
+type deadliner interface {
+	SetDeadline(time.Time) error
+}
+
+// AcceptContext waits for the next connection or for ctx to be done. The
+// accepted connection is bound to ctx.
+func (l *Listener) AcceptContext(ctx context.Context) (*Conn, error) {
+	dl, ok := l.Listener.(deadliner)
+	if !ok {
+		return nil, errors.New("ctxnet: listener does not support deadlines")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	dl.SetDeadline(deadline)
+	poked := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		dl.SetDeadline(aLongTimeAgo)
+		close(poked)
+	})
+	c, err := l.Accept()
+	if !stop() {
+		<-poked
+		if err != nil {
+			err = &net.OpError{Op: "accept", Net: l.Addr().Network(), Addr: l.Addr(), Err: context.Cause(ctx)}
+		}
+	}
+	dl.SetDeadline(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(ctx, c), nil
+}
@@ -0,0 +1,129 @@
+package dump
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Diff returns a line diff of the dumps of want and got, or "" if they
+// render the same. It is meant for test failures:
+//
+//	if d := dump.Diff(want, got); d != "" {
+//		t.Errorf("order mismatch (-want +got):\n%s", d)
+//	}
+//
+// Values that render the same are not necessarily ==: pointers are
+// compared by what they point to, and NaN equals NaN.
+func Diff(want, got any) string { return Config{}.Diff(want, got) }
+
+// Diff is the package Diff with c's rendering. Color is ignored.
+func (c Config) Diff(want, got any) string {
+	c.Color = false
+	a := strings.Split(c.Sdump(want), "\n")
+	b := strings.Split(c.Sdump(got), "\n")
+	return diffLines(a, b, 2)
+}
+
+// maxCells bounds the LCS table. A larger changed region is reported as a
+// whole block removed and a whole block added.
+const maxCells = 4 << 20
+
+type op byte
+
+const (
+	same op = ' '
+	del  op = '-'
+	add  op = '+'
+)
+
+// diffLines aligns a and b on their longest common subsequence and prints
+// the changes with context lines around each.
+func diffLines(a, b []string, context int) string {
+	// Common prefix and suffix cost nothing and usually cover most of a dump.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	if pre == len(a) && pre == len(b) {
+		return ""
+	}
+
+	type line struct {
+		op   op
+		text string
+		n    int // line number in a for same and del, in b for add
+	}
+	var lines []line
+	for i := range pre {
+		lines = append(lines, line{same, a[i], i})
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if len(ma)*len(mb) > maxCells {
+		for i, s := range ma {
+			lines = append(lines, line{del, s, pre + i})
+		}
+		for j, s := range mb {
+			lines = append(lines, line{add, s, pre + j})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of ma[i:] and mb[j:].
+		w := len(mb) + 1
+		lcs := make([]int32, (len(ma)+1)*w)
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+				} else {
+					lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				lines = append(lines, line{same, ma[i], pre + i})
+				i++
+				j++
+			case j == len(mb) || i < len(ma) && lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+				lines = append(lines, line{del, ma[i], pre + i})
+				i++
+			default:
+				lines = append(lines, line{add, mb[j], pre + j})
+				j++
+			}
+		}
+	}
+	for i := len(a) - suf; i < len(a); i++ {
+		lines = append(lines, line{same, a[i], i})
+	}
+
+	// Keep changed lines and up to context unchanged lines around them.
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.op == same {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+	var sb strings.Builder
+	for i, l := range lines {
+		if !keep[i] {
+			continue
+		}
+		if i > 0 && !keep[i-1] {
+			sb.WriteString("@@ line " + strconv.Itoa(l.n+1) + " @@\n")
+		}
+		sb.WriteByte(byte(l.op))
+		sb.WriteByte(' ')
+		sb.WriteString(l.text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,503 @@
+/*
+Package dump renders any Go value as an indented, Go-like literal.
+
+interface/empty.go describes a value with fmt's (%v, %T), and
+interface/stringer.go lets a type choose its own text with String. Neither
+helps with a large nested struct: %v loses field names, %+v puts everything
+on one line, and %#v prints pointers as bare addresses. Dump walks the value
+with reflect instead and prints one field or element per line:
+
+	&main.Order{
+	  ID: 7,
+	  Customer: &main.Customer{
+	    Name: "ada",
+	    tags: []string{"vip", "eu"},
+	  },
+	  Lines: map[string]int{
+	    "apple": 2,
+	    "pear": 1,
+	  },
+	}
+
+Types are printed where Go's composite literal syntax needs them: at the top,
+for struct fields, and wherever a value is reached through an interface.
+Elements of slices, arrays and maps leave them out, as gofmt -s would.
+Unexported fields are included. Map keys are sorted, so two dumps of equal
+values are equal text and Diff can compare them line by line. The exception
+is keys that are pointers or channels, or contain them: those sort by
+address, so equal maps built separately may list them in a different
+order. Pointers already being printed further up are shown as <cycle>,
+which makes self-referencing structures safe to dump.
+*/
+package dump
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Config controls rendering. The zero value prints everything, without
+// addresses or color, indented by two spaces.
+type Config struct {
+	MaxDepth     int    // levels of nesting to expand, 0 for no limit
+	Addresses    bool   // print pointer, map and chan addresses
+	Color        bool   // ANSI colors for a terminal
+	ExportedOnly bool   // skip unexported struct fields
+	Stringers    bool   // print String() or Error() instead of the structure when available
+	Indent       string // default two spaces
+}
+
+// Sdump renders v with the default Config.
+func Sdump(v any) string { return Config{}.Sdump(v) }
+
+// Fdump writes v to w with the default Config.
+func Fdump(w io.Writer, v any) error { return Config{}.Fdump(w, v) }
+
+// Dump writes each value to stdout with the default Config.
+func Dump(vs ...any) {
+	for _, v := range vs {
+		Config{}.Fdump(os.Stdout, v)
+	}
+}
+
+// Sdump renders v.
+func (c Config) Sdump(v any) string {
+	if c.Indent == "" {
+		c.Indent = "  "
+	}
+	p := &printer{c: c, visiting: make(map[visit]bool)}
+	p.value(reflect.ValueOf(v), true, 0)
+	return p.String()
+}
+
+// Fdump writes v to w followed by a newline.
+func (c Config) Fdump(w io.Writer, v any) error {
+	_, err := io.WriteString(w, c.Sdump(v)+"\n")
+	return err
+}
+
+// visit identifies a reference on the current path. The type is part of
+// the key because a struct and its first field share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type printer struct {
+	strings.Builder
+	c        Config
+	visiting map[visit]bool
+}
+
+const (
+	colorType   = "\x1b[36m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[33m"
+	colorNil    = "\x1b[35m"
+	colorAddr   = "\x1b[2m"
+	colorReset  = "\x1b[0m"
+)
+
+func (p *printer) colored(color, s string) {
+	if p.c.Color {
+		p.WriteString(color)
+		p.WriteString(s)
+		p.WriteString(colorReset)
+		return
+	}
+	p.WriteString(s)
+}
+
+func (p *printer) newline(depth int) {
+	p.WriteByte('\n')
+	for range depth {
+		p.WriteString(p.c.Indent)
+	}
+}
+
+func (p *printer) typeName(t reflect.Type) { p.colored(colorType, t.String()) }
+
+func (p *printer) addr(ptr uintptr) {
+	if p.c.Addresses {
+		p.colored(colorAddr, "(0x"+strconv.FormatUint(uint64(ptr), 16)+")")
+	}
+}
+
+// value prints v at the current position. typed means the reader cannot
+// infer v's type from the surrounding output, so it is printed.
+func (p *printer) value(v reflect.Value, typed bool, depth int) {
+	if !v.IsValid() {
+		p.colored(colorNil, "nil")
+		return
+	}
+	if p.c.Stringers && p.stringer(v, typed) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			p.colored(colorNil, "nil")
+			return
+		}
+		p.value(v.Elem(), true, depth)
+	case reflect.Pointer:
+		if v.IsNil() {
+			p.nilOf(v.Type(), typed)
+			return
+		}
+		key := visit{v.Pointer(), v.Type(), 0}
+		if p.visiting[key] {
+			p.colored(colorNil, "<cycle "+v.Type().String()+">")
+			return
+		}
+		p.visiting[key] = true
+		defer delete(p.visiting, key)
+		p.addr(v.Pointer())
+		p.WriteByte('&')
+		if isScalar(v.Elem().Kind()) {
+			// &int(5): a bare &5 is not a Go expression.
+			p.typeName(v.Elem().Type())
+			p.WriteByte('(')
+			p.scalar(v.Elem(), false)
+			p.WriteByte(')')
+			return
+		}
+		p.value(v.Elem(), typed, depth)
+	case reflect.Struct:
+		p.structValue(v, typed, depth)
+	case reflect.Map:
+		p.mapValue(v, typed, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			p.nilOf(v.Type(), typed)
+			return
+		}
+		key := visit{v.Pointer(), v.Type(), v.Len()}
+		if p.visiting[key] {
+			p.colored(colorNil, "<cycle "+v.Type().String()+">")
+			return
+		}
+		p.visiting[key] = true
+		defer delete(p.visiting, key)
+		p.listValue(v, typed, depth)
+	case reflect.Array:
+		p.listValue(v, typed, depth)
+	case reflect.Chan:
+		if v.IsNil() {
+			p.nilOf(v.Type(), typed)
+			return
+		}
+		p.addr(v.Pointer())
+		p.typeName(v.Type())
+		p.WriteString("(len " + strconv.Itoa(v.Len()) + ", cap " + strconv.Itoa(v.Cap()) + ")")
+	case reflect.Func:
+		if v.IsNil() {
+			p.nilOf(v.Type(), typed)
+			return
+		}
+		p.addr(v.Pointer())
+		p.typeName(v.Type())
+	case reflect.UnsafePointer:
+		p.typeName(v.Type())
+		p.WriteByte('(')
+		if p.c.Addresses {
+			p.colored(colorNumber, "0x"+strconv.FormatUint(uint64(v.Pointer()), 16))
+		} else {
+			p.WriteString("...")
+		}
+		p.WriteByte(')')
+	default:
+		p.scalar(v, typed)
+	}
+}
+
+// stringer prints v through its String or Error method if it has one that
+// can be called. Values read from unexported fields cannot be.
+func (p *printer) stringer(v reflect.Value, typed bool) bool {
+	if !v.CanInterface() || v.Kind() == reflect.Interface {
+		return false
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return false
+	}
+	iv := v.Interface()
+	if v.CanAddr() {
+		if _, ok := iv.(fmt.Stringer); !ok {
+			iv = v.Addr().Interface()
+		}
+	}
+	var s string
+	switch x := iv.(type) {
+	case error:
+		s = x.Error()
+	case fmt.Stringer:
+		s = x.String()
+	default:
+		return false
+	}
+	if typed {
+		p.typeName(v.Type())
+		p.WriteByte('(')
+	}
+	p.colored(colorString, strconv.Quote(s))
+	if typed {
+		p.WriteByte(')')
+	}
+	return true
+}
+
+func (p *printer) nilOf(t reflect.Type, typed bool) {
+	if !typed {
+		p.colored(colorNil, "nil")
+		return
+	}
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Func || t.Kind() == reflect.Chan {
+		p.WriteByte('(')
+		p.typeName(t)
+		p.WriteByte(')')
+	} else {
+		p.typeName(t)
+	}
+	p.WriteByte('(')
+	p.colored(colorNil, "nil")
+	p.WriteByte(')')
+}
+
+// truncated prints the placeholder for a non-empty composite below MaxDepth.
+func (p *printer) truncated(depth int) bool {
+	if p.c.MaxDepth <= 0 || depth < p.c.MaxDepth {
+		return false
+	}
+	p.colored(colorNil, "{...}")
+	return true
+}
+
+func (p *printer) structValue(v reflect.Value, typed bool, depth int) {
+	t := v.Type()
+	if typed {
+		p.typeName(t)
+	}
+	fields := make([]int, 0, t.NumField())
+	for i := range t.NumField() {
+		if p.c.ExportedOnly && !t.Field(i).IsExported() {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	if len(fields) == 0 {
+		p.WriteString("{}")
+		return
+	}
+	if p.truncated(depth) {
+		return
+	}
+	p.WriteByte('{')
+	for _, i := range fields {
+		p.newline(depth + 1)
+		p.WriteString(t.Field(i).Name)
+		p.WriteString(": ")
+		// A field's type is not elided in Go's composite literals, except
+		// that scalars and nils read better bare.
+		f := v.Field(i)
+		p.value(f, !isScalar(f.Kind()) && !isNil(f), depth+1)
+		p.WriteByte(',')
+	}
+	p.newline(depth)
+	p.WriteByte('}')
+}
+
+func (p *printer) mapValue(v reflect.Value, typed bool, depth int) {
+	if v.IsNil() {
+		p.nilOf(v.Type(), typed)
+		return
+	}
+	key := visit{v.Pointer(), v.Type(), 0}
+	if p.visiting[key] {
+		p.colored(colorNil, "<cycle "+v.Type().String()+">")
+		return
+	}
+	p.visiting[key] = true
+	defer delete(p.visiting, key)
+
+	p.addr(v.Pointer())
+	if typed {
+		p.typeName(v.Type())
+	}
+	if v.Len() == 0 {
+		p.WriteString("{}")
+		return
+	}
+	if p.truncated(depth) {
+		return
+	}
+	entries := make([]entry, 0, v.Len())
+	for it := v.MapRange(); it.Next(); {
+		entries = append(entries, entry{it.Key(), it.Value()})
+	}
+	sortEntries(entries)
+	p.WriteByte('{')
+	for _, e := range entries {
+		p.newline(depth + 1)
+		p.value(e.key, false, depth+1)
+		p.WriteString(": ")
+		p.value(e.value, false, depth+1)
+		p.WriteByte(',')
+	}
+	p.newline(depth)
+	p.WriteByte('}')
+}
+
+// maxInline is the width up to which a list of scalars stays on one line.
+const maxInline = 72
+
+func (p *printer) listValue(v reflect.Value, typed bool, depth int) {
+	t := v.Type()
+	if t.Elem().Kind() == reflect.Uint8 {
+		p.bytes(v)
+		return
+	}
+	if typed {
+		p.typeName(t)
+	}
+	if v.Len() == 0 {
+		p.WriteString("{}")
+		return
+	}
+	if p.truncated(depth) {
+		return
+	}
+	if isScalar(t.Elem().Kind()) && !p.c.Stringers {
+		sub := &printer{c: p.c}
+		sub.c.Color = false
+		for i := range v.Len() {
+			if i > 0 {
+				sub.WriteString(", ")
+			}
+			sub.scalar(v.Index(i), false)
+			if sub.Len() > maxInline {
+				break
+			}
+		}
+		if sub.Len() <= maxInline {
+			p.WriteByte('{')
+			for i := range v.Len() {
+				if i > 0 {
+					p.WriteString(", ")
+				}
+				p.scalar(v.Index(i), false)
+			}
+			p.WriteByte('}')
+			return
+		}
+	}
+	p.WriteByte('{')
+	for i := range v.Len() {
+		p.newline(depth + 1)
+		p.value(v.Index(i), false, depth+1)
+		p.WriteByte(',')
+	}
+	p.newline(depth)
+	p.WriteByte('}')
+}
+
+// bytes prints byte slices and arrays as a string when they are printable
+// UTF-8, and as hex otherwise. The type is always shown so the string form
+// is not mistaken for a string.
+func (p *printer) bytes(v reflect.Value) {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	p.typeName(v.Type())
+	if printable(b) {
+		p.WriteByte('(')
+		p.colored(colorString, strconv.Quote(string(b)))
+		p.WriteByte(')')
+		return
+	}
+	p.WriteByte('{')
+	for i, c := range b {
+		if i > 0 {
+			p.WriteString(", ")
+		}
+		p.colored(colorNumber, fmt.Sprintf("0x%02x", c))
+	}
+	p.WriteByte('}')
+}
+
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !strconv.IsPrint(r) && r != '\n' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// scalar prints a basic value, as T(v) when typed unless T is the default
+// type of the literal: int, float64, string, bool.
+func (p *printer) scalar(v reflect.Value, typed bool) {
+	var s, color string
+	switch v.Kind() {
+	case reflect.Bool:
+		s, color = strconv.FormatBool(v.Bool()), colorNumber
+	case reflect.String:
+		s, color = strconv.Quote(v.String()), colorString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, color = strconv.FormatInt(v.Int(), 10), colorNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, color = strconv.FormatUint(v.Uint(), 10), colorNumber
+	case reflect.Uintptr:
+		s, color = "0x"+strconv.FormatUint(v.Uint(), 16), colorNumber
+	case reflect.Float32, reflect.Float64:
+		s, color = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), colorNumber
+	case reflect.Complex64, reflect.Complex128:
+		s, color = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), colorNumber
+	default:
+		s, color = v.Type().String(), colorType
+	}
+	t := v.Type()
+	if typed && t.PkgPath() == "" {
+		switch t.Kind() {
+		case reflect.Int, reflect.Float64, reflect.String, reflect.Bool:
+			typed = false
+		}
+	}
+	if typed {
+		p.typeName(t)
+		p.WriteByte('(')
+	}
+	p.colored(color, s)
+	if typed {
+		p.WriteByte(')')
+	}
+}
@@ -0,0 +1,18 @@
+package dump_test
+
+import (
+	"math"
+	"testing"
+
+	"golang/dump"
+)
+
+func TestMapNaNKeys(t *testing.T) {
+	m := map[float64]string{math.NaN(): "b", 1: "one", math.NaN(): "a"}
+	want := "map[float64]string{\n  NaN: \"a\",\n  NaN: \"b\",\n  1: \"one\",\n}"
+	for range 10 {
+		if got := dump.Sdump(m); got != want {
+			t.Fatalf("got\n%s\nwant\n%s", got, want)
+		}
+	}
+}
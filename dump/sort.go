@@ -0,0 +1,86 @@
+package dump
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// entry is one key/value pair of a map, read with MapRange: a NaN key
+// cannot be looked up again with MapIndex.
+type entry struct{ key, value reflect.Value }
+
+// sortEntries orders map entries by key much like fmt does: numbers by
+// value, strings lexically, false before true, pointers and channels by
+// address, arrays and structs field by field. NaNs sort before every other
+// float, and entries whose keys compare equal, such as several NaNs, by
+// value. Interface keys sort by type name first; fmt uses the type's address
+// there, which can change from one build to the next.
+func sortEntries(entries []entry) {
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if c := compare(a.key, b.key); c != 0 {
+			return c
+		}
+		return compare(a.value, b.value)
+	})
+}
+
+func compare(a, b reflect.Value) int {
+	if a.Kind() != b.Kind() {
+		return cmp.Compare(a.Kind(), b.Kind())
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		ac, bc := a.Complex(), b.Complex()
+		if c := cmp.Compare(real(ac), real(bc)); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(ac), imag(bc))
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case a.Bool():
+			return 1
+		}
+		return -1
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if c := compare(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array:
+		for i := range a.Len() {
+			if c := compare(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		if c := cmp.Compare(a.Elem().Type().String(), b.Elem().Type().String()); c != 0 {
+			return c
+		}
+		return compare(a.Elem(), b.Elem())
+	}
+	return 0
+}
@@ -0,0 +1,334 @@
+/*
+Package errs is a structured error type for services.
+
+interface/error.go shows the smallest useful custom error: a struct with a
+time and a message that implements the error interface. A service needs a bit
+more from its errors:
+
+  - a Kind that says what went wrong in terms a caller can act on (not found,
+    invalid argument, unavailable) and maps to an HTTP status,
+  - an optional machine readable Code, for clients that branch on it,
+  - key-value fields for logs, in the style of log/slog,
+  - the cause, reachable through errors.Is, errors.As and errors.Join,
+  - optionally, the stack where the error was created.
+
+Kinds are errors themselves, so errors.Is(err, errs.NotFound) asks whether any
+error in the chain has that kind.
+
+concept/Interface/nill_interface.md explains why a nil *MyError stored in an
+error is not nil. Wrap returns error rather than *Error for that reason: it
+returns a true nil when there is nothing to wrap. The *Error methods also
+tolerate a nil receiver, so a typed nil that slips through prints "<nil>"
+instead of panicking.
+*/
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Kind classifies an error. The zero Kind is Unknown; a wrapper with an
+// Unknown kind inherits the kind of its cause.
+type Kind uint8
+
+const (
+	Unknown Kind = iota
+	Internal
+	Invalid  // the request is malformed, whatever the state of the system
+	NotFound // the thing the request names does not exist
+	Exists   // the thing the request would create already exists
+	Conflict // the request is valid but the current state rejects it
+	Unauthenticated
+	PermissionDenied
+	TooManyRequests
+	Unavailable // transient, worth retrying
+	Timeout
+	Canceled
+	Unimplemented
+)
+
+var kindNames = [...]string{
+	Unknown:          "unknown",
+	Internal:         "internal",
+	Invalid:          "invalid",
+	NotFound:         "not_found",
+	Exists:           "exists",
+	Conflict:         "conflict",
+	Unauthenticated:  "unauthenticated",
+	PermissionDenied: "permission_denied",
+	TooManyRequests:  "too_many_requests",
+	Unavailable:      "unavailable",
+	Timeout:          "timeout",
+	Canceled:         "canceled",
+	Unimplemented:    "unimplemented",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Error makes a Kind usable as an errors.Is target.
+func (k Kind) Error() string { return strings.ReplaceAll(k.String(), "_", " ") }
+
+// Error is a structured error. Create it with New, Newf or Wrap.
+type Error struct {
+	Kind   Kind
+	Code   string // optional, stable identifier for clients, e.g. "user_not_found"
+	Msg    string
+	Fields []slog.Attr
+	Err    error // the cause, may be nil
+
+	stack []uintptr
+}
+
+// New returns an error of the given kind. kv are slog-style key-value
+// pairs or slog.Attr values.
+func New(kind Kind, msg string, kv ...any) *Error {
+	e := &Error{Kind: kind, Msg: msg, Fields: attrs(kv)}
+	e.stack = callers()
+	return e
+}
+
+// Newf is New with a formatted message. It does not wrap: %w is treated
+// like %v. Use Wrap to keep a cause.
+func Newf(kind Kind, format string, args ...any) *Error {
+	e := &Error{Kind: kind, Msg: fmt.Sprintf(strings.ReplaceAll(format, "%w", "%v"), args...)}
+	e.stack = callers()
+	return e
+}
+
+// Wrap adds a kind, a message and fields to err. It returns nil if err is
+// nil, including a nil *Error stored in an error. The result is an error,
+// not an *Error, so that
+//
+//	return errs.Wrap(err, errs.Internal, "save user")
+//
+// is safe in a function returning error. Pass Unknown to keep err's kind.
+// No stack is captured if err already carries one.
+func Wrap(err error, kind Kind, msg string, kv ...any) error {
+	if IsNil(err) {
+		return nil
+	}
+	e := &Error{Kind: kind, Msg: msg, Fields: attrs(kv), Err: err}
+	if Stack(err) == nil {
+		e.stack = callers()
+	}
+	return e
+}
+
+// With returns err with fields added, or nil if err is nil.
+func With(err error, kv ...any) error {
+	if IsNil(err) {
+		return nil
+	}
+	return &Error{Fields: attrs(kv), Err: err}
+}
+
+// WithCode returns a copy of e with Code set.
+func (e *Error) WithCode(code string) *Error {
+	c := *e
+	c.Code = code
+	return &c
+}
+
+// With returns a copy of e with fields added.
+func (e *Error) With(kv ...any) *Error {
+	c := *e
+	c.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], attrs(kv)...)
+	return &c
+}
+
+// Error returns "msg: cause". Fields, code and stack are left to the
+// renderers.
+func (e *Error) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	msg := e.Msg
+	if msg == "" && e.Err == nil {
+		msg = e.Kind.Error()
+	}
+	switch {
+	case e.Err == nil:
+		return msg
+	case msg == "":
+		return e.Err.Error()
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// Is matches a Kind against e's effective kind, and an *Error template on
+// its non-zero Kind and Code. errors.Is first tries ==, so sentinel *Error
+// values compare by identity as usual.
+func (e *Error) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	switch t := target.(type) {
+	case Kind:
+		return t != Unknown && KindOf(e) == t
+	case *Error:
+		if t == nil || (t.Kind == Unknown && t.Code == "") {
+			return false
+		}
+		return (t.Kind == Unknown || t.Kind == KindOf(e)) && (t.Code == "" || t.Code == e.Code)
+	}
+	return false
+}
+
+// KindOf returns the first known kind found walking err's tree depth first,
+// including errors.Join branches. Standard library errors that have an
+// obvious kind are recognised; anything else non-nil is Internal.
+func KindOf(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+	k := Unknown
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case *Error:
+			if e != nil {
+				k = e.Kind
+			}
+		case Kind:
+			k = e
+		default:
+			k = stdKind(err)
+		}
+		return k != Unknown
+	})
+	if k == Unknown {
+		return Internal
+	}
+	return k
+}
+
+func stdKind(err error) Kind {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, fs.ErrNotExist):
+		return NotFound
+	case errors.Is(err, fs.ErrExist):
+		return Exists
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied
+	}
+	var t interface{ Timeout() bool }
+	if errors.As(err, &t) && t.Timeout() {
+		return Timeout
+	}
+	return Unknown
+}
+
+// CodeOf returns the first non-empty Code in err's tree.
+func CodeOf(err error) string {
+	code := ""
+	walk(err, func(err error) bool {
+		if e, ok := err.(*Error); ok && e != nil {
+			code = e.Code
+		}
+		return code != ""
+	})
+	return code
+}
+
+// Fields collects the fields of every *Error in err's tree, outermost
+// first. When a key repeats, the outermost value wins.
+func Fields(err error) []slog.Attr {
+	var out []slog.Attr
+	seen := make(map[string]bool)
+	walk(err, func(err error) bool {
+		if e, ok := err.(*Error); ok && e != nil {
+			for _, a := range e.Fields {
+				if !seen[a.Key] {
+					seen[a.Key] = true
+					out = append(out, a)
+				}
+			}
+		}
+		return false
+	})
+	return out
+}
+
+// walk visits err and its causes depth first, stopping when visit returns
+// true.
+func walk(err error, visit func(error) bool) bool {
+	for err != nil {
+		if visit(err) {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if walk(e, visit) {
+					return true
+				}
+			}
+			return false
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// IsNil reports whether err is nil or an interface holding a nil *Error:
+// the case err == nil misses.
+func IsNil(err error) bool {
+	if err == nil {
+		return true
+	}
+	e, ok := err.(*Error)
+	return ok && e == nil
+}
+
+// attrs converts slog-style arguments the way slog.Logger.Log does: a
+// string key followed by a value, or a slog.Attr. A trailing key without a
+// value and non-string keys are kept under "!BADKEY".
+func attrs(kv []any) []slog.Attr {
+	if len(kv) == 0 {
+		return nil
+	}
+	out := make([]slog.Attr, 0, len(kv)/2)
+	for len(kv) > 0 {
+		switch k := kv[0].(type) {
+		case slog.Attr:
+			out = append(out, k)
+			kv = kv[1:]
+		case string:
+			if len(kv) == 1 {
+				out = append(out, slog.String("!BADKEY", k))
+				kv = nil
+				continue
+			}
+			out = append(out, slog.Any(k, kv[1]))
+			kv = kv[2:]
+		default:
+			out = append(out, slog.Any("!BADKEY", k))
+			kv = kv[1:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package errs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"golang/errs"
+)
+
+// lookup is the trap of concept/Interface/nill_interface.md: it declares the
+// concrete type and returns it through an error result.
+func lookup(fail bool) error {
+	var e *errs.Error
+	if fail {
+		e = errs.New(errs.NotFound, "missing")
+	}
+	return e
+}
+
+func TestTypedNil(t *testing.T) {
+	err := lookup(false)
+	if err == nil {
+		t.Fatal("typed nil compared equal to nil; the trap this test is about is gone")
+	}
+	if !errs.IsNil(err) || errs.IsNil(lookup(true)) {
+		t.Error("IsNil does not see through a typed nil")
+	}
+	if got := errs.Wrap(nil, errs.Internal, "x"); got != nil {
+		t.Errorf("Wrap(nil) = %v", got)
+	}
+	if got := errs.Wrap(err, errs.Internal, "x"); got != nil {
+		t.Errorf("Wrap(typed nil) = %v", got)
+	}
+	if got := errs.With(nil, "k", 1); got != nil {
+		t.Errorf("With(nil) = %v", got)
+	}
+	if got := errs.HTTPStatus(err); got != http.StatusOK {
+		t.Errorf("HTTPStatus(typed nil) = %d, want 200", got)
+	}
+}
+
+func TestTypedNilDoesNotPanic(t *testing.T) {
+	err := lookup(false)
+	if got := err.Error(); got != "<nil>" {
+		t.Errorf("Error() = %q, want <nil>", got)
+	}
+	_ = fmt.Sprintf("%v %+v %q", err, err, err)
+	if b, jerr := json.Marshal(err); jerr != nil || string(b) != "null" {
+		t.Errorf("json.Marshal = %s, %v; want null", b, jerr)
+	}
+	slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)).Error("x", "err", err)
+	if errors.Is(err, errs.NotFound) {
+		t.Error("typed nil matched NotFound")
+	}
+	if errs.KindOf(err) != errs.Internal {
+		t.Errorf("KindOf(typed nil) = %v", errs.KindOf(err))
+	}
+}
@@ -0,0 +1,176 @@
+package errs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+var kindStatus = [...]int{
+	Unknown:          http.StatusInternalServerError,
+	Internal:         http.StatusInternalServerError,
+	Invalid:          http.StatusBadRequest,
+	NotFound:         http.StatusNotFound,
+	Exists:           http.StatusConflict,
+	Conflict:         http.StatusConflict,
+	Unauthenticated:  http.StatusUnauthorized,
+	PermissionDenied: http.StatusForbidden,
+	TooManyRequests:  http.StatusTooManyRequests,
+	Unavailable:      http.StatusServiceUnavailable,
+	Timeout:          http.StatusGatewayTimeout,
+	Canceled:         499, // nginx's "client closed request"; nobody is listening
+	Unimplemented:    http.StatusNotImplemented,
+}
+
+// HTTPStatus returns the status code for k.
+func (k Kind) HTTPStatus() int {
+	if int(k) < len(kindStatus) {
+		return kindStatus[k]
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPStatus returns the status code for err's kind, 200 for nil.
+func HTTPStatus(err error) int {
+	if IsNil(err) {
+		return http.StatusOK
+	}
+	return KindOf(err).HTTPStatus()
+}
+
+// jsonError is the JSON form of an *Error. Causes that are not *Error are
+// rendered as their message.
+type jsonError struct {
+	Kind    string         `json:"kind,omitempty"`
+	Code    string         `json:"code,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Cause   any            `json:"cause,omitempty"`
+	Stack   []string       `json:"stack,omitempty"`
+}
+
+// MarshalJSON renders e with its whole cause chain, including the branches
+// of errors.Join, and its stack if one was captured.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	j := jsonError{Code: e.Code, Message: e.Msg, Cause: jsonCause(e.Err)}
+	if e.Kind != Unknown {
+		j.Kind = e.Kind.String()
+	}
+	if len(e.Fields) > 0 {
+		j.Fields = make(map[string]any, len(e.Fields))
+		for _, a := range e.Fields {
+			j.Fields[a.Key] = a.Value.Resolve().Any()
+		}
+	}
+	for _, f := range e.StackFrames() {
+		j.Stack = append(j.Stack, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+	}
+	return json.Marshal(j)
+}
+
+func jsonCause(err error) any {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		return e
+	case interface{ Unwrap() []error }:
+		var out []any
+		for _, err := range e.Unwrap() {
+			out = append(out, jsonCause(err))
+		}
+		return out
+	}
+	return err.Error()
+}
+
+// LogValue makes an *Error render as a group when logged with slog:
+//
+//	logger.Error("request failed", "err", err)
+//
+// gives err.msg, err.kind, err.code, the fields and, if captured, the
+// innermost stack frame as err.at.
+func (e *Error) LogValue() slog.Value {
+	if e == nil {
+		return slog.StringValue("<nil>")
+	}
+	attrs := []slog.Attr{
+		slog.String("msg", e.Error()),
+		slog.String("kind", KindOf(e).String()),
+	}
+	if code := CodeOf(e); code != "" {
+		attrs = append(attrs, slog.String("code", code))
+	}
+	attrs = append(attrs, Fields(e)...)
+	if st := Stack(e); len(st) > 0 {
+		attrs = append(attrs, slog.String("at", fmt.Sprintf("%s:%d", st[0].File, st[0].Line)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// WriteHTTP writes err as a JSON response with the matching status. Only
+// the kind, the code and the message of the *Error that gave err its kind
+// reach the client; causes, fields and the messages of wrappers can carry
+// implementation details. Internal errors, which include anything
+// unclassified, get the status text as their message. If logger is not
+// nil, err is logged to it in full, at Error level for a 5xx status and
+// Info otherwise.
+func WriteHTTP(w http.ResponseWriter, err error, logger *slog.Logger) {
+	status := HTTPStatus(err)
+	kind := KindOf(err)
+	body := struct {
+		Kind    string `json:"kind"`
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message"`
+	}{Kind: kind.String(), Code: CodeOf(err), Message: http.StatusText(status)}
+	if !IsNil(err) {
+		if logger != nil {
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			}
+			logger.Log(context.Background(), level, "errs: http response", "status", status, "err", err)
+		}
+		if kind != Internal {
+			body.Message = kindMsg(err, kind)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// kindMsg returns the Msg of the *Error that KindOf takes kind from, or
+// the text of kind when that is a Kind, a standard library error or an
+// *Error without a message.
+func kindMsg(err error, kind Kind) string {
+	msg := ""
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case *Error:
+			if e == nil || e.Kind == Unknown {
+				return false
+			}
+			msg = e.Msg
+		case Kind:
+			if e == Unknown {
+				return false
+			}
+		default:
+			if stdKind(err) == Unknown {
+				return false
+			}
+		}
+		return true
+	})
+	if msg == "" {
+		return kind.Error()
+	}
+	return msg
+}
@@ -0,0 +1,64 @@
+package errs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang/errs"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	notFound := errs.New(errs.NotFound, "user not found").WithCode("user_not_found")
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+		body   string
+		level  string
+	}{
+		{"nil", nil, 200, `{"kind":"unknown","message":"OK"}`, ""},
+		{"kind's message, not the wrappers'",
+			errs.Wrap(notFound.With("user", 42), errs.Unknown, "rename"), 404,
+			`{"kind":"not_found","code":"user_not_found","message":"user not found"}`, "INFO"},
+		{"inner kind overridden",
+			errs.Wrap(notFound, errs.Conflict, "rename"), 409,
+			`{"kind":"conflict","code":"user_not_found","message":"rename"}`, "INFO"},
+		{"kind from a Kind",
+			fmt.Errorf("dial db-7: %w", errs.Timeout), 504,
+			`{"kind":"timeout","message":"timeout"}`, "ERROR"},
+		{"kind from a standard error",
+			errs.Wrap(fmt.Errorf("open /srv/data: %w", fs.ErrNotExist), errs.Unknown, "load"), 404,
+			`{"kind":"not_found","message":"not found"}`, "INFO"},
+		{"internal details hidden",
+			errs.Wrap(errors.New("pq: password authentication failed"), errs.Internal, "load"), 500,
+			`{"kind":"internal","message":"Internal Server Error"}`, "ERROR"},
+	} {
+		var log bytes.Buffer
+		rec := httptest.NewRecorder()
+		errs.WriteHTTP(rec, tc.err, slog.New(slog.NewTextHandler(&log, nil)))
+		if rec.Code != tc.status || strings.TrimSpace(rec.Body.String()) != tc.body {
+			t.Errorf("%s: %d %s, want %d %s", tc.name, rec.Code, rec.Body, tc.status, tc.body)
+		}
+		if tc.level == "" {
+			if log.Len() > 0 {
+				t.Errorf("%s: logged %s", tc.name, log.String())
+			}
+			continue
+		}
+		if !strings.Contains(log.String(), "level="+tc.level) || !strings.Contains(log.String(), tc.err.Error()) {
+			t.Errorf("%s: log %q, want level %s and the full error", tc.name, log.String(), tc.level)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	errs.WriteHTTP(rec, errs.New(errs.Invalid, "empty id"), nil)
+	if rec.Code != 400 {
+		t.Errorf("nil logger: status %d", rec.Code)
+	}
+}
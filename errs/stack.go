@@ -0,0 +1,84 @@
+package errs
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+var captureStacks atomic.Bool
+
+// CaptureStacks turns stack capture on or off for errors created from now
+// on. It is off by default: runtime.Callers costs about a microsecond, which
+// matters for errors used as ordinary control flow.
+func CaptureStacks(on bool) { captureStacks.Store(on) }
+
+func callers() []uintptr {
+	if !captureStacks.Load() {
+		return nil
+	}
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip Callers, callers and New/Wrap
+	return pcs[:n:n]
+}
+
+// StackFrames returns the frames captured when e was created.
+func (e *Error) StackFrames() []runtime.Frame {
+	if e == nil || len(e.stack) == 0 {
+		return nil
+	}
+	var out []runtime.Frame
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		f, more := frames.Next()
+		out = append(out, f)
+		if !more {
+			return out
+		}
+	}
+}
+
+// Stack returns the innermost stack captured in err's tree, which is the
+// one closest to where the failure happened.
+func Stack(err error) []runtime.Frame {
+	var deepest *Error
+	walk(err, func(err error) bool {
+		if e, ok := err.(*Error); ok && e != nil && len(e.stack) > 0 {
+			deepest = e
+		}
+		return false
+	})
+	return deepest.StackFrames()
+}
+
+// Format supports %s, %v and %q like Error, and %+v, which adds the kind,
+// code, fields and the stack:
+//
+//	load config: open app.yaml: no such file or directory
+//	  kind=not_found path=app.yaml
+//	  main.loadConfig
+//	      /src/app/main.go:42
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		if e == nil {
+			return
+		}
+		fmt.Fprintf(s, "\n  kind=%s", KindOf(e).String())
+		if code := CodeOf(e); code != "" {
+			fmt.Fprintf(s, " code=%s", code)
+		}
+		for _, a := range Fields(e) {
+			fmt.Fprintf(s, " %s", a)
+		}
+		for _, f := range Stack(e) {
+			fmt.Fprintf(s, "\n  %s\n      %s:%d", f.Function, f.File, f.Line)
+		}
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
@@ -0,0 +1,74 @@
+//go:build linux
+
+package fdinspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Snapshot summarises the fds of one process.
+type Snapshot struct {
+	PID     int            `json:"pid"`
+	Total   int            `json:"total"`
+	Limit   uint64         `json:"limit"` // soft RLIMIT_NOFILE, only known for the current process
+	ByKind  map[Kind]int   `json:"by_kind"`
+	Sockets map[string]int `json:"sockets"`
+	FDs     []FD           `json:"fds,omitempty"`
+}
+
+// Take builds a Snapshot of pid, including the fd list when detail is set.
+func Take(pid int, detail bool) (Snapshot, error) {
+	fds, err := List(pid)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	s := Snapshot{
+		PID:     pid,
+		Total:   len(fds),
+		ByKind:  Count(fds),
+		Sockets: SocketStates(fds),
+	}
+	if pid == os.Getpid() {
+		var rl syscall.Rlimit
+		if syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl) == nil {
+			s.Limit = rl.Cur
+		}
+	}
+	if detail {
+		s.FDs = fds
+	}
+	return s, nil
+}
+
+// Handler serves a JSON Snapshot of the current process, for mounting on a
+// debug mux next to net/http/pprof:
+//
+//	mux.Handle("/debug/fds", fdinspect.Handler())
+//
+// Add ?detail=1 (or any value strconv.ParseBool takes as true) to include
+// every fd.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		detail := false
+		if v := r.URL.Query().Get("detail"); v != "" {
+			var err error
+			if detail, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, "fdinspect: detail must be a boolean", http.StatusBadRequest)
+				return
+			}
+		}
+		s, err := Take(os.Getpid(), detail)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(s)
+	})
+}
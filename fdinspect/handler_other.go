@@ -0,0 +1,14 @@
+//go:build !linux
+
+// Package fdinspect lists and classifies the file descriptors of a process.
+// It reads them from /proc, so outside Linux only Handler is available.
+package fdinspect
+
+import "net/http"
+
+// Handler answers 501 Not Implemented: there is no procfs to read.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "fdinspect: only supported on linux", http.StatusNotImplemented)
+	})
+}
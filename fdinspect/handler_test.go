@@ -0,0 +1,44 @@
+//go:build linux
+
+package fdinspect_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang/fdinspect"
+)
+
+func TestHandlerDetail(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		status int
+		fds    bool
+	}{
+		{"", http.StatusOK, false},
+		{"?detail=1", http.StatusOK, true},
+		{"?detail=true", http.StatusOK, true},
+		{"?detail=0", http.StatusOK, false},
+		{"?detail=false", http.StatusOK, false},
+		{"?detail=yes", http.StatusBadRequest, false},
+	} {
+		rec := httptest.NewRecorder()
+		fdinspect.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/fds"+tc.query, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%q: status %d, want %d", tc.query, rec.Code, tc.status)
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		var s fdinspect.Snapshot
+		if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		if s.Total == 0 || (len(s.FDs) > 0) != tc.fds {
+			t.Errorf("%q: total %d, %d fds listed, want listed %v", tc.query, s.Total, len(s.FDs), tc.fds)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+//go:build linux
+
+package fdinspect
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SocketInfo is what procfs knows about a socket inode.
+type SocketInfo struct {
+	Proto  string `json:"proto"` // tcp, tcp6, udp, udp6, unix
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	State  string `json:"state"`
+	Path   string `json:"path,omitempty"` // unix sockets only
+}
+
+// tcpStates are the st column values from include/net/tcp_states.h.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+var unixTypes = map[string]string{"0001": "STREAM", "0002": "DGRAM", "0005": "SEQPACKET"}
+
+// sockets reads the socket tables of the process's network namespace, which
+// is why /proc/<pid>/net is used rather than /proc/net.
+func (in Inspector) sockets(dir string) map[uint64]SocketInfo {
+	out := make(map[uint64]SocketInfo)
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		in.parseInet(filepath.Join(dir, "net", proto), proto, out)
+	}
+	in.parseUnix(filepath.Join(dir, "net", "unix"), out)
+	return out
+}
+
+// parseInet reads /proc/net/{tcp,udp}{,6}:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 12345 ...
+func (in Inspector) parseInet(path, proto string, out map[uint64]SocketInfo) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue // TIME_WAIT entries have no inode and no owner
+		}
+		s := SocketInfo{
+			Proto:  proto,
+			Local:  parseHexAddr(fields[1]),
+			Remote: parseHexAddr(fields[2]),
+			State:  tcpStates[fields[3]],
+		}
+		if strings.HasPrefix(proto, "udp") {
+			// UDP reuses the TCP numbering: 07 is unconnected, 01 connected.
+			s.State = map[string]string{"01": "CONNECTED", "07": "UNCONN"}[fields[3]]
+		}
+		out[inode] = s
+	}
+}
+
+// parseHexAddr decodes "0100007F:1F90". The kernel prints the address as
+// 32-bit words in host byte order; the port is plain hex.
+func parseHexAddr(s string) string {
+	addr, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	raw, err := hex.DecodeString(addr)
+	if err != nil || len(raw)%4 != 0 {
+		return s
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.NativeEndian.Uint32(raw[i:]))
+	}
+	p, _ := strconv.ParseUint(port, 16, 16)
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(p, 10))
+}
+
+// parseUnix reads /proc/net/unix:
+//
+//	Num       RefCount Protocol Flags    Type St Inode Path
+//	0000000000000000: 00000002 00000000 00010000 0001 01 23456 /run/app.sock
+func (in Inspector) parseUnix(path string, out map[uint64]SocketInfo) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Scan()
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil {
+			continue
+		}
+		s := SocketInfo{Proto: "unix", State: unixTypes[fields[4]]}
+		if fields[3] == "00010000" {
+			s.State += " LISTEN"
+		} else if fields[5] == "03" {
+			s.State += " CONNECTED"
+		}
+		if len(fields) > 7 {
+			s.Path = fields[7]
+		}
+		out[inode] = s
+	}
+}
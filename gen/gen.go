@@ -0,0 +1,53 @@
+/*
+Package gen turns sequence producers into generators that a consumer drives.
+
+concurrency/range_and_close.go produces Fibonacci numbers on a goroutine and
+hands them over a channel. That shape has two costs: every value crosses a
+channel (a lock and usually a goroutine switch), and a consumer that stops
+early must tell the producer or the goroutine leaks blocked on its send.
+
+Here every sequence is written once as an iter.Seq and can be consumed three
+ways:
+
+	for v := range seq              push: the sequence calls the loop body
+	Chan(ctx, seq, buf)             goroutine + channel, stopped by ctx
+	Pull(ctx, seq)                  next()/stop() on top of iter.Pull
+
+iter.Pull runs the sequence as a coroutine: next switches directly to it and
+back without going through the scheduler or a channel lock, so it is cheaper
+per value than an unbuffered channel and needs no cancellation to avoid a
+leak, only a call to stop (go test -bench Consume ./gen).
+*/
+package gen
+
+import (
+	"context"
+	"iter"
+
+	"golang/itx"
+)
+
+// Chan runs seq on a new goroutine and sends its values on the returned
+// channel with capacity buf. The channel is closed when seq ends or ctx is
+// done; cancel ctx to stop the goroutine when the receiver quits early. It is
+// itx.ToChan, listed here as the third way to consume a sequence.
+func Chan[T any](ctx context.Context, seq iter.Seq[T], buf int) <-chan T {
+	return itx.ToChan(ctx, seq, buf)
+}
+
+// Pull converts seq into a pull-style generator. next returns the next value
+// and true, or the zero value and false once seq is exhausted, stop has been
+// called or ctx is done. stop must be called when the caller is finished so
+// seq can run its deferred cleanups; it is safe to call more than once.
+func Pull[T any](ctx context.Context, seq iter.Seq[T]) (next func() (T, bool), stop func()) {
+	pnext, pstop := iter.Pull(seq)
+	next = func() (T, bool) {
+		if ctx.Err() != nil {
+			pstop()
+			var zero T
+			return zero, false
+		}
+		return pnext()
+	}
+	return next, pstop
+}
@@ -0,0 +1,97 @@
+package gen_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"testing"
+
+	"golang/gen"
+	"golang/itx"
+)
+
+func TestSequences(t *testing.T) {
+	var fib []string
+	for v := range itx.Take(gen.Fibonacci(), 10) {
+		fib = append(fib, v.String())
+	}
+	if got := fmt.Sprint(fib); got != "[0 1 1 2 3 5 8 13 21 34]" {
+		t.Errorf("fibonacci: %s", got)
+	}
+	if got := slices.Collect(itx.Take(gen.Primes(), 10)); !slices.Equal(got, []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}) {
+		t.Errorf("primes: %v", got)
+	}
+	if got := slices.Collect(gen.Collatz(6)); !slices.Equal(got, []uint64{6, 3, 10, 5, 16, 8, 4, 2, 1}) {
+		t.Errorf("collatz(6): %v", got)
+	}
+}
+
+func TestChanStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := gen.Chan(ctx, gen.Count(0), 0)
+	if v := <-ch; v != 0 {
+		t.Fatalf("first value %d", v)
+	}
+	cancel()
+	for range ch {
+	}
+}
+
+func TestPullStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	next, stop := gen.Pull(ctx, gen.Count(0))
+	defer stop()
+	if v, ok := next(); v != 0 || !ok {
+		t.Fatalf("next() = %d, %v", v, ok)
+	}
+	cancel()
+	if _, ok := next(); ok {
+		t.Fatal("next() after cancel returned a value")
+	}
+}
+
+// BenchmarkConsume measures the per-value cost of consuming a sequence:
+//
+//	range     the loop body is called directly by the sequence (push)
+//	pull      Pull, a coroutine switch per value
+//	chan/0    Chan with an unbuffered channel, as in range_and_close.go
+//	chan/64   Chan with a 64 slot buffer
+//
+// An unbuffered channel pays a goroutine switch through the scheduler per
+// value; a buffered one amortises the switches but still takes the channel
+// lock per value; pull pays only a coroutine switch. Compare with -cpu 1,4.
+func BenchmarkConsume(b *testing.B) {
+	b.Run("range", func(b *testing.B) {
+		b.ReportAllocs()
+		sum := 0
+		for v := range itx.Take(gen.Count(0), b.N) {
+			sum += v
+		}
+		runtime.KeepAlive(sum)
+	})
+	b.Run("pull", func(b *testing.B) {
+		b.ReportAllocs()
+		next, stop := gen.Pull(context.Background(), gen.Count(0))
+		defer stop()
+		sum := 0
+		for range b.N {
+			v, _ := next()
+			sum += v
+		}
+		runtime.KeepAlive(sum)
+	})
+	for _, buf := range []int{0, 64} {
+		b.Run(fmt.Sprint("chan/", buf), func(b *testing.B) {
+			b.ReportAllocs()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ch := gen.Chan(ctx, gen.Count(0), buf)
+			sum := 0
+			for range b.N {
+				sum += <-ch
+			}
+			runtime.KeepAlive(sum)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"iter"
+	"math/big"
+	"math/rand/v2"
+)
+
+// Fibonacci yields 0, 1, 1, 2, 3, 5, ... without overflow. Each value is a new
+// big.Int the caller may keep or modify.
+func Fibonacci() iter.Seq[*big.Int] {
+	return func(yield func(*big.Int) bool) {
+		x, y := big.NewInt(0), big.NewInt(1)
+		for {
+			if !yield(new(big.Int).Set(x)) {
+				return
+			}
+			// x, y = y, x+y without allocating a third number.
+			x.Add(x, y)
+			x, y = y, x
+		}
+	}
+}
+
+// Primes yields 2, 3, 5, 7, ... using an incremental sieve of Eratosthenes.
+//
+// A classic sieve needs an upper bound to size its array. Instead the
+// incremental version keeps, for each prime p found so far, the next odd
+// multiple of p it has not crossed off yet, keyed by that multiple. A
+// candidate absent from the map is prime; a candidate present is composite,
+// and its primes are moved on to their next multiples. Memory grows with the
+// number of primes below the square root of the current candidate.
+func Primes() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		if !yield(2) {
+			return
+		}
+		// Only odd candidates are tested, so multiples advance by 2p.
+		composites := make(map[uint64][]uint64)
+		for n := uint64(3); ; n += 2 {
+			steps, composite := composites[n]
+			if !composite {
+				// The first multiple worth crossing off is n²; smaller ones
+				// have a smaller prime factor that already covers them.
+				composites[n*n] = append(composites[n*n], 2*n)
+				if !yield(n) {
+					return
+				}
+				continue
+			}
+			delete(composites, n)
+			for _, step := range steps {
+				composites[n+step] = append(composites[n+step], step)
+			}
+		}
+	}
+}
+
+// Collatz yields the Collatz sequence from n down to 1: n/2 for even n,
+// 3n+1 for odd n. The sequence is finite for every n tested so far. Collatz(0)
+// yields nothing.
+func Collatz(n uint64) iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		if n == 0 {
+			return
+		}
+		for v := n; ; {
+			if !yield(v) || v == 1 {
+				return
+			}
+			if v%2 == 0 {
+				v /= 2
+			} else {
+				v = 3*v + 1
+			}
+		}
+	}
+}
+
+// RandomWalk yields the positions of a one-dimensional walk that starts at
+// start and moves one step left or right with equal probability. r may be
+// nil to use the global source; pass a seeded *rand.Rand to replay a walk.
+func RandomWalk(r *rand.Rand, start int) iter.Seq[int] {
+	step := rand.IntN
+	if r != nil {
+		step = r.IntN
+	}
+	return func(yield func(int) bool) {
+		pos := start
+		for {
+			if !yield(pos) {
+				return
+			}
+			pos += 2*step(2) - 1
+		}
+	}
+}
+
+// Count yields start, start+1, start+2, ... It is the trivial sequence the
+// benchmarks use to measure per-value overhead.
+func Count(start int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := start; yield(i); i++ {
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Package geo indexes points on the Earth's surface.
+
+map/main.go keeps locations as Vertex{Lat, Long} values in a map[string]Vertex.
+Finding the places near a location that way means measuring the distance to
+every entry. An Index buckets points by geohash cell instead, so bounding box,
+radius and nearest-neighbour queries only look at the cells around the query.
+
+Coordinates are in degrees (WGS84, as from a GPS), distances in metres on a
+sphere of radius EarthRadius. GeoJSON support reads and writes collections of
+Point features so results can be inspected on a map, e.g. geojson.io.
+*/
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean Earth radius in metres.
+const EarthRadius = 6371008.8
+
+// Point is a location in degrees, like Vertex in map/main.go.
+type Point struct {
+	Lat, Long float64
+}
+
+// Valid reports whether p is within [-90, 90] x [-180, 180].
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Long >= -180 && p.Long <= 180
+}
+
+func (p Point) String() string { return fmt.Sprintf("(%.5f, %.5f)", p.Lat, p.Long) }
+
+func rad(deg float64) float64 { return deg * math.Pi / 180 }
+func deg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// Distance returns the great-circle distance between a and b in metres using
+// the haversine formula, which stays accurate for small distances where the
+// spherical law of cosines loses precision.
+func Distance(a, b Point) float64 {
+	dLat := rad(b.Lat - a.Lat)
+	dLong := rad(b.Long - a.Long)
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Pow(math.Sin(dLong/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// Bounds is a latitude/longitude rectangle. When Min.Long > Max.Long the box
+// crosses the antimeridian: it spans Min.Long..180 and -180..Max.Long.
+type Bounds struct {
+	Min, Max Point
+}
+
+// Contains reports whether p lies inside b, edges included.
+func (b Bounds) Contains(p Point) bool {
+	if p.Lat < b.Min.Lat || p.Lat > b.Max.Lat {
+		return false
+	}
+	if b.Min.Long <= b.Max.Long {
+		return p.Long >= b.Min.Long && p.Long <= b.Max.Long
+	}
+	return p.Long >= b.Min.Long || p.Long <= b.Max.Long
+}
+
+// Center returns the middle of b.
+func (b Bounds) Center() Point {
+	long := (b.Min.Long + b.Max.Long) / 2
+	if b.Min.Long > b.Max.Long {
+		long += 180
+		if long > 180 {
+			long -= 360
+		}
+	}
+	return Point{(b.Min.Lat + b.Max.Lat) / 2, long}
+}
+
+// BoundsAround returns the smallest box containing every point within
+// radius metres of center. Near the poles it widens to all longitudes.
+func BoundsAround(center Point, radius float64) Bounds {
+	angular := radius / EarthRadius
+	dLat := deg(angular)
+	b := Bounds{
+		Min: Point{center.Lat - dLat, -180},
+		Max: Point{center.Lat + dLat, 180},
+	}
+	if b.Min.Lat <= -90 || b.Max.Lat >= 90 {
+		// A pole is inside the circle.
+		b.Min.Lat = max(b.Min.Lat, -90)
+		b.Max.Lat = min(b.Max.Lat, 90)
+		return b
+	}
+	// Longitude span at the latitude where the circle is widest, which is
+	// not center.Lat itself (Matuschek, "Finding Points Within a Distance").
+	s := math.Sin(angular) / math.Cos(rad(center.Lat))
+	if s >= 1 {
+		return b
+	}
+	dLong := deg(math.Asin(s))
+	b.Min.Long = center.Long - dLong
+	b.Max.Long = center.Long + dLong
+	if b.Min.Long < -180 {
+		b.Min.Long += 360
+	}
+	if b.Max.Long > 180 {
+		b.Max.Long -= 360
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+)
+
+// base32 is the geohash alphabet: digits and letters without a, i, l, o.
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxPrecision is the longest geohash Encode produces; 12 characters locate a
+// point to a few centimetres.
+const MaxPrecision = 12
+
+// ErrInvalidGeohash is returned by Decode for empty, too long or non-base32
+// input.
+var ErrInvalidGeohash = errors.New("geo: invalid geohash")
+
+// Encode returns the geohash of p with precision characters (1..12). Each
+// character adds five bits that alternately halve the longitude and latitude
+// range, starting with longitude, so nearby points usually share a prefix.
+func Encode(p Point, precision int) string {
+	precision = max(1, min(precision, MaxPrecision))
+	latLo, latHi := -90.0, 90.0
+	longLo, longHi := -180.0, 180.0
+	var sb strings.Builder
+	sb.Grow(precision)
+	even := true
+	for sb.Len() < precision {
+		ch := 0
+		for range 5 {
+			ch <<= 1
+			if even {
+				mid := (longLo + longHi) / 2
+				if p.Long >= mid {
+					ch |= 1
+					longLo = mid
+				} else {
+					longHi = mid
+				}
+			} else {
+				mid := (latLo + latHi) / 2
+				if p.Lat >= mid {
+					ch |= 1
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+		sb.WriteByte(base32[ch])
+	}
+	return sb.String()
+}
+
+// Decode returns the cell a geohash stands for. Its Center is the best
+// estimate of the encoded point.
+func Decode(hash string) (Bounds, error) {
+	if hash == "" || len(hash) > MaxPrecision {
+		return Bounds{}, ErrInvalidGeohash
+	}
+	b := Bounds{Min: Point{-90, -180}, Max: Point{90, 180}}
+	even := true
+	for i := 0; i < len(hash); i++ {
+		ch := strings.IndexByte(base32, hash[i])
+		if ch < 0 {
+			return Bounds{}, ErrInvalidGeohash
+		}
+		for bit := 4; bit >= 0; bit-- {
+			on := ch>>bit&1 == 1
+			if even {
+				mid := (b.Min.Long + b.Max.Long) / 2
+				if on {
+					b.Min.Long = mid
+				} else {
+					b.Max.Long = mid
+				}
+			} else {
+				mid := (b.Min.Lat + b.Max.Lat) / 2
+				if on {
+					b.Min.Lat = mid
+				} else {
+					b.Max.Lat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return b, nil
+}
+
+// cellSize returns the height and width in degrees of a geohash cell of the
+// given precision.
+func cellSize(precision int) (lat, long float64) {
+	bits := 5 * precision
+	longBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / float64(uint64(1)<<latBits), 360 / float64(uint64(1)<<longBits)
+}
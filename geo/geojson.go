@@ -0,0 +1,115 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Feature is a GeoJSON Point feature with free-form properties.
+type Feature struct {
+	Point      Point
+	Properties map[string]any
+}
+
+// The wire format (RFC 7946). Coordinates are [longitude, latitude], the
+// reverse of the Lat, Long order used everywhere else.
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// WriteGeoJSON writes features as a FeatureCollection.
+func WriteGeoJSON(w io.Writer, features []Feature) error {
+	fc := geoJSONCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(features))}
+	for _, f := range features {
+		coords, _ := json.Marshal([2]float64{f.Point.Long, f.Point.Lat})
+		props := f.Properties
+		if props == nil {
+			props = map[string]any{}
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   &geoJSONGeometry{Type: "Point", Coordinates: coords},
+			Properties: props,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fc)
+}
+
+// ReadGeoJSON reads a FeatureCollection, a single Feature or a bare Point
+// geometry. MultiPoint features are expanded into one Feature per point;
+// other geometry types are skipped and counted in skipped.
+func ReadGeoJSON(r io.Reader) (features []Feature, skipped int, err error) {
+	// One struct covers all three top-level shapes; Type says which
+	// fields are meaningful.
+	var raw struct {
+		Type        string           `json:"type"`
+		Features    []geoJSONFeature `json:"features"`
+		Geometry    *geoJSONGeometry `json:"geometry"`
+		Properties  map[string]any   `json:"properties"`
+		Coordinates json.RawMessage  `json:"coordinates"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, 0, fmt.Errorf("geo: geojson: %w", err)
+	}
+	var fs []geoJSONFeature
+	switch raw.Type {
+	case "FeatureCollection":
+		fs = raw.Features
+	case "Feature":
+		fs = []geoJSONFeature{{Geometry: raw.Geometry, Properties: raw.Properties}}
+	case "Point", "MultiPoint":
+		fs = []geoJSONFeature{{Geometry: &geoJSONGeometry{Type: raw.Type, Coordinates: raw.Coordinates}}}
+	default:
+		return nil, 0, fmt.Errorf("geo: geojson: unsupported type %q", raw.Type)
+	}
+
+	for i, f := range fs {
+		if f.Geometry == nil {
+			skipped++
+			continue
+		}
+		var pts [][]float64
+		switch f.Geometry.Type {
+		case "Point":
+			var c []float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &c); err != nil {
+				return nil, 0, fmt.Errorf("geo: geojson: feature %d: %w", i, err)
+			}
+			pts = [][]float64{c}
+		case "MultiPoint":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &pts); err != nil {
+				return nil, 0, fmt.Errorf("geo: geojson: feature %d: %w", i, err)
+			}
+		default:
+			skipped++
+			continue
+		}
+		for _, c := range pts {
+			// A third value, the altitude, is allowed and ignored.
+			if len(c) < 2 {
+				return nil, 0, fmt.Errorf("geo: geojson: feature %d: position needs 2 coordinates, got %d", i, len(c))
+			}
+			p := Point{Lat: c[1], Long: c[0]}
+			if !p.Valid() {
+				return nil, 0, fmt.Errorf("geo: geojson: feature %d: position %v out of range", i, c)
+			}
+			features = append(features, Feature{Point: p, Properties: f.Properties})
+		}
+	}
+	return features, skipped, nil
+}
@@ -0,0 +1,176 @@
+package geo
+
+import (
+	"math"
+	"slices"
+)
+
+// Item is a value stored at a point.
+type Item[T any] struct {
+	Point Point
+	Value T
+}
+
+// Result is an item found by a distance query.
+type Result[T any] struct {
+	Item[T]
+	Distance float64 // metres from the query point
+}
+
+// Index is a geohash grid: every item is filed under the geohash of its point
+// at a fixed precision. Queries turn their area into the set of covering cells
+// and only measure the items in those cells. It is not safe for concurrent
+// use.
+//
+// Precision trades cell count against items per cell; 5 gives cells of about
+// 4.9 x 4.9 km at the equator, a good default for city-scale queries.
+type Index[T any] struct {
+	precision int
+	cellLat   float64
+	cellLong  float64
+	cells     map[string][]Item[T]
+	n         int
+}
+
+// NewIndex returns an empty index with the given geohash precision (1..12).
+func NewIndex[T any](precision int) *Index[T] {
+	precision = max(1, min(precision, MaxPrecision))
+	lat, long := cellSize(precision)
+	return &Index[T]{
+		precision: precision,
+		cellLat:   lat,
+		cellLong:  long,
+		cells:     make(map[string][]Item[T]),
+	}
+}
+
+// Len returns the number of items.
+func (ix *Index[T]) Len() int { return ix.n }
+
+// Add stores v at p.
+func (ix *Index[T]) Add(p Point, v T) {
+	h := Encode(p, ix.precision)
+	ix.cells[h] = append(ix.cells[h], Item[T]{p, v})
+	ix.n++
+}
+
+// Remove deletes the items at exactly p for which match returns true and
+// returns how many were removed.
+func (ix *Index[T]) Remove(p Point, match func(T) bool) int {
+	h := Encode(p, ix.precision)
+	before := len(ix.cells[h])
+	items := slices.DeleteFunc(ix.cells[h], func(it Item[T]) bool {
+		return it.Point == p && match(it.Value)
+	})
+	if len(items) == 0 {
+		delete(ix.cells, h)
+	} else {
+		ix.cells[h] = items
+	}
+	removed := before - len(items)
+	ix.n -= removed
+	return removed
+}
+
+// All returns every item, in no particular order.
+func (ix *Index[T]) All() []Item[T] {
+	out := make([]Item[T], 0, ix.n)
+	for _, items := range ix.cells {
+		out = append(out, items...)
+	}
+	return out
+}
+
+// Within returns the items inside b.
+func (ix *Index[T]) Within(b Bounds) []Item[T] {
+	var out []Item[T]
+	ix.visit(b, func(items []Item[T]) {
+		for _, it := range items {
+			if b.Contains(it.Point) {
+				out = append(out, it)
+			}
+		}
+	})
+	return out
+}
+
+// Radius returns the items within radius metres of center, nearest first.
+func (ix *Index[T]) Radius(center Point, radius float64) []Result[T] {
+	var out []Result[T]
+	ix.visit(BoundsAround(center, radius), func(items []Item[T]) {
+		for _, it := range items {
+			if d := Distance(center, it.Point); d <= radius {
+				out = append(out, Result[T]{it, d})
+			}
+		}
+	})
+	slices.SortFunc(out, func(a, b Result[T]) int {
+		switch {
+		case a.Distance < b.Distance:
+			return -1
+		case a.Distance > b.Distance:
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+// Nearest returns the k items closest to p, nearest first. It runs radius
+// queries starting from one cell's height and doubling until k items are
+// found, so the answer is exact and the cost follows the local density.
+func (ix *Index[T]) Nearest(p Point, k int) []Result[T] {
+	if k <= 0 || ix.n == 0 {
+		return nil
+	}
+	k = min(k, ix.n)
+	maxDist := math.Pi * EarthRadius
+	for r := rad(ix.cellLat) * EarthRadius; ; r *= 2 {
+		res := ix.Radius(p, min(r, maxDist))
+		if len(res) >= k || r >= maxDist {
+			return res[:min(k, len(res))]
+		}
+	}
+}
+
+// visit calls fn with the items of every cell that overlaps b. When b covers
+// more cells than the index has non-empty cells, walking the map is cheaper
+// than enumerating the grid.
+func (ix *Index[T]) visit(b Bounds, fn func([]Item[T])) {
+	type span struct{ lo, hi float64 }
+	longs := []span{{b.Min.Long, b.Max.Long}}
+	if b.Min.Long > b.Max.Long {
+		longs = []span{{b.Min.Long, 180}, {-180, b.Max.Long}}
+	}
+	rows := int(math.Floor((b.Max.Lat+90)/ix.cellLat)) - int(math.Floor((b.Min.Lat+90)/ix.cellLat)) + 1
+	cols := 0
+	for _, s := range longs {
+		cols += int(math.Floor((s.hi+180)/ix.cellLong)) - int(math.Floor((s.lo+180)/ix.cellLong)) + 1
+	}
+	if rows*cols > len(ix.cells) {
+		for _, items := range ix.cells {
+			fn(items)
+		}
+		return
+	}
+
+	maxRow := int(math.Round(180/ix.cellLat)) - 1
+	maxCol := int(math.Round(360/ix.cellLong)) - 1
+	r0 := max(0, int(math.Floor((b.Min.Lat+90)/ix.cellLat)))
+	r1 := min(maxRow, int(math.Floor((b.Max.Lat+90)/ix.cellLat)))
+	for _, s := range longs {
+		c0 := max(0, int(math.Floor((s.lo+180)/ix.cellLong)))
+		c1 := min(maxCol, int(math.Floor((s.hi+180)/ix.cellLong)))
+		for r := r0; r <= r1; r++ {
+			for c := c0; c <= c1; c++ {
+				center := Point{
+					Lat:  -90 + (float64(r)+0.5)*ix.cellLat,
+					Long: -180 + (float64(c)+0.5)*ix.cellLong,
+				}
+				if items, ok := ix.cells[Encode(center, ix.precision)]; ok {
+					fn(items)
+				}
+			}
+		}
+	}
+}
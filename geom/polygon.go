@@ -0,0 +1,164 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Polygon is a closed chain of vertices; the edge from the last vertex back
+// to the first is implied. Vertices may run either way round, which only
+// changes the sign of SignedArea.
+type Polygon []Vec2
+
+// Edges returns the len(p) sides of p.
+func (p Polygon) Edges() []Segment {
+	out := make([]Segment, len(p))
+	for i := range p {
+		out[i] = Segment{p[i], p[(i+1)%len(p)]}
+	}
+	return out
+}
+
+// SignedArea is positive for counter-clockwise vertices and negative for
+// clockwise ones (the shoelace formula).
+func (p Polygon) SignedArea() float64 {
+	if len(p) < 3 {
+		return 0
+	}
+	// Measuring from p[0] keeps the terms small when the polygon is far
+	// from the origin, which would otherwise cancel catastrophically.
+	var sum float64
+	for i := 1; i+1 < len(p); i++ {
+		sum += p[i].Sub(p[0]).Cross(p[i+1].Sub(p[0]))
+	}
+	return sum / 2
+}
+
+// Area returns the enclosed area of a simple polygon.
+func (p Polygon) Area() float64 { return math.Abs(p.SignedArea()) }
+
+// Perimeter returns the total edge length.
+func (p Polygon) Perimeter() float64 {
+	var sum float64
+	for _, e := range p.Edges() {
+		sum += e.Len()
+	}
+	return sum
+}
+
+// Centroid returns the centre of mass of the enclosed area. A degenerate
+// polygon with no area falls back to the mean of its vertices.
+func (p Polygon) Centroid() Vec2 {
+	if len(p) == 0 {
+		return Vec2{}
+	}
+	var a, cx, cy float64
+	for i := 1; i+1 < len(p); i++ {
+		u, w := p[i].Sub(p[0]), p[i+1].Sub(p[0])
+		cr := u.Cross(w)
+		a += cr
+		cx += (u.X + w.X) * cr
+		cy += (u.Y + w.Y) * cr
+	}
+	if a == 0 || math.Abs(a) <= Epsilon*p.Perimeter()*p.Perimeter() {
+		var sum Vec2
+		for _, v := range p {
+			sum = sum.Add(v)
+		}
+		return sum.Scale(1 / float64(len(p)))
+	}
+	return p[0].Add(Vec2{cx, cy}.Scale(1 / (3 * a)))
+}
+
+// Contains reports whether pt is inside p or on its boundary, using the
+// even-odd rule, so it works for concave polygons too.
+func (p Polygon) Contains(pt Vec2) bool {
+	if len(p) == 0 {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[j], p[i]
+		if (Segment{a, b}).Contains(pt) {
+			return true
+		}
+		// Count edges crossed by a ray from pt towards +x. The half-open
+		// test on Y counts a vertex on the ray once, not twice.
+		if (a.Y > pt.Y) != (b.Y > pt.Y) {
+			x := a.X + (pt.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X)
+			if pt.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Transform returns p with every vertex transformed by t.
+func (p Polygon) Transform(t Transform) Polygon {
+	out := make(Polygon, len(p))
+	for i, v := range p {
+		out[i] = t.Apply(v)
+	}
+	return out
+}
+
+// IsConvex reports whether every turn along p goes the same way.
+func (p Polygon) IsConvex() bool {
+	if len(p) < 3 {
+		return false
+	}
+	sign := 0.0
+	for i := range p {
+		a, b, c := p[i], p[(i+1)%len(p)], p[(i+2)%len(p)]
+		cr := b.Sub(a).Cross(c.Sub(b))
+		if cr == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cr
+		} else if (cr > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return sign != 0
+}
+
+// ConvexHull returns the smallest convex polygon containing points, counter-
+// clockwise from the leftmost (then lowest) point, without collinear vertices
+// (Andrew's monotone chain, O(n log n)). Points with NaN coordinates are
+// ignored. Fewer than three distinct points give a degenerate hull of the
+// distinct points.
+func ConvexHull(points []Vec2) Polygon {
+	pts := slices.DeleteFunc(slices.Clone(points), func(v Vec2) bool {
+		return math.IsNaN(v.X) || math.IsNaN(v.Y)
+	})
+	slices.SortFunc(pts, func(a, b Vec2) int {
+		return cmp.Or(cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y))
+	})
+	pts = slices.Compact(pts)
+	if len(pts) < 3 {
+		return Polygon(pts)
+	}
+	turn := func(o, a, b Vec2) float64 { return a.Sub(o).Cross(b.Sub(o)) }
+
+	hull := make(Polygon, 0, 2*len(pts))
+	// Lower hull left to right, then upper hull right to left; pop while
+	// the last two points and the new one do not turn counter-clockwise.
+	for _, v := range pts {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], v) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, v)
+	}
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		v := pts[i]
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], v) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, v)
+	}
+	return hull[:len(hull)-1]
+}
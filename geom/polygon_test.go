@@ -0,0 +1,55 @@
+package geom
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// FuzzConvexHull reads the points as pairs of float64s.
+func FuzzConvexHull(f *testing.F) {
+	seed := func(vs ...float64) []byte {
+		var b []byte
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+		}
+		return b
+	}
+	f.Add(seed(0, 0, 1, 0, 1, 1, 0, 1, 0.5, 0.5))
+	f.Add(seed(0, 0, 1, 1, 2, 2, 3, 3))
+	f.Add(seed(0, 0, math.NaN(), 1, 1e300, -1e300, 2, 2))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Subnormal and huge coordinates underflow or overflow the cross
+		// products; those only have to come out without NaN.
+		ordinary := func(v float64) bool { return v == 0 || math.Abs(v) >= 1e-100 && math.Abs(v) <= 1e6 }
+		var pts []Vec2
+		usual := true
+		for len(data) >= 16 {
+			v := V(math.Float64frombits(binary.LittleEndian.Uint64(data)),
+				math.Float64frombits(binary.LittleEndian.Uint64(data[8:])))
+			data = data[16:]
+			if math.IsNaN(v.X) || math.IsNaN(v.Y) {
+				continue
+			}
+			usual = usual && ordinary(v.X) && ordinary(v.Y)
+			pts = append(pts, v)
+		}
+		hull := ConvexHull(pts)
+		for _, v := range hull {
+			if math.IsNaN(v.X) || math.IsNaN(v.Y) {
+				t.Fatalf("NaN in hull %v of %#v", hull, pts)
+			}
+		}
+		if !usual || len(hull) < 3 {
+			return
+		}
+		if !hull.IsConvex() || hull.SignedArea() <= 0 {
+			t.Fatalf("hull %v of %#v is not convex and counter-clockwise", hull, pts)
+		}
+		for _, p := range pts {
+			if !hull.Contains(p) {
+				t.Errorf("hull %v misses %#v", hull, p)
+			}
+		}
+	})
+}
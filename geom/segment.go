@@ -0,0 +1,85 @@
+package geom
+
+import "math"
+
+// Segment is the straight line between two points.
+type Segment struct {
+	A, B Vec2
+}
+
+// Len returns the length of s.
+func (s Segment) Len() float64 { return s.A.Dist(s.B) }
+
+// Closest returns the point of s nearest to p.
+func (s Segment) Closest(p Vec2) Vec2 {
+	d := s.B.Sub(s.A)
+	l2 := d.Dot(d)
+	if l2 == 0 {
+		return s.A
+	}
+	t := max(0, min(1, p.Sub(s.A).Dot(d)/l2))
+	return s.A.Lerp(s.B, t)
+}
+
+// DistTo returns the distance from p to the nearest point of s.
+func (s Segment) DistTo(p Vec2) float64 { return p.Dist(s.Closest(p)) }
+
+// Contains reports whether p lies on s within Epsilon.
+func (s Segment) Contains(p Vec2) bool {
+	return s.DistTo(p) <= Epsilon*max(1, s.Len(), p.Abs())
+}
+
+// Intersect returns a point shared by s and o. Crossing or touching segments
+// meet in one point. Collinear segments that overlap share a whole stretch;
+// the overlap endpoint closest to s.A is returned.
+func (s Segment) Intersect(o Segment) (Vec2, bool) {
+	r := s.B.Sub(s.A)
+	q := o.B.Sub(o.A)
+	// A single point has no direction to be parallel or collinear with.
+	switch {
+	case r.IsZero() && o.Contains(s.A):
+		return s.A, true
+	case q.IsZero() && s.Contains(o.A):
+		return o.A, true
+	case r.IsZero() || q.IsZero():
+		return Vec2{}, false
+	}
+	w := o.A.Sub(s.A)
+	denom := r.Cross(q)
+	scale := max(r.Abs()*q.Abs(), math.SmallestNonzeroFloat64)
+
+	if math.Abs(denom) > Epsilon*scale {
+		// Solve s.A + t*r = o.A + u*q.
+		t := w.Cross(q) / denom
+		u := w.Cross(r) / denom
+		const slack = Epsilon
+		if t < -slack || t > 1+slack || u < -slack || u > 1+slack {
+			return Vec2{}, false
+		}
+		return s.A.Lerp(s.B, max(0, min(1, t))), true
+	}
+
+	// Parallel. They only meet if collinear, and then only where their
+	// projections onto r overlap.
+	if !s.collinear(o.A) {
+		return Vec2{}, false
+	}
+	r2 := r.Dot(r)
+	t0 := w.Dot(r) / r2
+	t1 := o.B.Sub(s.A).Dot(r) / r2
+	lo, hi := max(0, min(t0, t1)), min(1, max(t0, t1))
+	if lo > hi+Epsilon {
+		return Vec2{}, false
+	}
+	return s.A.Lerp(s.B, lo), true
+}
+
+// collinear reports whether p lies on the infinite line through s.
+func (s Segment) collinear(p Vec2) bool {
+	r := s.B.Sub(s.A)
+	w := p.Sub(s.A)
+	if r.IsZero() {
+		return w.Abs() <= Epsilon*max(1, p.Abs())
+	}
+	return math.Abs(r.Cross(w)) <= Epsilon*r.Abs()*max(1, w.Abs())
+}
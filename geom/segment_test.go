@@ -0,0 +1,80 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntersectPoint(t *testing.T) {
+	for _, tc := range []struct {
+		s, o Segment
+		want Vec2
+		ok   bool
+	}{
+		{Segment{V(1, 0), V(1, 0)}, Segment{V(0, 0), V(2, 0)}, V(1, 0), true},
+		{Segment{V(0, 0), V(0, 0)}, Segment{V(0, 0), V(2, 0)}, V(0, 0), true},
+		{Segment{V(1, 1), V(1, 1)}, Segment{V(0, 0), V(2, 0)}, Vec2{}, false},
+		{Segment{V(3, 0), V(3, 0)}, Segment{V(0, 0), V(2, 0)}, Vec2{}, false},
+		{Segment{V(1, 1), V(1, 1)}, Segment{V(1, 1), V(1, 1)}, V(1, 1), true},
+		{Segment{V(1, 1), V(1, 1)}, Segment{V(2, 2), V(2, 2)}, Vec2{}, false},
+	} {
+		for _, pair := range [][2]Segment{{tc.s, tc.o}, {tc.o, tc.s}} {
+			p, ok := pair[0].Intersect(pair[1])
+			if ok != tc.ok || ok && !p.Eq(tc.want) {
+				t.Errorf("%v.Intersect(%v) = %v, %v; want %v, %v", pair[0], pair[1], p, ok, tc.want, tc.ok)
+			}
+		}
+	}
+}
+
+// FuzzIntersect uses small integer coordinates, where every orientation
+// test is exact, so the answer must not depend on argument order.
+func FuzzIntersect(f *testing.F) {
+	f.Add(int8(1), int8(0), int8(1), int8(0), int8(0), int8(0), int8(2), int8(0))
+	f.Add(int8(0), int8(0), int8(4), int8(4), int8(0), int8(4), int8(4), int8(0))
+	f.Add(int8(0), int8(0), int8(2), int8(0), int8(1), int8(0), int8(3), int8(0))
+	f.Add(int8(0), int8(0), int8(2), int8(0), int8(3), int8(0), int8(4), int8(0))
+	f.Fuzz(func(t *testing.T, ax, ay, bx, by, cx, cy, dx, dy int8) {
+		s := Segment{V(float64(ax), float64(ay)), V(float64(bx), float64(by))}
+		o := Segment{V(float64(cx), float64(cy)), V(float64(dx), float64(dy))}
+		p, ok := s.Intersect(o)
+		q, ok2 := o.Intersect(s)
+		if ok != ok2 {
+			t.Fatalf("%v.Intersect(%v) = %v but the reverse is %v", s, o, ok, ok2)
+		}
+		if !ok {
+			return
+		}
+		for _, x := range []Vec2{p, q} {
+			if !s.Contains(x) || !o.Contains(x) {
+				t.Errorf("%v x %v = %v, not on both", s, o, x)
+			}
+		}
+	})
+}
+
+// FuzzIntersectFloat only asks that a reported point is on both segments
+// and that nothing panics, for any finite input.
+func FuzzIntersectFloat(f *testing.F) {
+	f.Add(1.0, 0.0, 1.0, 0.0, 0.0, 0.0, 2.0, 0.0)
+	f.Add(0.1, 0.2, 0.3, 0.4, 0.3, 0.2, 0.1, 0.4)
+	f.Add(1e300, 0.0, -1e300, 0.0, 0.0, 1e-300, 0.0, -1e-300)
+	f.Fuzz(func(t *testing.T, ax, ay, bx, by, cx, cy, dx, dy float64) {
+		s := Segment{V(ax, ay), V(bx, by)}
+		o := Segment{V(cx, cy), V(dx, dy)}
+		for _, v := range []float64{ax, ay, bx, by, cx, cy, dx, dy} {
+			if math.IsNaN(v) || math.Abs(v) > 1e6 {
+				s.Intersect(o)
+				return
+			}
+		}
+		p, ok := s.Intersect(o)
+		if !ok {
+			return
+		}
+		tol := 1e-6 * max(1, s.Len(), o.Len())
+		if s.DistTo(p) > tol || o.DistTo(p) > tol {
+			t.Errorf("%#v x %#v = %#v, %v and %v away", s, o, p, s.DistTo(p), o.DistTo(p))
+		}
+	})
+}
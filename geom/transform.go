@@ -0,0 +1,87 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Transform is a 2D affine transform stored as the top two rows of a 3x3
+// matrix, in the order used by SVG and canvas:
+//
+//	| A C E |   | x |
+//	| B D F | * | y |
+//	| 0 0 1 |   | 1 |
+//
+// The zero value is not the identity; start from Identity.
+type Transform struct {
+	A, B, C, D, E, F float64
+}
+
+// Identity leaves every point where it is.
+func Identity() Transform { return Transform{A: 1, D: 1} }
+
+// Translate moves points by (dx, dy).
+func Translate(dx, dy float64) Transform { return Transform{A: 1, D: 1, E: dx, F: dy} }
+
+// Scale stretches by sx along x and sy along y around the origin.
+func Scale(sx, sy float64) Transform { return Transform{A: sx, D: sy} }
+
+// Rotate turns counter-clockwise by theta radians around the origin.
+func Rotate(theta float64) Transform {
+	s, c := math.Sincos(theta)
+	return Transform{A: c, B: s, C: -s, D: c}
+}
+
+// RotateAround turns counter-clockwise by theta radians around p.
+func RotateAround(p Vec2, theta float64) Transform {
+	return Translate(-p.X, -p.Y).Then(Rotate(theta)).Then(Translate(p.X, p.Y))
+}
+
+// Shear slants x by kx*y and y by ky*x.
+func Shear(kx, ky float64) Transform { return Transform{A: 1, B: ky, C: kx, D: 1} }
+
+// Then returns the transform that applies t first and then u.
+func (t Transform) Then(u Transform) Transform {
+	return Transform{
+		A: u.A*t.A + u.C*t.B,
+		B: u.B*t.A + u.D*t.B,
+		C: u.A*t.C + u.C*t.D,
+		D: u.B*t.C + u.D*t.D,
+		E: u.A*t.E + u.C*t.F + u.E,
+		F: u.B*t.E + u.D*t.F + u.F,
+	}
+}
+
+// Apply transforms the point p.
+func (t Transform) Apply(p Vec2) Vec2 {
+	return Vec2{t.A*p.X + t.C*p.Y + t.E, t.B*p.X + t.D*p.Y + t.F}
+}
+
+// ApplyVector transforms a direction: like Apply but without translation.
+func (t Transform) ApplyVector(v Vec2) Vec2 {
+	return Vec2{t.A*v.X + t.C*v.Y, t.B*v.X + t.D*v.Y}
+}
+
+// Det is the determinant of the linear part: the factor by which areas are
+// scaled, negative when the transform mirrors.
+func (t Transform) Det() float64 { return t.A*t.D - t.B*t.C }
+
+// Invert returns the inverse transform, or false if t collapses the plane
+// onto a line or point and has none.
+func (t Transform) Invert() (Transform, bool) {
+	det := t.Det()
+	scale := max(math.Abs(t.A), math.Abs(t.B), math.Abs(t.C), math.Abs(t.D))
+	if det == 0 || math.Abs(det) <= Epsilon*scale*scale || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Transform{}, false
+	}
+	a, b, c, d := t.D/det, -t.B/det, -t.C/det, t.A/det
+	return Transform{
+		A: a, B: b, C: c, D: d,
+		E: -(a*t.E + c*t.F),
+		F: -(b*t.E + d*t.F),
+	}, true
+}
+
+func (t Transform) String() string {
+	return fmt.Sprintf("matrix(%g %g %g %g %g %g)", t.A, t.B, t.C, t.D, t.E, t.F)
+}
@@ -0,0 +1,35 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func FuzzInvert(f *testing.F) {
+	f.Add(1.0, 0.0, 0.0, 1.0, 0.0, 0.0, 3.0, 4.0)
+	f.Add(2.0, 1.0, 4.0, 2.0, 5.0, 6.0, 1.0, 1.0)
+	f.Add(0.0, -1.0, 1.0, 0.0, 10.0, -10.0, 7.0, 0.5)
+	f.Fuzz(func(t *testing.T, a, b, c, d, e, ff, x, y float64) {
+		tr := Transform{A: a, B: b, C: c, D: d, E: e, F: ff}
+		inv, ok := tr.Invert()
+		if !ok {
+			return
+		}
+		for _, v := range []float64{a, b, c, d, e, ff, x, y} {
+			if math.Abs(v) > 1e3 || math.IsNaN(v) {
+				return
+			}
+		}
+		// Keep to transforms that do not squash one direction much more
+		// than the other; the round trip error grows with the condition.
+		scale := max(math.Abs(a), math.Abs(b), math.Abs(c), math.Abs(d))
+		if math.Abs(tr.Det()) < 1e-3*scale*scale {
+			return
+		}
+		p := V(x, y)
+		back := inv.Apply(tr.Apply(p))
+		if back.Dist(p) > 1e-6*max(1, p.Abs(), math.Abs(e), math.Abs(ff)) {
+			t.Errorf("%v then %v moved %#v to %#v", tr, inv, p, back)
+		}
+	})
+}
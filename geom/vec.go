@@ -0,0 +1,111 @@
+/*
+Package geom is 2D geometry on float64 vectors.
+
+method/main.go, method/pointer.go and method/indirection.go each declare their
+own Vertex{X, Y float64} with Abs and Scale. Vec2 is that Vertex grown into a
+reusable type. It is small enough to copy, so every method takes a value
+receiver and returns a new vector instead of scaling in place through a
+pointer as method/pointer.go does; v.Scale(2) never changes v.
+
+Besides vectors the package has affine Transforms, Segments and Polygons.
+Comparisons that decide a geometric predicate (collinear, parallel, on an edge)
+use a relative tolerance of Epsilon so results do not flip on rounding noise.
+*/
+package geom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Epsilon is the relative tolerance used by predicates such as parallel or
+// collinear tests.
+const Epsilon = 1e-9
+
+// Vec2 is a point or direction in the plane.
+type Vec2 struct {
+	X, Y float64
+}
+
+// V is shorthand for Vec2{x, y}.
+func V(x, y float64) Vec2 { return Vec2{x, y} }
+
+func (v Vec2) Add(o Vec2) Vec2             { return Vec2{v.X + o.X, v.Y + o.Y} }
+func (v Vec2) Sub(o Vec2) Vec2             { return Vec2{v.X - o.X, v.Y - o.Y} }
+func (v Vec2) Scale(f float64) Vec2        { return Vec2{v.X * f, v.Y * f} }
+func (v Vec2) Neg() Vec2                   { return Vec2{-v.X, -v.Y} }
+func (v Vec2) Dot(o Vec2) float64          { return v.X*o.X + v.Y*o.Y }
+func (v Vec2) Dist(o Vec2) float64         { return v.Sub(o).Abs() }
+func (v Vec2) Perp() Vec2                  { return Vec2{-v.Y, v.X} } // rotated 90° counter-clockwise
+func (v Vec2) IsZero() bool                { return v.X == 0 && v.Y == 0 }
+func (v Vec2) Angle() float64              { return math.Atan2(v.Y, v.X) }
+func (v Vec2) Lerp(o Vec2, t float64) Vec2 { return Vec2{v.X + (o.X-v.X)*t, v.Y + (o.Y-v.Y)*t} }
+
+// Cross returns the z component of the 3D cross product: positive when o is
+// counter-clockwise from v, negative when clockwise, zero when parallel.
+func (v Vec2) Cross(o Vec2) float64 { return v.X*o.Y - v.Y*o.X }
+
+// Abs returns the length of v, as in method/main.go. It uses math.Hypot so
+// components near the float64 limits do not overflow when squared.
+func (v Vec2) Abs() float64 { return math.Hypot(v.X, v.Y) }
+
+// Normalize returns the unit vector in the direction of v, or the zero vector
+// if v has no direction (zero, infinite or NaN length).
+func (v Vec2) Normalize() Vec2 {
+	// Dividing by the larger component first brings subnormal components up
+	// to a range where the length is exact; dividing by a rounded subnormal
+	// length directly can give a "unit" vector of length √2.
+	m := max(math.Abs(v.X), math.Abs(v.Y))
+	if m == 0 || math.IsInf(m, 0) || math.IsNaN(m) {
+		return Vec2{}
+	}
+	u := Vec2{v.X / m, v.Y / m}
+	return u.Scale(1 / u.Abs())
+}
+
+// Rotate turns v counter-clockwise by theta radians around the origin.
+func (v Vec2) Rotate(theta float64) Vec2 {
+	s, c := math.Sincos(theta)
+	return Vec2{v.X*c - v.Y*s, v.X*s + v.Y*c}
+}
+
+// Eq reports whether v and o are equal within Epsilon relative to their size.
+func (v Vec2) Eq(o Vec2) bool {
+	return near(v.X, o.X) && near(v.Y, o.Y)
+}
+
+// near compares with a tolerance relative to the larger magnitude, with a
+// floor of Epsilon for values close to zero.
+func near(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= Epsilon*max(1, math.Abs(a), math.Abs(b))
+}
+
+// Format implements fmt.Formatter:
+//
+//	%v   (1, 2.5)
+//	%+v  {X:1 Y:2.5}
+//	%#v  geom.Vec2{X:1, Y:2.5}
+//	%.2f (1.00, 2.50), and likewise %e %g with any width, precision and flags
+func (v Vec2) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			fmt.Fprintf(f, "geom.Vec2{X:%#v, Y:%#v}", v.X, v.Y)
+			return
+		case f.Flag('+'):
+			fmt.Fprintf(f, "{X:%v Y:%v}", v.X, v.Y)
+			return
+		}
+		fallthrough
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		// Apply the caller's flags, width and precision to each component.
+		directive := fmt.FormatString(f, verb)
+		fmt.Fprintf(f, "("+directive+", "+directive+")", v.X, v.Y)
+	default:
+		fmt.Fprintf(f, "%%!%c(geom.Vec2=(%v, %v))", verb, v.X, v.Y)
+	}
+}
@@ -0,0 +1,30 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func FuzzNormalize(f *testing.F) {
+	f.Add(3.0, 4.0)
+	f.Add(0.0, math.Copysign(0, -1))
+	f.Add(math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64)
+	f.Add(math.MaxFloat64, math.MaxFloat64)
+	f.Add(math.Inf(1), 1.0)
+	f.Fuzz(func(t *testing.T, x, y float64) {
+		v := V(x, y)
+		n := v.Normalize()
+		if n.IsZero() {
+			if m := max(math.Abs(x), math.Abs(y)); m != 0 && !math.IsInf(m, 0) && !math.IsNaN(m) {
+				t.Errorf("%#v.Normalize() = 0", v)
+			}
+			return
+		}
+		if l := n.Abs(); math.Abs(l-1) > 1e-12 {
+			t.Errorf("%#v.Normalize() = %#v, length %v", v, n, l)
+		}
+		if n.Cross(v.Scale(1/max(math.Abs(x), math.Abs(y)))) > 1e-12 || n.Dot(v) < 0 {
+			t.Errorf("%#v.Normalize() = %#v points elsewhere", v, n)
+		}
+	})
+}
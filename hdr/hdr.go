@@ -0,0 +1,241 @@
+/*
+Package hdr is a High Dynamic Range histogram after Gil Tene's HdrHistogram.
+
+A latency distribution spans orders of magnitude: most requests take a
+millisecond, the one that matters took two seconds. Fixed-width buckets either
+waste memory on the tail or blur the head. HDR buckets keep a constant relative
+precision instead: with 3 significant digits every recorded value is counted
+in a bucket no wider than 0.1% of the value, whether it is 1µs or 1h.
+
+The layout is a series of buckets, each covering twice the range of the
+previous one with the same number of sub-buckets, so the width of a sub-bucket
+doubles from one bucket to the next. Finding the slot for a value is a couple
+of shifts and a leading-zero count; recording is one atomic add, so many
+goroutines can share a histogram without a lock. For the highest throughput,
+give each goroutine its own histogram and Merge them afterwards.
+
+Values are int64 in whatever unit the caller picks, typically nanoseconds or
+microseconds.
+*/
+package hdr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// ErrOutOfRange is returned when recording a value above the histogram's
+// highest trackable value or below zero.
+var ErrOutOfRange = errors.New("hdr: value out of range")
+
+// Histogram counts int64 values with a fixed number of significant digits.
+type Histogram struct {
+	lowest, highest int64
+	sigfigs         int
+
+	unitMagnitude               uint // log2 of lowest, rounded down
+	subBucketHalfCountMagnitude uint
+	subBucketCount              int64
+	subBucketHalfCount          int64
+	subBucketMask               int64
+	bucketCount                 int
+
+	counts []atomic.Int64
+	total  atomic.Int64
+	min    atomic.Int64
+	max    atomic.Int64
+}
+
+// New returns a histogram that tracks values from lowest (at least 1) to
+// highest (at least 2*lowest) with sigfigs significant decimal digits (1..5).
+// lowest sets the resolution: values below it are indistinguishable from 0.
+func New(lowest, highest int64, sigfigs int) (*Histogram, error) {
+	h, err := layout(lowest, highest, sigfigs)
+	if err != nil {
+		return nil, err
+	}
+	h.counts = make([]atomic.Int64, h.slots())
+	h.min.Store(math.MaxInt64)
+	return h, nil
+}
+
+// layout checks New's arguments and returns a histogram with their bucket
+// layout but no counts allocated yet.
+func layout(lowest, highest int64, sigfigs int) (*Histogram, error) {
+	if lowest < 1 {
+		return nil, fmt.Errorf("hdr: lowest trackable value must be at least 1, got %d", lowest)
+	}
+	if highest < 2*lowest {
+		return nil, fmt.Errorf("hdr: highest trackable value %d must be at least twice the lowest %d", highest, lowest)
+	}
+	if sigfigs < 1 || sigfigs > 5 {
+		return nil, fmt.Errorf("hdr: significant figures must be 1..5, got %d", sigfigs)
+	}
+	h := &Histogram{lowest: lowest, highest: highest, sigfigs: sigfigs}
+
+	// Enough sub-buckets that one unit in the last significant digit of the
+	// largest value in a bucket is still a whole sub-bucket.
+	largestSingleUnit := 2 * int64(math.Pow10(sigfigs))
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(float64(largestSingleUnit))))
+	h.subBucketHalfCountMagnitude = max(subBucketCountMagnitude, 1) - 1
+	h.unitMagnitude = uint(bits.Len64(uint64(lowest)) - 1)
+	h.subBucketCount = 1 << (h.subBucketHalfCountMagnitude + 1)
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = (h.subBucketCount - 1) << h.unitMagnitude
+
+	// Buckets needed until the range covers highest.
+	smallestUntrackable := h.subBucketCount << h.unitMagnitude
+	h.bucketCount = 1
+	for smallestUntrackable <= highest {
+		if smallestUntrackable > math.MaxInt64/2 {
+			h.bucketCount++
+			break
+		}
+		smallestUntrackable <<= 1
+		h.bucketCount++
+	}
+	return h, nil
+}
+
+// slots returns the length of the counts array for h's layout.
+func (h *Histogram) slots() int { return (h.bucketCount + 1) * int(h.subBucketHalfCount) }
+
+// MustNew is New that panics on invalid arguments, for package-level
+// histograms with constant parameters.
+func MustNew(lowest, highest int64, sigfigs int) *Histogram {
+	h, err := New(lowest, highest, sigfigs)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func (h *Histogram) LowestTrackable() int64  { return h.lowest }
+func (h *Histogram) HighestTrackable() int64 { return h.highest }
+func (h *Histogram) SignificantFigures() int { return h.sigfigs }
+
+// MemorySize returns the approximate bytes used by the counts.
+func (h *Histogram) MemorySize() int { return len(h.counts) * 8 }
+
+func (h *Histogram) bucketIndex(v int64) int {
+	// Position of the highest set bit, with the mask forcing values below
+	// the first bucket's top into bucket 0.
+	pow2Ceiling := bits.Len64(uint64(v | h.subBucketMask))
+	return pow2Ceiling - int(h.unitMagnitude) - int(h.subBucketHalfCountMagnitude+1)
+}
+
+func (h *Histogram) subBucketIndex(v int64, bucket int) int64 {
+	return v >> (uint(bucket) + h.unitMagnitude)
+}
+
+func (h *Histogram) countsIndex(bucket int, sub int64) int {
+	// Bucket 0 uses all its sub-buckets; every later bucket only its upper
+	// half, since its lower half is covered by the previous bucket.
+	base := (bucket + 1) << h.subBucketHalfCountMagnitude
+	return base + int(sub-h.subBucketHalfCount)
+}
+
+func (h *Histogram) indexOf(v int64) int {
+	b := h.bucketIndex(v)
+	return h.countsIndex(b, h.subBucketIndex(v, b))
+}
+
+func (h *Histogram) valueFromIndex(i int) int64 {
+	bucket := (i >> h.subBucketHalfCountMagnitude) - 1
+	sub := int64(i)&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucket < 0 {
+		sub -= h.subBucketHalfCount
+		bucket = 0
+	}
+	return sub << (uint(bucket) + h.unitMagnitude)
+}
+
+// sizeOfEquivalentRange is the width of the sub-bucket that holds v.
+func (h *Histogram) sizeOfEquivalentRange(v int64) int64 {
+	b := h.bucketIndex(v)
+	sub := h.subBucketIndex(v, b)
+	if sub >= h.subBucketCount {
+		b++
+	}
+	return 1 << (h.unitMagnitude + uint(b))
+}
+
+// LowestEquivalent returns the smallest value counted in the same slot as v.
+func (h *Histogram) LowestEquivalent(v int64) int64 {
+	b := h.bucketIndex(v)
+	return h.subBucketIndex(v, b) << (uint(b) + h.unitMagnitude)
+}
+
+// HighestEquivalent returns the largest value counted in the same slot as v.
+func (h *Histogram) HighestEquivalent(v int64) int64 {
+	return h.LowestEquivalent(v) + h.sizeOfEquivalentRange(v) - 1
+}
+
+// medianEquivalent is the middle of v's slot, used for mean and merging.
+func (h *Histogram) medianEquivalent(v int64) int64 {
+	return h.LowestEquivalent(v) + h.sizeOfEquivalentRange(v)>>1
+}
+
+// Record counts one occurrence of v. It is safe for concurrent use.
+func (h *Histogram) Record(v int64) error { return h.RecordN(v, 1) }
+
+// RecordN counts n occurrences of v.
+func (h *Histogram) RecordN(v, n int64) error {
+	if v < 0 || v > h.highest {
+		return ErrOutOfRange
+	}
+	i := h.indexOf(v)
+	if i < 0 || i >= len(h.counts) {
+		return ErrOutOfRange
+	}
+	h.counts[i].Add(n)
+	h.total.Add(n)
+	for {
+		m := h.min.Load()
+		if v >= m || h.min.CompareAndSwap(m, v) {
+			break
+		}
+	}
+	for {
+		m := h.max.Load()
+		if v <= m || h.max.CompareAndSwap(m, v) {
+			break
+		}
+	}
+	return nil
+}
+
+// RecordCorrected records v and, when v is longer than expectedInterval,
+// the samples a load generator with that fixed send interval would have
+// taken while it was stalled: v-interval, v-2*interval, ... down to
+// interval. Without them a closed-loop benchmark under-reports the tail
+// ("coordinated omission"): one stall of 1s hides every request that would
+// have been sent during it.
+func (h *Histogram) RecordCorrected(v, expectedInterval int64) error {
+	if err := h.Record(v); err != nil {
+		return err
+	}
+	if expectedInterval <= 0 {
+		return nil
+	}
+	for missing := v - expectedInterval; missing >= expectedInterval; missing -= expectedInterval {
+		if err := h.Record(missing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset clears all counts. Recording concurrently with Reset may leave some
+// of those records counted.
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.total.Store(0)
+	h.min.Store(math.MaxInt64)
+	h.max.Store(0)
+}
@@ -0,0 +1,213 @@
+package hdr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Merge adds the counts of other into h. Histograms with the same layout
+// (lowest, sigfigs and a covering highest) are merged slot by slot; otherwise
+// each of other's slots is re-recorded at its middle value, which can cost
+// precision. Values other holds above h's range make Merge fail with
+// ErrOutOfRange after merging everything else.
+func (h *Histogram) Merge(other *Histogram) error {
+	sameLayout := h.unitMagnitude == other.unitMagnitude &&
+		h.subBucketHalfCountMagnitude == other.subBucketHalfCountMagnitude &&
+		h.highest >= other.highest && len(h.counts) >= len(other.counts)
+	var dropped int64
+	for i := range other.counts {
+		c := other.counts[i].Load()
+		if c == 0 {
+			continue
+		}
+		if sameLayout {
+			h.counts[i].Add(c)
+			h.total.Add(c)
+			continue
+		}
+		if err := h.RecordN(other.medianEquivalent(other.valueFromIndex(i)), c); err != nil {
+			dropped += c
+		}
+	}
+	if sameLayout && other.TotalCount() > 0 {
+		h.updateMinMax(other.min.Load(), other.max.Load())
+	}
+	if dropped > 0 {
+		return fmt.Errorf("%w: %d values above %d not merged", ErrOutOfRange, dropped, h.highest)
+	}
+	return nil
+}
+
+func (h *Histogram) updateMinMax(lo, hi int64) {
+	for {
+		m := h.min.Load()
+		if lo >= m || h.min.CompareAndSwap(m, lo) {
+			break
+		}
+	}
+	for {
+		m := h.max.Load()
+		if hi <= m || h.max.CompareAndSwap(m, hi) {
+			break
+		}
+	}
+}
+
+// Encoding
+//
+//	magic   "HDR1"
+//	header  uvarint lowest, uvarint highest, uvarint sigfigs, uvarint min, uvarint max
+//	counts  zigzag varints: a positive n is a count, a negative -k skips k
+//	        empty slots, so long runs of zeros take a byte or two
+//
+// The whole payload after the magic is DEFLATE compressed.
+
+var magic = []byte("HDR1")
+
+// maxDecodeSlots caps the counts array UnmarshalBinary allocates, 32 MiB.
+// It admits every layout with up to 4 significant figures, and 5 figures
+// for ranges up to 10^14 times the lowest value.
+const maxDecodeSlots = 1 << 22
+
+// ErrBadEncoding is returned when decoding data that is not a histogram.
+var ErrBadEncoding = errors.New("hdr: bad encoding")
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (h *Histogram) MarshalBinary() ([]byte, error) {
+	var raw []byte
+	raw = binary.AppendUvarint(raw, uint64(h.lowest))
+	raw = binary.AppendUvarint(raw, uint64(h.highest))
+	raw = binary.AppendUvarint(raw, uint64(h.sigfigs))
+	raw = binary.AppendUvarint(raw, uint64(h.Min()))
+	raw = binary.AppendUvarint(raw, uint64(h.max.Load()))
+	zeros := int64(0)
+	for i := range h.counts {
+		c := h.counts[i].Load()
+		if c == 0 {
+			zeros++
+			continue
+		}
+		if zeros > 0 {
+			raw = binary.AppendVarint(raw, -zeros)
+			zeros = 0
+		}
+		raw = binary.AppendVarint(raw, c)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(magic)
+	zw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	zw.Write(raw)
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the
+// contents and layout of h. Layouts too large to allocate on the word of a
+// short header, such as 5 significant figures over the whole int64 range,
+// are rejected.
+func (h *Histogram) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, magic) {
+		return ErrBadEncoding
+	}
+	zr := bufio.NewReader(flate.NewReader(bytes.NewReader(data[len(magic):])))
+	var hdr [5]uint64
+	var err error
+	for i := range hdr {
+		if hdr[i], err = binary.ReadUvarint(zr); err != nil {
+			return fmt.Errorf("%w: header: %v", ErrBadEncoding, err)
+		}
+	}
+	if hdr[0] > math.MaxInt64 || hdr[1] > math.MaxInt64 || hdr[2] > 5 {
+		return ErrBadEncoding
+	}
+	nh, err := layout(int64(hdr[0]), int64(hdr[1]), int(hdr[2]))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadEncoding, err)
+	}
+	// The header alone decides how much memory the counts take; a few
+	// bytes must not be able to ask for gigabytes.
+	if nh.slots() > maxDecodeSlots {
+		return fmt.Errorf("%w: layout of %d slots exceeds the limit of %d", ErrBadEncoding, nh.slots(), maxDecodeSlots)
+	}
+	nh.counts = make([]atomic.Int64, nh.slots())
+	// Every slot takes at most a skip and a count, so a valid payload is
+	// bounded by the layout; read one byte more to notice anything longer
+	// without inflating all of it.
+	limit := int64(2 * binary.MaxVarintLen64 * len(nh.counts))
+	raw, err := io.ReadAll(io.LimitReader(zr, limit+1))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadEncoding, err)
+	}
+	if int64(len(raw)) > limit {
+		return fmt.Errorf("%w: counts longer than the layout allows", ErrBadEncoding)
+	}
+	r := bytes.NewReader(raw)
+	i := 0
+	for r.Len() > 0 {
+		v, err := binary.ReadVarint(r)
+		if err != nil {
+			return fmt.Errorf("%w: counts: %v", ErrBadEncoding, err)
+		}
+		if v < 0 {
+			if v == math.MinInt64 || -v > int64(len(nh.counts)-i) {
+				return fmt.Errorf("%w: skip past the last slot", ErrBadEncoding)
+			}
+			i += int(-v)
+			continue
+		}
+		if i >= len(nh.counts) {
+			return fmt.Errorf("%w: more counts than slots", ErrBadEncoding)
+		}
+		nh.counts[i].Store(v)
+		nh.total.Add(v)
+		i++
+	}
+	if nh.total.Load() > 0 {
+		nh.min.Store(int64(hdr[3]))
+		nh.max.Store(int64(hdr[4]))
+	}
+
+	h.lowest, h.highest, h.sigfigs = nh.lowest, nh.highest, nh.sigfigs
+	h.unitMagnitude, h.subBucketHalfCountMagnitude = nh.unitMagnitude, nh.subBucketHalfCountMagnitude
+	h.subBucketCount, h.subBucketHalfCount, h.subBucketMask = nh.subBucketCount, nh.subBucketHalfCount, nh.subBucketMask
+	h.bucketCount = nh.bucketCount
+	h.counts = nh.counts
+	h.total.Store(nh.total.Load())
+	h.min.Store(nh.min.Load())
+	h.max.Store(nh.max.Load())
+	return nil
+}
+
+// Encode returns the binary form as standard base64, suitable for logs,
+// headers or JSON.
+func (h *Histogram) Encode() (string, error) {
+	b, err := h.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Decode parses the output of Encode.
+func Decode(s string) (*Histogram, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadEncoding, err)
+	}
+	h := new(Histogram)
+	if err := h.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
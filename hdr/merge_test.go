@@ -0,0 +1,93 @@
+package hdr
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// encode builds an encoding by hand from header values and count varints.
+func encode(hdr [5]uint64, counts ...int64) []byte {
+	var raw []byte
+	for _, v := range hdr {
+		raw = binary.AppendUvarint(raw, v)
+	}
+	for _, c := range counts {
+		raw = binary.AppendVarint(raw, c)
+	}
+	var buf bytes.Buffer
+	buf.Write(magic)
+	zw, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	zw.Write(raw)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestUnmarshalRejects(t *testing.T) {
+	h, _ := New(1, 1000, 2)
+	slots := int64(len(h.counts))
+	hdr := [5]uint64{1, 1000, 2, 1, 1}
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"min int skip", encode(hdr, math.MinInt64, 1)},
+		{"skip past the end", encode(hdr, -slots-1)},
+		{"skip then count past the end", encode(hdr, -slots, 1)},
+		{"too many counts", encode(hdr, make([]int64, slots+1)...)},
+		{"too long", encode(hdr, make([]int64, 3*slots)...)},
+		{"huge layout", encode([5]uint64{1, math.MaxInt64, 5, 1, 1})},
+	} {
+		var got Histogram
+		if err := got.UnmarshalBinary(tc.data); !errors.Is(err, ErrBadEncoding) {
+			t.Errorf("%s: err = %v, want ErrBadEncoding", tc.name, err)
+		}
+	}
+}
+
+func TestMergeNeedsCoveringRange(t *testing.T) {
+	small, _ := New(1, 1500, 2)
+	big, _ := New(1, 2000, 2)
+	big.Record(1800)
+	// Same lowest, sigfigs and number of slots, so the slots line up, but
+	// small cannot hold what big recorded.
+	if err := small.Merge(big); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Merge into a narrower range: err = %v, want ErrOutOfRange", err)
+	}
+	if small.TotalCount() != 0 {
+		t.Errorf("narrower histogram counted %d values", small.TotalCount())
+	}
+	if err := big.Merge(small); err != nil {
+		t.Errorf("Merge into a wider range: %v", err)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	h, _ := New(1, 3_600_000, 3)
+	for _, v := range []int64{1, 5, 5, 900, 3_600_000} {
+		h.Record(v)
+	}
+	b, _ := h.MarshalBinary()
+	f.Add(b)
+	f.Add(encode([5]uint64{1, 1000, 2, 1, 1}, math.MinInt64))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var got Histogram
+		if err := got.UnmarshalBinary(data); err != nil {
+			return
+		}
+		again, err := got.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Histogram
+		if err := back.UnmarshalBinary(again); err != nil {
+			t.Fatalf("re-encoding does not decode: %v", err)
+		}
+		if back.TotalCount() != got.TotalCount() {
+			t.Errorf("total %d after round trip, want %d", back.TotalCount(), got.TotalCount())
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package hdr
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// Queries read the counts one by one without stopping writers, so results
+// taken while other goroutines record are approximate: they may include some
+// of the concurrent records and not others.
+
+// TotalCount returns the number of recorded values.
+func (h *Histogram) TotalCount() int64 { return h.total.Load() }
+
+// Min returns the smallest recorded value, rounded down to its slot, or 0
+// when the histogram is empty.
+func (h *Histogram) Min() int64 {
+	m := h.min.Load()
+	if m == math.MaxInt64 {
+		return 0
+	}
+	return h.LowestEquivalent(m)
+}
+
+// Max returns the largest recorded value, rounded up to its slot.
+func (h *Histogram) Max() int64 {
+	m := h.max.Load()
+	if m == 0 {
+		return 0
+	}
+	return h.HighestEquivalent(m)
+}
+
+// Mean returns the average of the recorded values, each taken as the middle
+// of its slot.
+func (h *Histogram) Mean() float64 {
+	var total, sum float64
+	for i := range h.counts {
+		if c := h.counts[i].Load(); c != 0 {
+			total += float64(c)
+			sum += float64(c) * float64(h.medianEquivalent(h.valueFromIndex(i)))
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// StdDev returns the population standard deviation.
+func (h *Histogram) StdDev() float64 {
+	mean := h.Mean()
+	var total, sq float64
+	for i := range h.counts {
+		if c := h.counts[i].Load(); c != 0 {
+			d := float64(h.medianEquivalent(h.valueFromIndex(i))) - mean
+			total += float64(c)
+			sq += float64(c) * d * d
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(sq / total)
+}
+
+// ValueAt returns the value at percentile p (0..100): the highest value in
+// the slot that contains the p-th percentile, so the result is never lower
+// than the true percentile and at most one slot width above it.
+func (h *Histogram) ValueAt(p float64) int64 {
+	return h.ValuesAt(p)[0]
+}
+
+// ValuesAt returns the values at several percentiles in one pass.
+func (h *Histogram) ValuesAt(ps ...float64) []int64 {
+	out := make([]int64, len(ps))
+	total := h.total.Load()
+	if total == 0 {
+		return out
+	}
+	// Answer in increasing order of percentile, then put back in place.
+	order := make([]int, len(ps))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		switch {
+		case ps[a] < ps[b]:
+			return -1
+		case ps[a] > ps[b]:
+			return 1
+		}
+		return 0
+	})
+	var cum int64
+	i := 0
+	for _, k := range order {
+		p := min(max(ps[k], 0), 100)
+		want := max(int64(math.Ceil(p/100*float64(total))), 1)
+		for cum < want && i < len(h.counts) {
+			cum += h.counts[i].Load()
+			i++
+		}
+		if i == 0 {
+			out[k] = 0
+			continue
+		}
+		out[k] = h.HighestEquivalent(h.valueFromIndex(i - 1))
+	}
+	return out
+}
+
+// Bar is one non-empty slot of the histogram.
+type Bar struct {
+	From, To int64 // inclusive value range of the slot
+	Count    int64
+}
+
+// Bars returns the non-empty slots in increasing order.
+func (h *Histogram) Bars() []Bar {
+	var out []Bar
+	for i := range h.counts {
+		if c := h.counts[i].Load(); c != 0 {
+			v := h.valueFromIndex(i)
+			out = append(out, Bar{v, h.HighestEquivalent(v), c})
+		}
+	}
+	return out
+}
+
+// WritePercentiles prints the distribution in the layout of HdrHistogram's
+// .hgrm files, which its online plotter reads: value, percentile, total count
+// and 1/(1-percentile), with ticks getting denser towards 100%. Values are
+// divided by scale, e.g. 1e6 to print values recorded in nanoseconds as
+// milliseconds.
+func (h *Histogram) WritePercentiles(w io.Writer, ticksPerHalf int, scale float64) error {
+	if ticksPerHalf <= 0 {
+		ticksPerHalf = 5
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	total := h.TotalCount()
+	fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+	if total > 0 {
+		// Percentile ticks: each halving of the distance to 100 gets
+		// ticksPerHalf steps.
+		for half := 0; half < 30; half++ {
+			remaining := 100 / math.Pow(2, float64(half))
+			step := remaining / 2 / float64(ticksPerHalf)
+			done := false
+			for t := 0; t < ticksPerHalf; t++ {
+				p := 100 - remaining + float64(t)*step
+				v := h.ValueAt(p)
+				count := h.countAtOrBelow(v)
+				frac := float64(count) / float64(total)
+				inv := "inf"
+				if frac < 1 {
+					inv = fmt.Sprintf("%.2f", 1/(1-p/100))
+				}
+				fmt.Fprintf(w, "%12.3f %14.12f %10d %14s\n", float64(v)/scale, p/100, count, inv)
+				if count == total {
+					done = true
+					break
+				}
+			}
+			if done {
+				break
+			}
+		}
+		fmt.Fprintf(w, "%12.3f %14.12f %10d %14s\n", float64(h.Max())/scale, 1.0, total, "")
+	}
+	_, err := fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n#[Max     = %12.3f, Total count    = %12d]\n#[Buckets = %12d, SubBuckets     = %12d]\n",
+		h.Mean()/scale, h.StdDev()/scale, float64(h.Max())/scale, total, h.bucketCount, h.subBucketCount)
+	return err
+}
+
+func (h *Histogram) countAtOrBelow(v int64) int64 {
+	var n int64
+	last := h.indexOf(min(v, h.highest))
+	for i := 0; i <= last && i < len(h.counts); i++ {
+		n += h.counts[i].Load()
+	}
+	return n
+}
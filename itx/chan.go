@@ -0,0 +1,110 @@
+package itx
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// FromChan yields values received from ch until it is closed. Breaking out of
+// the loop leaves the remaining values in the channel; as in
+// concurrency/range_and_close.go, only the sender closes ch.
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ToChan runs seq in a new goroutine and sends its values on the returned
+// channel, which has capacity buf and is closed when seq ends. Cancelling ctx
+// stops the goroutine at its next send, so a receiver that stops early must
+// cancel ctx or the goroutine leaks blocked on the send.
+func ToChan[T any](ctx context.Context, seq iter.Seq[T], buf int) <-chan T {
+	ch := make(chan T, buf)
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// MapOrdered applies f to the values of seq on up to workers goroutines and
+// yields the results in input order. A slow value holds back the ones after
+// it, but at most workers values are being processed or waiting to be yielded
+// at any time, so memory stays bounded on infinite sequences.
+//
+// seq itself is consumed from a separate goroutine. When the caller breaks,
+// MapOrdered waits for in-flight calls of f to finish and returns; it does
+// not wait for seq, which may be blocked, say in FromChan on a channel no
+// one sends to. That goroutine starts no more calls of f and exits the next
+// time seq produces a value or ends, so a sender feeding MapOrdered through
+// FromChan should still close its channel.
+func MapOrdered[T, U any](seq iter.Seq[T], workers int, f func(T) U) iter.Seq[U] {
+	if workers < 1 {
+		workers = 1
+	}
+	return func(yield func(U) bool) {
+		done := make(chan struct{})
+		sem := make(chan struct{}, workers)
+		// pending carries one result channel per input value, in input order.
+		pending := make(chan chan U, workers)
+		var (
+			mu      sync.Mutex
+			stopped bool // set on break, after which no worker may start
+			calls   sync.WaitGroup
+		)
+
+		go func() {
+			defer close(pending)
+			for v := range seq {
+				select {
+				case sem <- struct{}{}:
+				case <-done:
+					return
+				}
+				mu.Lock()
+				if stopped {
+					mu.Unlock()
+					return
+				}
+				calls.Add(1)
+				mu.Unlock()
+				res := make(chan U, 1)
+				go func() {
+					defer calls.Done()
+					res <- f(v)
+				}()
+				select {
+				case pending <- res:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		defer func() {
+			mu.Lock()
+			stopped = true
+			mu.Unlock()
+			close(done)
+			calls.Wait()
+		}()
+		for res := range pending {
+			u := <-res
+			<-sem
+			if !yield(u) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package itx_test
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang/itx"
+)
+
+// finish fails the test if f does not return within a second.
+func finish(t *testing.T, what string, f func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("%s did not return", what)
+	}
+}
+
+func TestFromChanBreakLeavesValues(t *testing.T) {
+	ch := make(chan int, 4)
+	for i := range 4 {
+		ch <- i
+	}
+	close(ch)
+	for v := range itx.FromChan(ch) {
+		if v == 1 {
+			break
+		}
+	}
+	if got := slices.Collect(itx.FromChan(ch)); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("left in channel: %v, want [2 3]", got)
+	}
+}
+
+func TestToChanStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	seq := func(yield func(int) bool) {
+		defer close(exited)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	ch := itx.ToChan(ctx, seq, 0)
+	if v := <-ch; v != 0 {
+		t.Fatalf("first value %d, want 0", v)
+	}
+	cancel()
+	finish(t, "ToChan's goroutine", func() { <-exited })
+	for range ch {
+	}
+}
+
+func TestMapOrderedKeepsOrder(t *testing.T) {
+	in := []int{5, 1, 4, 2, 3}
+	got := slices.Collect(itx.MapOrdered(slices.Values(in), 3, func(v int) int {
+		time.Sleep(time.Duration(v) * time.Millisecond)
+		return v * 2
+	}))
+	if want := []int{10, 2, 8, 4, 6}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestMapOrderedBreakWithBlockedSource(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		ch <- 1
+		ch <- 2
+	}()
+	var got []int
+	finish(t, "MapOrdered", func() {
+		for v := range itx.MapOrdered(itx.FromChan(ch), 2, func(v int) int { return v }) {
+			got = append(got, v)
+			if len(got) == 2 {
+				break
+			}
+		}
+	})
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("got %v, want [1 2]", got)
+	}
+	close(ch)
+}
+
+func TestMapOrderedBreakWaitsForCalls(t *testing.T) {
+	var running, started atomic.Int64
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	for v := range itx.MapOrdered(seq, 4, func(v int) int {
+		started.Add(1)
+		running.Add(1)
+		defer running.Add(-1)
+		time.Sleep(time.Millisecond)
+		return v
+	}) {
+		if v == 20 {
+			break
+		}
+	}
+	if n := running.Load(); n != 0 {
+		t.Fatalf("%d calls of f still running after break", n)
+	}
+	n := started.Load()
+	time.Sleep(10 * time.Millisecond)
+	if m := started.Load(); m != n {
+		t.Fatalf("%d calls of f started after break", m-n)
+	}
+}
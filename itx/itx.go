@@ -0,0 +1,248 @@
+/*
+Package itx provides combinators over Go 1.23 range-over-func iterators.
+
+slice/range.go ranges over a slice and concurrency/range_and_close.go ranges
+over a channel; both sources are finished before the loop starts or are fed by
+a goroutine. An iter.Seq is just a function that calls yield for each value, so
+sequences can be built lazily, chained and stopped part way:
+
+	evens := itx.Filter(naturals, func(n int) bool { return n%2 == 0 })
+	for sq := range itx.Take(itx.Map(evens, square), 5) { ... }
+
+Nothing runs until the final range loop pulls values. Every combinator here
+stops calling its source as soon as a yield returns false, so a break in the
+outermost loop propagates back to the source and infinite sequences are safe.
+*/
+package itx
+
+import "iter"
+
+// Map yields f(v) for every v in seq.
+func Map[T, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Map2 yields f(k, v) for every pair in seq.
+func Map2[K, V, K2, V2 any](seq iter.Seq2[K, V], f func(K, V) (K2, V2)) iter.Seq2[K2, V2] {
+	return func(yield func(K2, V2) bool) {
+		for k, v := range seq {
+			if !yield(f(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter yields the values of seq for which keep returns true.
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Filter2 yields the pairs of seq for which keep returns true.
+func Filter2[K, V any](seq iter.Seq2[K, V], keep func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if keep(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce folds seq into a single value, starting from init.
+func Reduce[T, A any](seq iter.Seq[T], init A, f func(A, T) A) A {
+	acc := init
+	for v := range seq {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Enumerate pairs each value of seq with its position.
+func Enumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for v := range seq {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Keys yields the first element of every pair in seq.
+func Keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values yields the second element of every pair in seq.
+func Values[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Zip pairs up the values of a and b and stops at the end of the shorter one.
+// b is consumed with iter.Pull, so it is stopped (and its deferred cleanups
+// run) even when a ends first or the caller breaks.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Take yields at most the first n values of seq. It does not ask seq for the
+// value after the n-th.
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			if i++; i == n {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile yields values of seq until keep first returns false.
+func TakeWhile[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !keep(v) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Skip drops the first n values of seq and yields the rest.
+func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		for v := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk splits seq into slices of n values; the last one may be shorter.
+// Each chunk is a new slice the caller may keep. Chunk panics if n < 1.
+func Chunk[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("itx: Chunk size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		buf := make([]T, 0, n)
+		for v := range seq {
+			buf = append(buf, v)
+			if len(buf) == n {
+				if !yield(buf) {
+					return
+				}
+				buf = make([]T, 0, n)
+			}
+		}
+		if len(buf) > 0 {
+			yield(buf)
+		}
+	}
+}
+
+// Window yields every run of n consecutive values of seq: [v0 v1 v2],
+// [v1 v2 v3], ... A sequence shorter than n yields nothing. Each window is a
+// new slice the caller may keep. Window panics if n < 1.
+func Window[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("itx: Window size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		// ring holds the last n values; ring[start] is the oldest.
+		ring := make([]T, 0, n)
+		start := 0
+		for v := range seq {
+			if len(ring) < n {
+				ring = append(ring, v)
+				if len(ring) < n {
+					continue
+				}
+			} else {
+				ring[start] = v
+				start = (start + 1) % n
+			}
+			w := make([]T, 0, n)
+			w = append(w, ring[start:]...)
+			w = append(w, ring[:start]...)
+			if !yield(w) {
+				return
+			}
+		}
+	}
+}
+
+// Flatten yields the values of each inner sequence in turn.
+func Flatten[T any](seqs iter.Seq[iter.Seq[T]]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for inner := range seqs {
+			for v := range inner {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Concat yields the values of each sequence in turn.
+func Concat[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, s := range seqs {
+			for v := range s {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Package afterloop reports time.After in a select inside a loop when another
+case of the select goes round the loop again.
+
+Leak pattern #7 in advance-concept/3.Goroutine Internals/3.Go_leak_pattern.md:
+
+	for {
+		select {
+		case <-work:
+		case <-time.After(time.Second):
+		}
+	}
+
+Every iteration creates a timer and a channel. When work wins, which under
+load is nearly every time, that timer is abandoned and a new one made on the
+next pass. Before Go 1.23 the abandoned timers stayed in the runtime's heap
+until they fired, so a busy loop with a long timeout held thousands of them;
+since 1.23 an unreferenced timer can be collected, but each iteration still
+allocates one. It is also usually not what was meant: the timeout restarts
+every time work arrives, so it measures idle time, not total time.
+
+The fix is one timer outside the loop, Reset where the timeout should
+restart, or a time.Ticker for a period.
+
+A select whose other cases all return or otherwise leave the loop, such as
+the reconnect delay in sse/client.go, makes one timer per real wait and is
+not reported.
+*/
+package afterloop
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"golang/lint/internal/lintutil"
+)
+
+const Doc = `report time.After in a select in a loop
+
+When another case of the select wins and the loop goes round again, the
+timer time.After made is abandoned and a new one allocated; use one
+time.Timer created outside the loop.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "afterloop",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.WithStack([]ast.Node{(*ast.SelectStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		loop := lintutil.EnclosingLoop(stack)
+		if loop == nil {
+			return true
+		}
+		label := loopLabel(pass, stack, loop)
+		sel := n.(*ast.SelectStmt)
+		var after []*ast.CallExpr
+		loops := false
+		for _, c := range sel.Body.List {
+			cc := c.(*ast.CommClause)
+			if call := afterCall(pass, cc.Comm); call != nil {
+				after = append(after, call)
+			} else if !leaves(pass, cc.Body, label) {
+				loops = true
+			}
+		}
+		if loops {
+			for _, call := range after {
+				pass.ReportRangef(call, "time.After in a loop allocates a timer every iteration and abandons it when another case wins; create a time.Timer before the loop and Reset it")
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// afterCall returns the time.After call received from by comm, a case of a
+// select, if there is one.
+func afterCall(pass *analysis.Pass, comm ast.Stmt) *ast.CallExpr {
+	var x ast.Expr
+	switch s := comm.(type) {
+	case *ast.ExprStmt:
+		x = s.X
+	case *ast.AssignStmt:
+		x = s.Rhs[0]
+	default:
+		return nil // a send, or default
+	}
+	recv, ok := ast.Unparen(x).(*ast.UnaryExpr)
+	if !ok || recv.Op != token.ARROW {
+		return nil
+	}
+	call, ok := ast.Unparen(recv.X).(*ast.CallExpr)
+	if !ok || !lintutil.IsFunc(pass.TypesInfo, call, "time", "After") {
+		return nil
+	}
+	return call
+}
+
+// loopLabel returns the label of loop, an element of stack, or nil.
+func loopLabel(pass *analysis.Pass, stack []ast.Node, loop ast.Stmt) types.Object {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i] == loop {
+			if ls, ok := stack[i-1].(*ast.LabeledStmt); ok {
+				return pass.TypesInfo.Defs[ls.Label]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// leaves reports whether a case body ends by leaving the loop labelled
+// label: a return, a labelled break (an unlabelled one would only leave the
+// select), a continue naming an outer loop, a goto, or a call that does not
+// return.
+func leaves(pass *analysis.Pass, body []ast.Stmt, label types.Object) bool {
+	if len(body) == 0 {
+		return false
+	}
+	switch s := body[len(body)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		switch s.Tok {
+		case token.GOTO:
+			return true
+		case token.BREAK:
+			return s.Label != nil
+		case token.CONTINUE:
+			return s.Label != nil && pass.TypesInfo.Uses[s.Label] != label
+		}
+		return false
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && id.Name == "panic" && pass.TypesInfo.Uses[id] == types.Universe.Lookup("panic") {
+			return true
+		}
+		return lintutil.IsFunc(pass.TypesInfo, call, "os", "Exit") ||
+			lintutil.IsFunc(pass.TypesInfo, call, "log", "Fatal") ||
+			lintutil.IsFunc(pass.TypesInfo, call, "log", "Fatalf") ||
+			lintutil.IsFunc(pass.TypesInfo, call, "log", "Fatalln")
+	case *ast.BlockStmt:
+		return leaves(pass, s.List, label)
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package afterloop_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/afterloop"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), afterloop.Analyzer, "afterloop")
+}
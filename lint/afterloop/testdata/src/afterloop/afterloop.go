@@ -0,0 +1,127 @@
+package afterloop
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func leakPattern(work <-chan int) {
+	for {
+		select {
+		case w := <-work:
+			fmt.Println(w)
+		case <-time.After(time.Second): // want `time.After in a loop allocates a timer every iteration`
+			fmt.Println("idle")
+		}
+	}
+}
+
+func breakOnlyLeavesSelect(work <-chan int) {
+	for range 10 {
+		select {
+		case <-work:
+			break
+		case t := <-(time.After(time.Millisecond)): // want `time.After in a loop`
+			fmt.Println(t)
+			return
+		}
+	}
+}
+
+// The reconnect delay of sse/client.go: the only other case returns, so
+// each timer is waited for or the function is done.
+func reconnect(ctx context.Context, dial func() error) error {
+	for {
+		if err := dial(); err == nil {
+			return nil
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func labelledBreak(work <-chan int) {
+loop:
+	for {
+		select {
+		case <-work:
+			break loop
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Continuing the outer loop leaves the inner one.
+func labelledContinue(batches [][]int, work <-chan int) {
+outer:
+	for range batches {
+		for {
+			select {
+			case <-work:
+				continue outer
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// Continuing its own loop goes round again like no branch at all.
+func continueSameLoop(work <-chan int) {
+loop:
+	for {
+		select {
+		case <-work:
+			continue loop
+		case <-time.After(time.Second): // want `time.After in a loop`
+			return
+		}
+	}
+}
+
+func panics(work <-chan int) {
+	for {
+		select {
+		case <-work:
+			panic("unexpected work")
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// One timer for the whole loop is the fix.
+func timer(work <-chan int) {
+	t := time.NewTimer(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-work:
+			t.Reset(time.Second)
+		case <-t.C:
+			return
+		}
+	}
+}
+
+// Outside a loop time.After is fine.
+func once(work <-chan int) {
+	select {
+	case <-work:
+	case <-time.After(time.Second):
+	}
+}
+
+// A literal in the loop is its own function.
+func spawn(work <-chan int) {
+	for range 3 {
+		go func() {
+			select {
+			case <-work:
+			case <-time.After(time.Second):
+			}
+		}()
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Package crossunlock reports a mutex locked by one goroutine and unlocked by
+a goroutine it starts.
+
+goroutin/mutex.go runs
+
+	mutex.Lock() // lock to read counter variable
+	go printCounter()
+	mutex.Lock() // lock to incr counter variable
+	go incrCounter()
+
+and printCounter and incrCounter end with mutex.Unlock(). The Mutex is used
+as a one-slot semaphore: main's second Lock blocks until printCounter has
+released the first. sync.Mutex allows this, as its documentation says a
+locked Mutex is not tied to a goroutine, but nothing in printCounter says
+which Lock its Unlock pairs with, the race detector cannot see the hand-off
+as a critical section, and a printCounter that returns early without
+unlocking deadlocks main. A channel or a WaitGroup says the same thing
+directly.
+
+The analyzer follows go statements whose function is a literal or a
+function or method declared in the same package. It reports an Unlock or
+RUnlock in that function of a sync.Mutex or sync.RWMutex that the function
+does not lock itself and that the starting function locked before the go
+statement. Mutexes are matched by variable or struct field, so c.mu and
+d.mu count as the same mutex when c and d have the same type.
+*/
+package crossunlock
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"golang/lint/internal/lintutil"
+)
+
+const Doc = `report mutexes unlocked by a goroutine other than the one that locked them
+
+A Lock in one function followed by go f(), where f calls Unlock, hands the
+lock between goroutines; use a channel or a sync.WaitGroup to say that.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "crossunlock",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// summary is the Lock and Unlock calls made directly by one function body.
+type summary struct {
+	locks   map[types.Object][]*ast.CallExpr
+	unlocks map[types.Object][]*ast.CallExpr
+	gos     []*ast.GoStmt
+}
+
+func summarize(info *types.Info, body *ast.BlockStmt) *summary {
+	s := &summary{
+		locks:   make(map[types.Object][]*ast.CallExpr),
+		unlocks: make(map[types.Object][]*ast.CallExpr),
+	}
+	lintutil.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			s.gos = append(s.gos, n)
+		case *ast.CallExpr:
+			sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			obj := lintutil.Object(info, sel.X)
+			if obj == nil {
+				break
+			}
+			switch {
+			case isMutex(info, n, "Lock", "RLock"):
+				s.locks[obj] = append(s.locks[obj], n)
+			case isMutex(info, n, "Unlock", "RUnlock"):
+				s.unlocks[obj] = append(s.unlocks[obj], n)
+			}
+		}
+		return true
+	})
+	return s
+}
+
+func isMutex(info *types.Info, call *ast.CallExpr, names ...string) bool {
+	return lintutil.IsMethod(info, call, "sync", "Mutex", names...) ||
+		lintutil.IsMethod(info, call, "sync", "RWMutex", names...)
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	decls := make(map[*types.Func]*summary)
+	lits := make(map[*ast.FuncLit]*summary)
+	var bodies []*summary
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body == nil {
+				return
+			}
+			s := summarize(pass.TypesInfo, n.Body)
+			if fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+				decls[fn] = s
+			}
+			bodies = append(bodies, s)
+		case *ast.FuncLit:
+			s := summarize(pass.TypesInfo, n.Body)
+			lits[n] = s
+			bodies = append(bodies, s)
+		}
+	})
+
+	reported := make(map[*ast.CallExpr]bool)
+	for _, parent := range bodies {
+		for _, g := range parent.gos {
+			var child *summary
+			if lit, ok := ast.Unparen(g.Call.Fun).(*ast.FuncLit); ok {
+				child = lits[lit]
+			} else if fn := typeutil.StaticCallee(pass.TypesInfo, g.Call); fn != nil {
+				child = decls[fn.Origin()]
+			}
+			if child == nil {
+				continue
+			}
+			for obj, unlocks := range child.unlocks {
+				if len(child.locks[obj]) > 0 {
+					continue // the goroutine takes the lock it releases
+				}
+				lock := lastBefore(parent.locks[obj], g)
+				if lock == nil {
+					continue
+				}
+				for _, u := range unlocks {
+					if reported[u] {
+						continue
+					}
+					reported[u] = true
+					pass.Report(analysis.Diagnostic{
+						Pos: u.Pos(),
+						End: u.End(),
+						Message: fmt.Sprintf("%s releases the lock taken on line %d by the goroutine that started this one on line %d; lock and unlock in one goroutine and hand over with a channel or sync.WaitGroup",
+							types.ExprString(u.Fun), pass.Fset.Position(lock.Pos()).Line, pass.Fset.Position(g.Pos()).Line),
+						Related: []analysis.RelatedInformation{
+							{Pos: lock.Pos(), End: lock.End(), Message: "locked here"},
+							{Pos: g.Pos(), End: g.End(), Message: "goroutine started here"},
+						},
+					})
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// lastBefore returns the last of calls that comes before the go statement.
+func lastBefore(calls []*ast.CallExpr, g *ast.GoStmt) *ast.CallExpr {
+	var last *ast.CallExpr
+	for _, c := range calls {
+		if c.Pos() < g.Pos() {
+			last = c
+		}
+	}
+	return last
+}
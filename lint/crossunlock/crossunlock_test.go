@@ -0,0 +1,13 @@
+package crossunlock_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/crossunlock"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), crossunlock.Analyzer, "crossunlock")
+}
@@ -0,0 +1,80 @@
+package crossunlock
+
+import (
+	"fmt"
+	"sync"
+)
+
+// goroutin/mutex.go
+var wg = sync.WaitGroup{}
+var counter = 0
+var mutex = sync.Mutex{}
+
+func lesson() {
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		mutex.Lock()
+		go printCounter()
+		mutex.Lock()
+		go incrCounter()
+	}
+	wg.Wait()
+}
+
+func printCounter() {
+	fmt.Println(counter)
+	mutex.Unlock() // want `mutex.Unlock releases the lock taken on line 16 by the goroutine that started this one on line 17`
+	wg.Done()
+}
+
+func incrCounter() {
+	counter++
+	mutex.Unlock() // want `mutex.Unlock releases the lock taken on line 18 by the goroutine that started this one on line 19`
+	wg.Done()
+}
+
+type cache struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+func (c *cache) refresh(load func() map[string]string) {
+	c.mu.Lock()
+	go func() {
+		defer c.mu.Unlock() // want `c.mu.Unlock releases the lock taken on line 42`
+		c.m = load()
+	}()
+}
+
+func (c *cache) read(key string) string {
+	c.mu.RLock()
+	go c.release()
+	return c.m[key]
+}
+
+func (c *cache) release() {
+	c.mu.RUnlock() // want `c.mu.RUnlock releases the lock taken on line 50`
+}
+
+// The goroutine takes and releases the lock itself.
+func (c *cache) set(key, value string) {
+	go func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.m[key] = value
+	}()
+}
+
+// Locked and unlocked by the parent around starting a goroutine.
+func (c *cache) start(work func()) {
+	c.mu.Lock()
+	go work()
+	c.mu.Unlock()
+}
+
+// Unlocked by a goroutine, but the parent never locked it.
+func unlockOnly(mu *sync.Mutex) {
+	go func() {
+		mu.Unlock()
+	}()
+}
@@ -0,0 +1,59 @@
+/*
+Package deferloop reports defer statements inside loops.
+
+defer/main.go ends with
+
+	for i := 0; i < 10; i++ {
+		defer fmt.Println(i, "in loop")
+	}
+
+which prints 9 down to 0 after "done": a deferred call runs when the
+function returns, not when the iteration ends. The lesson uses that to show
+the LIFO order, but in real code the same shape opens every file in a
+directory and closes none until the loop is over, or holds a lock taken in
+the first iteration across all the others. Deferred calls also pile up on
+the function's defer stack, one per iteration.
+
+The fix is to move the body into a function, so each iteration has its own
+return, or to make the call directly at the end of the body. Defers in a
+function literal inside the loop are not reported, since that is the fix.
+*/
+package deferloop
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"golang/lint/internal/lintutil"
+)
+
+const Doc = `report defer statements inside loops
+
+A deferred call runs when the surrounding function returns, so a defer in
+a loop body holds every resource it releases until the loop and the rest of
+the function are done.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "deferloop",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.WithStack([]ast.Node{(*ast.DeferStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		if loop := lintutil.EnclosingLoop(stack); loop != nil {
+			pass.ReportRangef(n, "defer in a loop runs when the function returns, not at the end of the iteration (loop at line %d)",
+				pass.Fset.Position(loop.Pos()).Line)
+		}
+		return true
+	})
+	return nil, nil
+}
@@ -0,0 +1,13 @@
+package deferloop_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/deferloop"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), deferloop.Analyzer, "deferloop")
+}
@@ -0,0 +1,69 @@
+package deferloop
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+func lesson() {
+	defer fmt.Println("defer function")
+	for i := 0; i < 10; i++ {
+		defer fmt.Println(i, "in loop") // want `defer in a loop runs when the function returns, not at the end of the iteration \(loop at line 11\)`
+	}
+}
+
+func sizes(names []string) (total int64) {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		defer f.Close() // want `defer in a loop`
+		if fi, err := f.Stat(); err == nil {
+			total += fi.Size()
+		}
+	}
+	return total
+}
+
+func nested(mu *sync.Mutex, rows [][]int) {
+	for _, row := range rows {
+		for range row {
+			mu.Lock()
+			defer mu.Unlock() // want `defer in a loop`
+		}
+	}
+}
+
+// Each iteration has its own function, so its defer runs at its end.
+func perIteration(names []string) {
+	for _, name := range names {
+		func() {
+			f, err := os.Open(name)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+		}()
+	}
+}
+
+// A function literal declared in a loop and run later defers at its own
+// return too.
+func callbacks(names []string) []func() {
+	var out []func()
+	for _, name := range names {
+		out = append(out, func() {
+			defer fmt.Println("done", name)
+		})
+	}
+	return out
+}
+
+func afterLoop(mu *sync.Mutex, n int) {
+	for i := 0; i < n; i++ {
+	}
+	mu.Lock()
+	defer mu.Unlock()
+}
@@ -0,0 +1,108 @@
+// Package lintutil holds the call matching and tree walking that the lint
+// analyzers share.
+package lintutil
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// IsFunc reports whether call statically calls the package-level function
+// pkg.name, such as time.Sleep.
+func IsFunc(info *types.Info, call *ast.CallExpr, pkg, name string) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkg || fn.Name() != name {
+		return false
+	}
+	return fn.Type().(*types.Signature).Recv() == nil
+}
+
+// IsMethod reports whether call calls one of names on pkg.typ or *pkg.typ,
+// such as sync.Mutex.Lock, including through an embedded field.
+func IsMethod(info *types.Info, call *ast.CallExpr, pkg, typ string, names ...string) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != pkg || named.Obj().Name() != typ {
+		return false
+	}
+	for _, n := range names {
+		if fn.Name() == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Inspect is ast.Inspect that does not enter function literals below n:
+// their bodies run at another time, or in another goroutine, so what
+// happens in them is not part of the enclosing function's control flow.
+func Inspect(n ast.Node, f func(ast.Node) bool) {
+	ast.Inspect(n, func(m ast.Node) bool {
+		if _, ok := m.(*ast.FuncLit); ok && m != n {
+			return false
+		}
+		return f(m)
+	})
+}
+
+// EnclosingLoop returns the innermost for or range statement in stack, the
+// path from the file down to the current node, that belongs to the same
+// function as the node, or nil.
+func EnclosingLoop(stack []ast.Node) ast.Stmt {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit, *ast.FuncDecl:
+			return nil
+		case *ast.ForStmt:
+			if isLoopBody(n.Body, stack[i+1]) {
+				return n
+			}
+		case *ast.RangeStmt:
+			if isLoopBody(n.Body, stack[i+1]) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// isLoopBody keeps the loop header out: the init of a for statement and the
+// operand of a range run once.
+func isLoopBody(body *ast.BlockStmt, child ast.Node) bool {
+	return child == ast.Node(body)
+}
+
+// Object returns the variable or field that e, an identifier or a chain of
+// selectors such as c.mu or s.state.mu, finally names. Two expressions that
+// name the same field of different values give the same object, which is
+// the granularity the analyzers want: they reason about "the mutex in
+// SafeCounter", not about a particular counter.
+func Object(info *types.Info, e ast.Expr) types.Object {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return info.ObjectOf(e)
+	case *ast.SelectorExpr:
+		if sel := info.Selections[e]; sel != nil {
+			return sel.Obj()
+		}
+		return info.ObjectOf(e.Sel) // qualified identifier, pkg.Var
+	case *ast.UnaryExpr:
+		return Object(info, e.X) // &mu
+	case *ast.StarExpr:
+		return Object(info, e.X)
+	}
+	return nil
+}
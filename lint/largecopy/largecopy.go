@@ -0,0 +1,478 @@
+/*
+Package largecopy reports values above a size threshold that are copied by
+passing them as parameters or receivers, returning them, or ranging over
+them.
+
+concept/Pass_By_Value_And_pointer/what_happen_when_pass_a_large_struct.md
+shows
+
+	type LargeStruct struct {
+		A [1000000]int
+	}
+
+	func doSomething(s LargeStruct)
+
+where every call copies eight megabytes. Most copies are far smaller and
+cheaper than the indirection a pointer costs, so the analyzer measures each
+one with the package's types.Sizes, the same numbers unsafe.Sizeof gives,
+and reports those above -threshold bytes (256 by default):
+
+	parameters and results of every function and function literal
+	receivers of methods
+	range value variables, copied once per iteration
+	arrays ranged over by value, copied once before the loop
+
+One more diagnostic, on the package clause, totals the copies by kind and
+their sizes added up, each counted once however often it runs.
+
+A receiver gets a suggested fix that makes it a pointer when the change
+cannot alter what the program does or stop it compiling:
+
+	the method does not assign to the receiver, take its address or call
+	pointer methods on it, since those would then change the caller's value
+	the receiver is only used to select a field or method or to index it;
+	returned, passed, compared or converted as a whole it would be a
+	pointer where a value was meant
+	no interface in scope with a method of that name is satisfied by the
+	type, since values stored in one, fmt.Stringer for a String method,
+	would lose the method
+	no call in the package is on a value that is not addressable, such as a
+	map element or a function result, and no method expression T.M names it
+	no struct in the package embeds the type, whose method set would change
+	the method is unexported, the type is unexported or the package is main,
+	so no other package can be relying on it
+
+Parameters, results and range variables get no fix, since their callers or
+bodies would need more than one edit.
+*/
+package largecopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `report large values copied as parameters, receivers, results and range variables
+
+Each copy is measured with the package's types.Sizes and reported when it is
+over -threshold bytes; receivers get a fix to a pointer receiver where that
+is safe.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "largecopy",
+	Doc:        Doc,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	Run:        run,
+	ResultType: reflect.TypeFor[*Totals](),
+}
+
+var threshold int64
+
+func init() {
+	Analyzer.Flags.Int64Var(&threshold, "threshold", 256, "report copies of more than this many bytes")
+}
+
+// Totals is the analyzer's result: what it reported in one package.
+type Totals struct {
+	Copies int
+	Bytes  int64
+	ByKind map[string]int // parameter, receiver, result, range variable, range array
+}
+
+// kinds is the order the package diagnostic lists them in.
+var kinds = []string{"parameter", "receiver", "result", "range variable", "range array"}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	totals := &Totals{ByKind: make(map[string]int)}
+	report := func(kind string, n ast.Node, size int64, format string, args ...any) *analysis.Diagnostic {
+		totals.Copies++
+		totals.Bytes += size
+		totals.ByKind[kind]++
+		return &analysis.Diagnostic{Pos: n.Pos(), End: n.End(), Message: fmt.Sprintf(format, args...)}
+	}
+
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil), (*ast.RangeStmt)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				for _, f := range n.Recv.List {
+					t := pass.TypesInfo.TypeOf(f.Type)
+					if size, ok := large(pass, t); ok {
+						d := report("receiver", f, size, "receiver %s copies %s on every call to %s; use a pointer receiver",
+							types.TypeString(t, types.RelativeTo(pass.Pkg)), bytes(size), n.Name.Name)
+						if fix, ok := pointerFix(pass, n, f); ok {
+							d.SuggestedFixes = []analysis.SuggestedFix{fix}
+						}
+						pass.Report(*d)
+					}
+				}
+			}
+			signature(pass, n.Type, report)
+		case *ast.FuncLit:
+			signature(pass, n.Type, report)
+		case *ast.RangeStmt:
+			if n.Value == nil || isBlank(n.Value) {
+				return // without a value variable nothing is copied
+			}
+			x := pass.TypesInfo.TypeOf(n.X)
+			if x == nil {
+				return
+			}
+			if _, ok := x.Underlying().(*types.Array); ok {
+				if size, ok := large(pass, x); ok {
+					pass.Report(*report("range array", n.X, size, "range over an array value copies %s before the loop; range over a pointer to it or a slice of it",
+						bytes(size)))
+				}
+			}
+			if size, ok := large(pass, pass.TypesInfo.TypeOf(n.Value)); ok {
+				pass.Report(*report("range variable", n.Value, size, "range variable %s copies %s on every iteration; range over the index and use a pointer to the element",
+					types.ExprString(n.Value), bytes(size)))
+			}
+		}
+	})
+
+	if totals.Copies > 0 && len(pass.Files) > 0 {
+		var parts []string
+		for _, k := range kinds {
+			if c := totals.ByKind[k]; c > 0 {
+				parts = append(parts, plural(c, k))
+			}
+		}
+		name := pass.Files[0].Name
+		pass.Reportf(name.Pos(), "package %s copies %s over %d bytes, %s in total: %s",
+			pass.Pkg.Name(), plural(totals.Copies, "value"), threshold, bytes(totals.Bytes), strings.Join(parts, ", "))
+	}
+	return totals, nil
+}
+
+type reporter func(kind string, n ast.Node, size int64, format string, args ...any) *analysis.Diagnostic
+
+// signature reports the parameters and results of a function type.
+func signature(pass *analysis.Pass, ft *ast.FuncType, report reporter) {
+	for _, list := range []struct {
+		kind   string
+		fields *ast.FieldList
+	}{{"parameter", ft.Params}, {"result", ft.Results}} {
+		if list.fields == nil {
+			continue
+		}
+		for _, f := range list.fields.List {
+			t := pass.TypesInfo.TypeOf(f.Type)
+			size, ok := large(pass, t)
+			if !ok {
+				continue
+			}
+			what := "pass"
+			if list.kind == "result" {
+				what = "return"
+			}
+			// One copy per name: a, b Big copies twice.
+			if len(f.Names) == 0 {
+				pass.Report(*report(list.kind, f.Type, size, "%s copies %s; %s *%s",
+					list.kind, bytes(size), what, types.TypeString(t, types.RelativeTo(pass.Pkg))))
+			}
+			for _, id := range f.Names {
+				pass.Report(*report(list.kind, id, size, "%s %s copies %s; %s *%s",
+					list.kind, id.Name, bytes(size), what, types.TypeString(t, types.RelativeTo(pass.Pkg))))
+			}
+		}
+	}
+}
+
+// large returns the size of t and whether it is over the threshold. Types
+// involving type parameters have no size until instantiated.
+func large(pass *analysis.Pass, t types.Type) (int64, bool) {
+	if t == nil || hasTypeParam(t, nil) {
+		return 0, false
+	}
+	size := pass.TypesSizes.Sizeof(t)
+	return size, size > threshold
+}
+
+func hasTypeParam(t types.Type, seen map[*types.Named]bool) bool {
+	switch t := t.(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Array:
+		return hasTypeParam(t.Elem(), seen)
+	case *types.Struct:
+		for i := range t.NumFields() {
+			if hasTypeParam(t.Field(i).Type(), seen) {
+				return true
+			}
+		}
+	case *types.Named:
+		if seen[t] {
+			return false
+		}
+		if seen == nil {
+			seen = make(map[*types.Named]bool)
+		}
+		seen[t] = true
+		if args := t.TypeArgs(); args != nil {
+			for i := range args.Len() {
+				if hasTypeParam(args.At(i), seen) {
+					return true
+				}
+			}
+		}
+		return t.TypeParams().Len() > 0 && t.TypeArgs() == nil || hasTypeParam(t.Underlying(), seen)
+	}
+	return false
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+func bytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+func plural(n int, what string) string {
+	if n == 1 {
+		return "1 " + what
+	}
+	return fmt.Sprintf("%d %ss", n, what)
+}
+
+// pointerFix returns the edit turning the receiver of decl into a pointer,
+// if the conditions in the package comment hold.
+func pointerFix(pass *analysis.Pass, decl *ast.FuncDecl, field *ast.Field) (analysis.SuggestedFix, bool) {
+	fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok || decl.Body == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	named, ok := fn.Type().(*types.Signature).Recv().Type().(*types.Named)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	if fn.Exported() && named.Obj().Exported() && pass.Pkg.Name() != "main" {
+		return analysis.SuggestedFix{}, false
+	}
+	if len(field.Names) == 1 {
+		recv := pass.TypesInfo.Defs[field.Names[0]]
+		if mutates(pass.TypesInfo, decl.Body, recv) || !onlySelected(pass.TypesInfo, decl.Body, recv) {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	if inInterface(pass.Pkg, named, fn.Name()) || needsValue(pass.TypesInfo, fn, named) || embedded(pass.Pkg, named) {
+		return analysis.SuggestedFix{}, false
+	}
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("make %s a pointer receiver", types.ExprString(field.Type)),
+		TextEdits: []analysis.TextEdit{{Pos: field.Type.Pos(), End: field.Type.Pos(), NewText: []byte("*")}},
+	}, true
+}
+
+// mutates reports whether body changes recv or something inside it, or
+// lets something else do so by taking its address, slicing an array in it
+// or calling a pointer method: today that changes the method's copy, with a
+// pointer receiver it would change the caller's value.
+func mutates(info *types.Info, body *ast.BlockStmt, recv types.Object) bool {
+	if recv == nil {
+		return false
+	}
+	rooted := func(e ast.Expr) bool { return root(info, e) == recv }
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, l := range n.Lhs {
+				found = found || n.Tok != token.DEFINE && rooted(l)
+			}
+		case *ast.IncDecStmt:
+			found = found || rooted(n.X)
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				found = found || n.Key != nil && rooted(n.Key) || n.Value != nil && rooted(n.Value)
+			}
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.AND && rooted(n.X)
+		case *ast.SliceExpr:
+			// Slicing an addressable array takes its address: b.arr[:]
+			// aliases the copy's storage.
+			if _, ok := info.TypeOf(n.X).Underlying().(*types.Array); ok {
+				found = found || rooted(n.X)
+			}
+		case *ast.SelectorExpr:
+			if sel := info.Selections[n]; sel != nil && sel.Kind() == types.MethodVal && !sel.Indirect() && rooted(n.X) {
+				_, ptr := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer)
+				found = found || ptr
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// onlySelected reports whether every use of recv in body is the operand of
+// a selector or an index, the uses that mean the same through a pointer.
+func onlySelected(info *types.Info, body *ast.BlockStmt, recv types.Object) bool {
+	if recv == nil {
+		return true
+	}
+	operands := make(map[*ast.Ident]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		var x ast.Expr
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			x = n.X
+		case *ast.IndexExpr:
+			x = n.X
+		}
+		if id, ok := ast.Unparen(x).(*ast.Ident); ok {
+			operands[id] = true
+		}
+		return true
+	})
+	ok := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, isID := n.(*ast.Ident); isID && info.Uses[id] == recv && !operands[id] {
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
+
+// root returns the variable at the bottom of a chain of field selectors,
+// array indexes and parentheses, the value a write through e lands in.
+func root(info *types.Info, e ast.Expr) types.Object {
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			return info.ObjectOf(x)
+		case *ast.SelectorExpr:
+			sel := info.Selections[x]
+			if sel == nil || sel.Kind() != types.FieldVal || sel.Indirect() {
+				return nil // through a pointer the target is shared already
+			}
+			e = x.X
+		case *ast.IndexExpr:
+			if _, ok := info.TypeOf(x.X).Underlying().(*types.Array); !ok {
+				return nil // slices and maps share their elements already
+			}
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// inInterface reports whether named satisfies an interface in scope that
+// has a method called name: a value stored in one would lose the method.
+func inInterface(pkg *types.Package, named *types.Named, name string) bool {
+	check := func(obj types.Object) bool {
+		tn, ok := obj.(*types.TypeName)
+		if !ok {
+			return false
+		}
+		it, ok := tn.Type().Underlying().(*types.Interface)
+		if !ok || !it.IsMethodSet() {
+			return false
+		}
+		for i := range it.NumMethods() {
+			if it.Method(i).Name() == name && types.Implements(named, it) {
+				return true
+			}
+		}
+		return false
+	}
+	scopes := []*types.Scope{pkg.Scope(), types.Universe}
+	for _, imp := range pkg.Imports() {
+		scopes = append(scopes, imp.Scope())
+	}
+	for _, s := range scopes {
+		for _, n := range s.Names() {
+			if check(s.Lookup(n)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsValue reports whether the package calls fn on a value that is not
+// addressable, or names it as the method expression T.M; both stop
+// compiling with a pointer receiver.
+func needsValue(info *types.Info, fn *types.Func, named *types.Named) bool {
+	for e, sel := range info.Selections {
+		if sel.Obj() != fn {
+			continue
+		}
+		switch sel.Kind() {
+		case types.MethodExpr:
+			if _, ptr := sel.Recv().(*types.Pointer); !ptr {
+				return true
+			}
+		case types.MethodVal:
+			if !sel.Indirect() && types.Identical(info.TypeOf(e.X), named) && !addressable(info, e.X) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func addressable(info *types.Info, e ast.Expr) bool {
+	switch x := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		_, ok := info.ObjectOf(x).(*types.Var)
+		return ok
+	case *ast.SelectorExpr:
+		sel := info.Selections[x]
+		if sel == nil {
+			_, ok := info.ObjectOf(x.Sel).(*types.Var) // pkg.Var
+			return ok
+		}
+		return sel.Kind() == types.FieldVal && (sel.Indirect() || addressable(info, x.X))
+	case *ast.IndexExpr:
+		switch info.TypeOf(x.X).Underlying().(type) {
+		case *types.Slice, *types.Pointer:
+			return true
+		case *types.Array:
+			return addressable(info, x.X)
+		}
+		return false // maps and strings
+	case *ast.StarExpr:
+		return true
+	}
+	return false
+}
+
+// embedded reports whether a struct type of pkg embeds named by value.
+func embedded(pkg *types.Package, named *types.Named) bool {
+	for _, n := range pkg.Scope().Names() {
+		tn, ok := pkg.Scope().Lookup(n).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st, ok := tn.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for i := range st.NumFields() {
+			if f := st.Field(i); f.Embedded() && types.Identical(f.Type(), named) {
+				return true
+			}
+		}
+	}
+	return false
+}
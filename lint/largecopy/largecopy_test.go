@@ -0,0 +1,13 @@
+package largecopy_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/largecopy"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), largecopy.Analyzer, "largecopy")
+}
@@ -0,0 +1,112 @@
+package largecopy // want `package largecopy copies 25 values over 256 bytes, 7.6 MiB in total: 7 parameters, 12 receivers, 2 results, 3 range variables, 1 range array`
+
+import "fmt"
+
+// The lesson's struct: eight megabytes.
+type LargeStruct struct {
+	A [1000000]int
+}
+
+func doSomething(s LargeStruct) {} // want `parameter s copies 7.6 MiB; pass \*LargeStruct`
+
+func modify(s *LargeStruct) { s.A[0] = 100 }
+
+// 520 bytes.
+type block struct {
+	words [64]uint64
+	n     int
+}
+
+type small struct{ a, b int }
+
+func (b block) sum() (total uint64) { // want `receiver block copies 520 bytes on every call to sum; use a pointer receiver`
+	for _, w := range b.words[:b.n] {
+		total += w
+	}
+	return total
+}
+
+func (b block) reset() { // want `receiver block copies 520 bytes on every call to reset`
+	b.n = 0 // changes the copy; a pointer receiver would change the caller's block
+}
+
+func (b block) grow() block { // want `receiver block copies 520 bytes on every call to grow` `result copies 520 bytes; return \*block`
+	b.push(1)
+	return b
+}
+
+func (b block) fill(src []uint64) int { // want `receiver block copies 520 bytes on every call to fill`
+	return copy(b.words[:], src) // fills the copy's array
+}
+
+func (b *block) push(w uint64) {
+	b.words[b.n] = w
+	b.n++
+}
+
+func (b block) String() string { // want `receiver block copies 520 bytes on every call to String`
+	return fmt.Sprint(b.n, " words")
+}
+
+func (b block) first() uint64 { return b.words[0] } // want `receiver block copies 520 bytes on every call to first`
+
+func firsts(m map[string]block) uint64 {
+	return m["a"].first() // a map element is not addressable
+}
+
+func (b block) last() uint64 { return b.words[b.n-1] } // want `receiver block copies 520 bytes on every call to last`
+
+var lastOf = block.last // a method expression on the value type
+
+// Under the threshold.
+type Frame struct{ Pixels [128]byte }
+
+func (f Frame) Len() int { return len(f.Pixels) }
+
+// An exported method of an exported type may be called from other packages,
+// so it gets no fix.
+type Canvas struct{ Pixels [1024]byte }
+
+func (c Canvas) Width() int { return 32 } // want `receiver Canvas copies 1.0 KiB on every call to Width`
+
+func compare(a, b block, s small) bool { // want `parameter a copies 520 bytes; pass \*block` `parameter b copies 520 bytes`
+	return a.n == b.n && s.a == 0
+}
+
+func walk(blocks []block, arr [4]block) (n int) { // want `parameter arr copies 2.0 KiB; pass \*\[4\]block`
+	for i := range blocks {
+		n += blocks[i].n
+	}
+	for _, b := range blocks { // want `range variable b copies 520 bytes on every iteration`
+		n += b.n
+	}
+	for _, b := range arr { // want `range over an array value copies 2.0 KiB before the loop` `range variable b copies 520 bytes`
+		n += b.n
+	}
+	for _, b := range &arr { // want `range variable b copies 520 bytes`
+		_ = b.n
+	}
+	f := func(block) {} // want `parameter copies 520 bytes; pass \*block`
+	f(arr[0])
+	return n
+}
+
+func generic[T any](v T) T { return v }
+
+type Box[T any] struct{ v T }
+
+func (b Box[T]) Get() T { return b.v }
+
+// Uses of the whole receiver mean something else through a pointer, or
+// do not compile, so none of these get a fix.
+type big struct{ a [64]int }
+
+func (b big) clone() big { return b } // want `receiver big copies 512 bytes on every call to clone` `result copies 512 bytes; return \*big`
+
+func use(b big) int { return b.a[0] } // want `parameter b copies 512 bytes; pass \*big`
+
+func (b big) pass() int { return use(b) } // want `receiver big copies 512 bytes on every call to pass`
+
+func (b big) eq(o big) bool { return b == o } // want `receiver big copies 512 bytes on every call to eq` `parameter o copies 512 bytes`
+
+func (b big) asAny() any { return b } // want `receiver big copies 512 bytes on every call to asAny`
@@ -0,0 +1,147 @@
+/*
+Package mixedrecv reports types whose methods mix value and pointer
+receivers.
+
+method/pointer.go declares
+
+	func (v Vertex) Abs() float64
+	func (v *Vertex) Scale(f float64)
+
+and method/indirection.go gives the advice it goes against: all methods on
+a type should have value receivers or all pointer receivers. The reason
+shows up in interface/main.go. The method set of Vertex holds only the
+value-receiver methods, that of *Vertex holds both, so a Vertex satisfies
+fewer interfaces than a *Vertex, and which ones depends on a detail of each
+method's declaration. With one kind of receiver the answer is the same for
+every method: either both T and *T satisfy an interface, or only *T does.
+
+The receiver kind that most of the type's methods use wins, and the
+others are reported; on a tie the value receivers are reported, since
+pointer receivers are the more common choice. Decoding methods are exempt,
+because they must have pointer receivers even on a type that is otherwise
+a value, such as time.Time or config.Size: UnmarshalJSON, UnmarshalText,
+UnmarshalBinary, UnmarshalXML, UnmarshalXMLAttr, GobDecode, Scan and Set.
+cmd/implements shows what a type's method sets satisfy.
+*/
+package mixedrecv
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `report types whose methods mix value and pointer receivers
+
+The method set of T lacks the pointer-receiver methods of *T, so with mixed
+receivers T and *T satisfy different interfaces.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "mixedrecv",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// decoders must have pointer receivers whatever the rest of the type does.
+var decoders = map[string]bool{
+	"UnmarshalJSON":    true,
+	"UnmarshalText":    true,
+	"UnmarshalBinary":  true,
+	"UnmarshalXML":     true,
+	"UnmarshalXMLAttr": true,
+	"GobDecode":        true,
+	"Scan":             true,
+	"Set":              true,
+}
+
+type method struct {
+	decl *ast.FuncDecl
+	ptr  bool
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	byType := make(map[*types.TypeName][]method)
+	var order []*types.TypeName
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		decl := n.(*ast.FuncDecl)
+		if decl.Recv == nil || len(decl.Recv.List) != 1 || decoders[decl.Name.Name] {
+			return
+		}
+		fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			return
+		}
+		t := fn.Type().(*types.Signature).Recv().Type()
+		_, ptr := t.(*types.Pointer)
+		if ptr {
+			t = t.(*types.Pointer).Elem()
+		}
+		named, ok := t.(*types.Named)
+		if !ok {
+			return
+		}
+		obj := named.Origin().Obj()
+		if byType[obj] == nil {
+			order = append(order, obj)
+		}
+		byType[obj] = append(byType[obj], method{decl, ptr})
+	})
+
+	for _, obj := range order {
+		methods := byType[obj]
+		var values, pointers []method
+		for _, m := range methods {
+			if m.ptr {
+				pointers = append(pointers, m)
+			} else {
+				values = append(values, m)
+			}
+		}
+		if len(values) == 0 || len(pointers) == 0 {
+			continue
+		}
+		odd, usual, kind := values, pointers, "value"
+		if len(values) > len(pointers) {
+			odd, usual, kind = pointers, values, "pointer"
+		}
+		other := "pointer"
+		if kind == "pointer" {
+			other = "value"
+		}
+		for _, m := range odd {
+			pass.ReportRangef(m.decl.Recv, "%s.%s has a %s receiver but %s %s; use one kind of receiver for all of %s's methods",
+				obj.Name(), m.decl.Name.Name, kind, names(usual), have(len(usual), other), obj.Name())
+		}
+	}
+	return nil, nil
+}
+
+// names lists up to three method names.
+func names(ms []method) string {
+	var out []string
+	for i, m := range ms {
+		if i == 3 {
+			out = append(out, fmt.Sprintf("%d more", len(ms)-3))
+			break
+		}
+		out = append(out, m.decl.Name.Name)
+	}
+	if len(out) == 1 {
+		return out[0]
+	}
+	return strings.Join(out[:len(out)-1], ", ") + " and " + out[len(out)-1]
+}
+
+func have(n int, kind string) string {
+	if n == 1 {
+		return "has a " + kind + " receiver"
+	}
+	return "have " + kind + " receivers"
+}
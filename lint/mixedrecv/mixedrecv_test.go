@@ -0,0 +1,13 @@
+package mixedrecv_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/mixedrecv"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), mixedrecv.Analyzer, "mixedrecv")
+}
@@ -0,0 +1,66 @@
+package mixedrecv
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// method/pointer.go
+type Vertex struct {
+	X, Y float64
+}
+
+func (v Vertex) Abs() float64 { // want `Vertex.Abs has a value receiver but Scale has a pointer receiver; use one kind of receiver for all of Vertex's methods`
+	return math.Sqrt(v.X*v.X + v.Y*v.Y)
+}
+
+func (v *Vertex) Scale(f float64) {
+	v.X = v.X * f
+	v.Y = v.Y * f
+}
+
+// The odd one out is the pointer receiver.
+type Point struct{ X, Y int }
+
+func (p Point) Add(q Point) Point    { return Point{p.X + q.X, p.Y + q.Y} }
+func (p Point) Sub(q Point) Point    { return Point{p.X - q.X, p.Y - q.Y} }
+func (p Point) String() string       { return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y) }
+func (p Point) Eq(q Point) bool      { return p == q }
+func (p *Point) Move(dx, dy int)     { p.X += dx; p.Y += dy } // want `Point.Move has a pointer receiver but Add, Sub, String and 3 more have value receivers`
+func (p Point) In(r [2]Point) bool   { return r[0].X <= p.X && p.X < r[1].X }
+func (p Point) Dist(q Point) float64 { return math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y)) }
+
+// A tie reports the value receivers.
+type Counter struct{ n int }
+
+func (c *Counter) Inc()      { c.n++ }
+func (c Counter) Value() int { return c.n } // want `Counter.Value has a value receiver but Inc has a pointer receiver`
+
+// Decoders need a pointer receiver on a value type.
+type Celsius float64
+
+func (c Celsius) String() string { return strconv.FormatFloat(float64(c), 'f', 1, 64) + "°C" }
+
+func (c *Celsius) UnmarshalText(b []byte) error {
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return errors.New("bad temperature")
+	}
+	*c = Celsius(f)
+	return nil
+}
+
+func (c *Celsius) Set(s string) error { return c.UnmarshalText([]byte(s)) }
+
+// All pointer receivers, even the one that only reads.
+type Stack struct{ items []int }
+
+func (s *Stack) Push(v int) { s.items = append(s.items, v) }
+func (s *Stack) Len() int   { return len(s.items) }
+
+// Generic types are grouped by their declaration.
+type Box[T any] struct{ v T }
+
+func (b *Box[T]) Put(v T) { b.v = v }
+func (b *Box[T]) Get() T  { return b.v }
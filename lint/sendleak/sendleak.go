@@ -0,0 +1,470 @@
+/*
+Package sendleak reports return paths that leave a goroutine blocked forever
+on a send to an unbuffered channel.
+
+Leak pattern #2 in advance-concept/3.Goroutine Internals/3.Go_leak_pattern.md
+is a goroutine sending on a channel nobody will receive from. The usual
+shape is a function that starts the work in a goroutine and then gives up
+on it:
+
+	ch := make(chan result)
+	go func() { ch <- fetch() }()
+	select {
+	case r := <-ch:
+		return r, nil
+	case <-ctx.Done():
+		return result{}, ctx.Err() // fetch's goroutine now waits forever
+	}
+
+With an unbuffered channel the send completes only when someone receives,
+and after that return nobody can: the channel is a local that has not
+escaped. Making it buffered with room for every send lets the goroutine
+finish and the channel be collected, and that is the suggested fix when
+there is exactly one send.
+
+The analyzer considers channels made with make(chan T) or make(chan T, 0)
+into a local variable that is used only by sends in goroutines started
+from the function with go func() { ... }(), and by receives, ranges and
+close in the function itself. It walks the control flow graph from each
+such go statement and reports every return, and the end of the function,
+reachable without passing a receive. It does not count: one receive is
+taken to satisfy any number of senders. A goroutine that receives from a
+channel itself is answering the function rather than working for it, and
+is left alone.
+*/
+package sendleak
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+
+	"golang/lint/internal/lintutil"
+)
+
+const Doc = `report returns that leave a goroutine blocked sending on an unbuffered channel
+
+When a function starts a goroutine that sends on a local unbuffered channel
+and then returns without receiving from it, the goroutine can never finish.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "sendleak",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		var body *ast.BlockStmt
+		var g *cfg.CFG
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body == nil {
+				return
+			}
+			body, g = n.Body, cfgs.FuncDecl(n)
+		case *ast.FuncLit:
+			body, g = n.Body, cfgs.FuncLit(n)
+		}
+		if g == nil {
+			return
+		}
+		for obj, mk := range unbuffered(pass, body) {
+			if ch := classify(pass, body, obj, mk); ch != nil {
+				check(pass, g, body, ch)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// channel is a local unbuffered channel whose every use is understood.
+type channel struct {
+	obj   types.Object
+	make  *ast.CallExpr
+	gos   []*ast.GoStmt   // goroutines that send on it
+	sends []*ast.SendStmt // their blocking sends
+	loops []ast.Node      // loops in the function, to tell whether a go statement repeats
+	recvs map[ast.Node]bool
+}
+
+// unbuffered finds the variables declared in body, outside function
+// literals, as make(chan T) or make(chan T, 0).
+func unbuffered(pass *analysis.Pass, body *ast.BlockStmt) map[types.Object]*ast.CallExpr {
+	out := make(map[types.Object]*ast.CallExpr)
+	add := func(id *ast.Ident, rhs ast.Expr) {
+		call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+		if !ok || !isMakeChan(pass.TypesInfo, call) {
+			return
+		}
+		if obj := pass.TypesInfo.Defs[id]; obj != nil {
+			out[obj] = call
+		}
+	}
+	lintutil.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE && len(n.Lhs) == len(n.Rhs) {
+				for i, l := range n.Lhs {
+					if id, ok := l.(*ast.Ident); ok {
+						add(id, n.Rhs[i])
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == len(n.Values) {
+				for i, id := range n.Names {
+					add(id, n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	return out
+}
+
+func isMakeChan(info *types.Info, call *ast.CallExpr) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || info.Uses[id] != types.Universe.Lookup("make") {
+		return false
+	}
+	if _, ok := info.TypeOf(call).Underlying().(*types.Chan); !ok {
+		return false
+	}
+	if len(call.Args) == 1 {
+		return true
+	}
+	tv := info.Types[call.Args[1]]
+	return tv.Value != nil && constant.Sign(tv.Value) == 0
+}
+
+// classify looks at every use of obj in body and returns nil if any of them
+// is not a send in a goroutine literal or a receive, range or close in the
+// function, since then the channel may be received from elsewhere.
+func classify(pass *analysis.Pass, body *ast.BlockStmt, obj types.Object, mk *ast.CallExpr) *channel {
+	ch := &channel{obj: obj, make: mk, recvs: make(map[ast.Node]bool)}
+	ok := true
+	var gos map[*ast.GoStmt]bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			ch.loops = append(ch.loops, n)
+		}
+		return true
+	})
+	inspectAll(body, func(n ast.Node, stack []ast.Node) bool {
+		id, isIdent := n.(*ast.Ident)
+		if !ok || !isIdent || pass.TypesInfo.Uses[id] != obj {
+			return ok
+		}
+		parent := stack[len(stack)-2]
+		g, depth := goroutine(stack)
+		switch {
+		case depth < 0:
+			ok = false // inside a function literal that is not a goroutine
+		case g != nil:
+			switch p := parent.(type) {
+			case *ast.SendStmt:
+				if _, inSelect := stack[len(stack)-3].(*ast.CommClause); p.Chan == id && !inSelect {
+					ch.sends = append(ch.sends, p)
+					if gos == nil {
+						gos = make(map[*ast.GoStmt]bool)
+					}
+					if !gos[g] {
+						gos[g] = true
+						ch.gos = append(ch.gos, g)
+					}
+				}
+				// A send in a select may give up, so it is not a leak.
+			case *ast.CallExpr:
+				if !isBuiltin(pass.TypesInfo, p, "close") {
+					ok = false
+				}
+			default:
+				ok = false
+			}
+		default:
+			switch p := parent.(type) {
+			case *ast.UnaryExpr:
+				if p.Op != token.ARROW {
+					ok = false
+				}
+				ch.recvs[p] = true
+			case *ast.RangeStmt:
+				if p.X != id {
+					ok = false
+				}
+			case *ast.CallExpr:
+				if !isBuiltin(pass.TypesInfo, p, "close", "len", "cap") {
+					ok = false
+				}
+			default:
+				ok = false
+			}
+		}
+		return ok
+	})
+	if !ok || len(ch.gos) == 0 {
+		return nil
+	}
+	for _, g := range ch.gos {
+		if driven(pass.TypesInfo, g) {
+			return nil
+		}
+	}
+	return ch
+}
+
+// driven reports whether the goroutine receives from a channel itself, as
+// the echo loop in cmd/hdrdemo does. Its sends then answer the function's
+// own sends, which the analyzer does not follow, so it stays quiet.
+func driven(info *types.Info, g *ast.GoStmt) bool {
+	found := false
+	ast.Inspect(g.Call.Fun, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.ARROW
+		case *ast.SelectStmt:
+			for _, c := range n.Body.List {
+				if comm := c.(*ast.CommClause).Comm; comm != nil {
+					if _, send := comm.(*ast.SendStmt); !send {
+						found = true
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			if t := info.TypeOf(n.X); t != nil {
+				_, isChan := t.Underlying().(*types.Chan)
+				found = found || isChan
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// goroutine returns the go statement whose function literal encloses the
+// top of stack, with depth 1, or nil and 0 when the top is in the function
+// itself. Any other function literal on the way gives depth -1.
+func goroutine(stack []ast.Node) (*ast.GoStmt, int) {
+	for i := len(stack) - 1; i >= 1; i-- {
+		lit, ok := stack[i].(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		call, ok := stack[i-1].(*ast.CallExpr)
+		if !ok || call.Fun != lit || i < 2 {
+			return nil, -1
+		}
+		g, ok := stack[i-2].(*ast.GoStmt)
+		if !ok {
+			return nil, -1
+		}
+		for _, outer := range stack[:i-2] {
+			if _, ok := outer.(*ast.FuncLit); ok {
+				return nil, -1
+			}
+		}
+		return g, 1
+	}
+	return nil, 0
+}
+
+func isBuiltin(info *types.Info, call *ast.CallExpr, names ...string) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := info.Uses[id].(*types.Builtin)
+	if !ok {
+		return false
+	}
+	for _, name := range names {
+		if b.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// check walks g from each go statement of ch and reports the exits reached
+// without a receive.
+func check(pass *analysis.Pass, g *cfg.CFG, body *ast.BlockStmt, ch *channel) {
+	// The comm statements of a select are hoisted into the block before
+	// it; whether they happen is decided by the case bodies.
+	hoisted := make(map[ast.Node]bool)
+	lintutil.Inspect(body, func(n ast.Node) bool {
+		if cc, ok := n.(*ast.CommClause); ok && cc.Comm != nil {
+			hoisted[cc.Comm] = true
+		}
+		return true
+	})
+	containsRecv := func(n ast.Node) bool {
+		found := false
+		lintutil.Inspect(n, func(m ast.Node) bool {
+			found = found || ch.recvs[m]
+			return !found
+		})
+		return found
+	}
+	receives := func(n ast.Node) bool {
+		return !hoisted[n] && containsRecv(n)
+	}
+	blockReceives := func(b *cfg.Block) bool {
+		switch s := b.Stmt.(type) {
+		case *ast.CommClause:
+			if b.Kind == cfg.KindSelectCaseBody && s.Comm != nil && containsRecv(s.Comm) {
+				return true
+			}
+		case *ast.RangeStmt:
+			if b.Kind == cfg.KindRangeLoop {
+				if id, ok := ast.Unparen(s.X).(*ast.Ident); ok && pass.TypesInfo.Uses[id] == ch.obj {
+					return true
+				}
+			}
+		}
+		for _, n := range b.Nodes {
+			if receives(n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	reported := make(map[*ast.ReturnStmt]bool)
+	for _, gs := range ch.gos {
+		start, rest := find(g, gs)
+		if start == nil {
+			continue
+		}
+		var leaks []*ast.ReturnStmt
+		seen := make(map[*cfg.Block]bool)
+		var walk func(b *cfg.Block, nodes []ast.Node)
+		walk = func(b *cfg.Block, nodes []ast.Node) {
+			for _, n := range nodes {
+				if receives(n) {
+					return
+				}
+			}
+			if len(b.Succs) == 0 {
+				if exit := exitOf(b); exit != nil {
+					leaks = append(leaks, exit)
+				}
+				return
+			}
+			for _, s := range b.Succs {
+				if !seen[s] {
+					seen[s] = true
+					if !blockReceives(s) {
+						walk(s, s.Nodes)
+					}
+				}
+			}
+		}
+		walk(start, rest)
+		for _, exit := range leaks {
+			if reported[exit] {
+				continue
+			}
+			reported[exit] = true
+			report(pass, body, ch, gs, exit)
+		}
+	}
+}
+
+// find returns the block holding the go statement and the nodes after it.
+func find(g *cfg.CFG, gs *ast.GoStmt) (*cfg.Block, []ast.Node) {
+	for _, b := range g.Blocks {
+		for i, n := range b.Nodes {
+			if n == gs {
+				return b, b.Nodes[i+1:]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// exitOf returns the return statement that ends b, a block without
+// successors, or nil if b ends in a call that does not return, such as
+// panic or os.Exit. The CFG makes falling off the end of the function an
+// explicit return at the closing brace.
+func exitOf(b *cfg.Block) *ast.ReturnStmt {
+	if !b.Live || len(b.Nodes) == 0 {
+		return nil
+	}
+	ret, _ := b.Nodes[len(b.Nodes)-1].(*ast.ReturnStmt)
+	return ret
+}
+
+func report(pass *analysis.Pass, body *ast.BlockStmt, ch *channel, gs *ast.GoStmt, exit *ast.ReturnStmt) {
+	what := "return"
+	if exit.Return == body.Rbrace {
+		what = "end of function"
+	}
+	d := analysis.Diagnostic{
+		Pos: exit.Pos(),
+		End: exit.End(),
+		Message: fmt.Sprintf("%s reached without receiving from %s: the goroutine started on line %d blocks forever on its send",
+			what, ch.obj.Name(), pass.Fset.Position(gs.Pos()).Line),
+		Related: []analysis.RelatedInformation{{Pos: ch.sends[0].Pos(), End: ch.sends[0].End(), Message: "blocked send"}},
+	}
+	if edit, ok := bufferOne(pass, ch); ok {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   fmt.Sprintf("give %s a buffer of 1 so the send cannot block", ch.obj.Name()),
+			TextEdits: []analysis.TextEdit{edit},
+		}}
+	}
+	pass.Report(d)
+}
+
+// bufferOne returns the edit making the channel buffered with capacity 1,
+// when that is enough: one goroutine, started once, sending once.
+func bufferOne(pass *analysis.Pass, ch *channel) (analysis.TextEdit, bool) {
+	if len(ch.gos) != 1 || len(ch.sends) != 1 || inLoop(ch.loops, ch.gos[0]) || inLoop(ch.loops, ch.sends[0]) {
+		return analysis.TextEdit{}, false
+	}
+	if len(ch.make.Args) == 2 {
+		arg := ch.make.Args[1]
+		return analysis.TextEdit{Pos: arg.Pos(), End: arg.End(), NewText: []byte("1")}, true
+	}
+	arg := ch.make.Args[0]
+	return analysis.TextEdit{Pos: arg.End(), End: arg.End(), NewText: []byte(", 1")}, true
+}
+
+func inLoop(loops []ast.Node, n ast.Node) bool {
+	for _, l := range loops {
+		if l.Pos() < n.Pos() && n.End() <= l.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectAll is ast.Inspect passing the path from root to each node,
+// including root and the node itself.
+func inspectAll(root ast.Node, f func(n ast.Node, stack []ast.Node) bool) {
+	var stack []ast.Node
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if !f(n, stack) {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		return true
+	})
+}
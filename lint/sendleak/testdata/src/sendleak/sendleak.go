@@ -0,0 +1,140 @@
+package sendleak
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+type result struct{ body string }
+
+func fetch() result { return result{"ok"} }
+
+func withContext(ctx context.Context) (result, error) {
+	ch := make(chan result)
+	go func() {
+		ch <- fetch()
+	}()
+	select {
+	case r := <-ch:
+		return r, nil
+	case <-ctx.Done():
+		return result{}, ctx.Err() // want `return reached without receiving from ch: the goroutine started on line 16 blocks forever on its send`
+	}
+}
+
+func earlyReturn(name string) (string, error) {
+	ch := make(chan string, 0)
+	go func() { ch <- fetch().body }()
+	if name == "" {
+		return "", errors.New("no name") // want `return reached without receiving from ch`
+	}
+	return <-ch, nil
+}
+
+func fallsOff(done chan<- bool) {
+	var errc = make(chan error)
+	go func() {
+		errc <- os.Remove("x")
+	}()
+	if time.Now().IsZero() {
+		<-errc
+	}
+	done <- true
+} // want `end of function reached without receiving from errc`
+
+// No fix is offered for a go statement in a loop.
+func fanOut(ctx context.Context, jobs []int) error {
+	ch := make(chan int)
+	for _, j := range jobs {
+		go func() { ch <- j * j }()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err() // want `return reached without receiving from ch`
+		}
+	}
+	return nil
+}
+
+// Every path receives.
+func always() result {
+	ch := make(chan result)
+	go func() { ch <- fetch() }()
+	return <-ch
+}
+
+func ranges() (n int) {
+	ch := make(chan int)
+	go func() {
+		for i := range 3 {
+			ch <- i
+		}
+		close(ch)
+	}()
+	for v := range ch {
+		n += v
+	}
+	return n
+}
+
+// Buffered with room for the send.
+func buffered(ctx context.Context) (result, error) {
+	ch := make(chan result, 1)
+	go func() { ch <- fetch() }()
+	select {
+	case r := <-ch:
+		return r, nil
+	case <-ctx.Done():
+		return result{}, ctx.Err()
+	}
+}
+
+// The goroutine gives up with the context too.
+func cancellable(ctx context.Context) (result, error) {
+	ch := make(chan result)
+	go func() {
+		select {
+		case ch <- fetch():
+		case <-ctx.Done():
+		}
+	}()
+	select {
+	case r := <-ch:
+		return r, nil
+	case <-ctx.Done():
+		return result{}, ctx.Err()
+	}
+}
+
+// The channel escapes, so someone else may receive.
+func escapes() chan result {
+	ch := make(chan result)
+	go func() { ch <- fetch() }()
+	return ch
+}
+
+func fatal() result {
+	ch := make(chan result)
+	go func() { ch <- fetch() }()
+	if len(os.Args) > 5 {
+		panic("too many arguments")
+	}
+	return <-ch
+}
+
+// The goroutine only sends in answer to a receive, as in cmd/hdrdemo.
+func echo(n int) {
+	ping, pong := make(chan struct{}), make(chan struct{})
+	go func() {
+		for range ping {
+			pong <- struct{}{}
+		}
+	}()
+	for range n {
+		ping <- struct{}{}
+		<-pong
+	}
+	close(ping)
+}
@@ -0,0 +1,152 @@
+/*
+Package sleepsync reports time.Sleep used to wait for goroutines.
+
+concurrency/mutex.go starts a thousand goroutines and then
+
+	time.Sleep(time.Second)
+	fmt.Println(c.Value("somekey"))
+
+hoping they have all run by then. On a loaded machine, or under the race
+detector, some have not and the count is short; on an idle one the program
+waits most of a second for work that took a millisecond. A sync.WaitGroup,
+or a channel each goroutine reports on, waits exactly as long as needed.
+
+A Sleep is reported when it follows a go statement in the same function and
+nothing after that go statement waits for anything: no WaitGroup or other
+Wait method, no channel receive, no range over a channel, no select.
+Sleeping inside the goroutine, and a loop that starts a goroutine and
+sleeps on every iteration to pace them, are not reported.
+*/
+package sleepsync
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"golang/lint/internal/lintutil"
+)
+
+const Doc = `report time.Sleep used to wait for goroutines
+
+A sleep after starting goroutines, with nothing else waiting for them, is
+either longer than the work or shorter than it; use a sync.WaitGroup or a
+channel.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "sleepsync",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		var body *ast.BlockStmt
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			body = n.Body
+		case *ast.FuncLit:
+			body = n.Body
+		}
+		if body != nil {
+			check(pass, body)
+		}
+	})
+	return nil, nil
+}
+
+// check looks at one function body, leaving the literals inside it to their
+// own call.
+func check(pass *analysis.Pass, body *ast.BlockStmt) {
+	var (
+		gos    []*ast.GoStmt
+		sleeps []*ast.CallExpr
+		waits  []token.Pos
+		loops  []ast.Node
+	)
+	lintutil.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			gos = append(gos, n)
+		case *ast.ForStmt, *ast.RangeStmt:
+			loops = append(loops, n)
+			if r, ok := n.(*ast.RangeStmt); ok && isChan(pass.TypesInfo.TypeOf(r.X)) {
+				waits = append(waits, r.Pos())
+			}
+		case *ast.SelectStmt:
+			waits = append(waits, n.Pos())
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				waits = append(waits, n.Pos())
+			}
+		case *ast.CallExpr:
+			if lintutil.IsFunc(pass.TypesInfo, n, "time", "Sleep") {
+				sleeps = append(sleeps, n)
+			} else if isWait(pass.TypesInfo, n) {
+				waits = append(waits, n.Pos())
+			}
+		}
+		return true
+	})
+	if len(gos) == 0 || len(sleeps) == 0 {
+		return
+	}
+	first := gos[0]
+	for _, w := range waits {
+		if w > first.Pos() {
+			return // something after the goroutines start really waits
+		}
+	}
+	for _, s := range sleeps {
+		var g *ast.GoStmt
+		for _, cand := range gos {
+			if cand.Pos() < s.Pos() && !sameLoop(loops, cand, s) {
+				g = cand
+			}
+		}
+		if g == nil {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     s.Pos(),
+			End:     s.End(),
+			Message: fmt.Sprintf("time.Sleep used to wait for the goroutine started on line %d; use a sync.WaitGroup or a channel", pass.Fset.Position(g.Pos()).Line),
+			Related: []analysis.RelatedInformation{{Pos: g.Pos(), End: g.End(), Message: "goroutine started here"}},
+		})
+	}
+}
+
+// sameLoop reports whether a loop contains both the go statement and the
+// sleep, which makes the sleep pace the goroutines rather than wait for
+// them.
+func sameLoop(loops []ast.Node, g *ast.GoStmt, s *ast.CallExpr) bool {
+	for _, l := range loops {
+		if l.Pos() <= g.Pos() && g.End() <= l.End() && l.Pos() <= s.Pos() && s.End() <= l.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// isWait matches a call to a method called Wait, which covers
+// sync.WaitGroup, sync.Cond, errgroup.Group and exec.Cmd alike.
+func isWait(info *types.Info, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	return ok && fn.Name() == "Wait" && fn.Type().(*types.Signature).Recv() != nil
+}
+
+func isChan(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Chan)
+	return ok
+}
@@ -0,0 +1,13 @@
+package sleepsync_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"golang/lint/sleepsync"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), sleepsync.Analyzer, "sleepsync")
+}
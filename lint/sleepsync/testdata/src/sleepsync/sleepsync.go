@@ -0,0 +1,82 @@
+package sleepsync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type SafeCounter struct {
+	mu sync.Mutex
+	v  map[string]int
+}
+
+func (c *SafeCounter) Inc(key string) {
+	c.mu.Lock()
+	c.v[key]++
+	c.mu.Unlock()
+}
+
+func (c *SafeCounter) Value(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v[key]
+}
+
+// concurrency/mutex.go
+func lesson() {
+	c := SafeCounter{v: make(map[string]int)}
+	for i := 0; i < 1000; i++ {
+		go c.Inc("somekey")
+	}
+
+	time.Sleep(time.Second) // want `time.Sleep used to wait for the goroutine started on line 30; use a sync.WaitGroup or a channel`
+	fmt.Println(c.Value("somekey"))
+}
+
+func literal() {
+	done := false
+	go func() {
+		done = true
+	}()
+	time.Sleep(10 * time.Millisecond) // want `time.Sleep used to wait`
+	fmt.Println(done)
+}
+
+func waitGroup() {
+	c := SafeCounter{v: make(map[string]int)}
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Inc("somekey")
+		}()
+	}
+	wg.Wait()
+	time.Sleep(time.Millisecond) // a pause after the wait, not instead of it
+	fmt.Println(c.Value("somekey"))
+}
+
+func channel() {
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(time.Millisecond) // inside the goroutine
+		close(done)
+	}()
+	<-done
+}
+
+// Starting a goroutine and sleeping on every iteration paces them.
+func pace(jobs []func()) {
+	for _, job := range jobs {
+		go job()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// A sleep before any goroutine starts waits for nothing in particular.
+func before() {
+	time.Sleep(time.Millisecond)
+	go fmt.Println("later")
+}
@@ -0,0 +1,76 @@
+package matrix_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"golang/matrix"
+)
+
+func TestDecompose(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	a := random(r, 60, 60)
+	lu, err := matrix.Decompose(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pa, _ := matrix.MulNaive(lu.P(), a)
+	prod, _ := matrix.MulNaive(lu.L(), lu.U())
+	if !matrix.ApproxEqual(pa, prod, 1e-10) {
+		t.Fatal("P*A != L*U")
+	}
+
+	x := make([]float64, 60)
+	for i := range x {
+		x[i] = float64(i)
+	}
+	b := make([]float64, 60)
+	for i := range b {
+		for j, v := range a.Row(i) {
+			b[i] += v * x[j]
+		}
+	}
+	got, err := lu.Solve(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxErr := 0.0
+	for i := range got {
+		maxErr = max(maxErr, math.Abs(got[i]-x[i]))
+	}
+	if maxErr > 1e-8 {
+		t.Fatalf("solve: max error %.1e", maxErr)
+	}
+}
+
+func TestDet(t *testing.T) {
+	m, _ := matrix.FromRows([][]int{{19, 22}, {43, 50}})
+	if d, _ := matrix.Det(m); math.Abs(d-(19*50-22*43)) > 1e-9 {
+		t.Errorf("det 2x2 = %v, want %v", d, 19*50-22*43)
+	}
+	if d, _ := matrix.Det(matrix.Identity[float64](8).Scale(2)); d != 256 {
+		t.Errorf("det of 2I (8x8) = %v, want 256", d)
+	}
+
+	r := rand.New(rand.NewPCG(5, 6))
+	a, b := random(r, 20, 20), random(r, 20, 20)
+	ab, _ := matrix.MulBlocked(a, b, 0)
+	da, _ := matrix.Det(a)
+	db, _ := matrix.Det(b)
+	dab, _ := matrix.Det(ab)
+	if math.Abs(dab-da*db) > 1e-9*math.Abs(dab) {
+		t.Errorf("det(AB) = %.6g, det(A)det(B) = %.6g", dab, da*db)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	sing, _ := matrix.FromRows([][]float64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}})
+	if d, _ := matrix.Det(sing); d != 0 {
+		t.Errorf("det = %v, want 0", d)
+	}
+	lu, _ := matrix.Decompose(sing)
+	if _, err := lu.Solve([]float64{1, 2, 3}); err == nil {
+		t.Error("Solve on a singular matrix: no error")
+	}
+}
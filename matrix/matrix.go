@@ -0,0 +1,197 @@
+/*
+Package matrix is a dense matrix generic over the numeric types.
+
+slice/sliceOfSlice.go builds a 2D board as [][]string: every row is a
+separate allocation somewhere on the heap. Matrix keeps all elements in one
+row-major []T instead, so element (i, j) is Data[i*Cols+j], rows are adjacent
+in memory and Row returns a view into the backing slice without copying.
+
+Layout decides speed. A CPU loads memory in 64-byte cache lines. Walking a row
+uses every value of each line it loads; walking a column uses one value per
+line and, once the matrix is larger than the cache, loads each line again on
+the next column. MulNaive walks B by columns and slows down sharply as the
+matrices outgrow the cache; MulBlocked works on tiles small enough to stay in
+cache and reuses them before moving on. MulParallel splits the rows of the
+result across goroutines, which the scheduler spreads over the GOMAXPROCS
+processors described in concept/Processor/how_processor_work_internaly.md.
+cmd/matbench measures all three.
+*/
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Number is any integer or floating point type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Float is the floating point types, needed where division is involved.
+type Float interface {
+	~float32 | ~float64
+}
+
+// ErrShape is returned when operand dimensions do not fit together.
+var ErrShape = errors.New("matrix: dimension mismatch")
+
+// Matrix is a Rows x Cols matrix stored row by row in Data.
+type Matrix[T Number] struct {
+	Rows, Cols int
+	Data       []T
+}
+
+// New returns a zero matrix.
+func New[T Number](rows, cols int) *Matrix[T] {
+	if rows < 0 || cols < 0 {
+		panic("matrix: negative dimension")
+	}
+	return &Matrix[T]{Rows: rows, Cols: cols, Data: make([]T, rows*cols)}
+}
+
+// Identity returns the n x n identity matrix.
+func Identity[T Number](n int) *Matrix[T] {
+	m := New[T](n, n)
+	for i := range n {
+		m.Data[i*n+i] = 1
+	}
+	return m
+}
+
+// FromRows copies rows into a new matrix. All rows must have the same length.
+func FromRows[T Number](rows [][]T) (*Matrix[T], error) {
+	if len(rows) == 0 {
+		return New[T](0, 0), nil
+	}
+	m := New[T](len(rows), len(rows[0]))
+	for i, r := range rows {
+		if len(r) != m.Cols {
+			return nil, fmt.Errorf("%w: row %d has %d columns, want %d", ErrShape, i, len(r), m.Cols)
+		}
+		copy(m.Row(i), r)
+	}
+	return m, nil
+}
+
+// At returns element (i, j).
+func (m *Matrix[T]) At(i, j int) T { return m.Data[i*m.Cols+j] }
+
+// Set assigns element (i, j).
+func (m *Matrix[T]) Set(i, j int, v T) { m.Data[i*m.Cols+j] = v }
+
+// Row returns row i as a slice sharing m's storage: writes through it change m.
+// Its capacity is capped so appending cannot spill into the next row.
+func (m *Matrix[T]) Row(i int) []T {
+	start := i * m.Cols
+	return m.Data[start : start+m.Cols : start+m.Cols]
+}
+
+// Clone returns a deep copy of m.
+func (m *Matrix[T]) Clone() *Matrix[T] {
+	return &Matrix[T]{Rows: m.Rows, Cols: m.Cols, Data: append([]T(nil), m.Data...)}
+}
+
+// Add returns m + b.
+func (m *Matrix[T]) Add(b *Matrix[T]) (*Matrix[T], error) {
+	if m.Rows != b.Rows || m.Cols != b.Cols {
+		return nil, fmt.Errorf("%w: %dx%d + %dx%d", ErrShape, m.Rows, m.Cols, b.Rows, b.Cols)
+	}
+	out := New[T](m.Rows, m.Cols)
+	for i, v := range m.Data {
+		out.Data[i] = v + b.Data[i]
+	}
+	return out, nil
+}
+
+// Sub returns m - b.
+func (m *Matrix[T]) Sub(b *Matrix[T]) (*Matrix[T], error) {
+	if m.Rows != b.Rows || m.Cols != b.Cols {
+		return nil, fmt.Errorf("%w: %dx%d - %dx%d", ErrShape, m.Rows, m.Cols, b.Rows, b.Cols)
+	}
+	out := New[T](m.Rows, m.Cols)
+	for i, v := range m.Data {
+		out.Data[i] = v - b.Data[i]
+	}
+	return out, nil
+}
+
+// Scale returns m with every element multiplied by f.
+func (m *Matrix[T]) Scale(f T) *Matrix[T] {
+	out := New[T](m.Rows, m.Cols)
+	for i, v := range m.Data {
+		out.Data[i] = v * f
+	}
+	return out
+}
+
+// Transpose returns the Cols x Rows transpose of m. It copies in tiles so
+// that both the reads and the strided writes stay within cached lines.
+func (m *Matrix[T]) Transpose() *Matrix[T] {
+	out := New[T](m.Cols, m.Rows)
+	const tile = 32
+	for i0 := 0; i0 < m.Rows; i0 += tile {
+		for j0 := 0; j0 < m.Cols; j0 += tile {
+			for i := i0; i < min(i0+tile, m.Rows); i++ {
+				for j := j0; j < min(j0+tile, m.Cols); j++ {
+					out.Data[j*m.Rows+i] = m.Data[i*m.Cols+j]
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Equal reports whether m and b have the same shape and elements.
+func (m *Matrix[T]) Equal(b *Matrix[T]) bool {
+	if m.Rows != b.Rows || m.Cols != b.Cols {
+		return false
+	}
+	for i, v := range m.Data {
+		if v != b.Data[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ApproxEqual reports whether m and b have the same shape and every pair of
+// elements differs by at most tol relative to their magnitude (absolute below 1).
+func ApproxEqual[T Number](m, b *Matrix[T], tol float64) bool {
+	if m.Rows != b.Rows || m.Cols != b.Cols {
+		return false
+	}
+	for i, v := range m.Data {
+		x, y := float64(v), float64(b.Data[i])
+		if math.Abs(x-y) > tol*max(1, math.Abs(x), math.Abs(y)) {
+			return false
+		}
+	}
+	return true
+}
+
+// String prints one row per line with columns aligned.
+func (m *Matrix[T]) String() string {
+	cells := make([]string, len(m.Data))
+	width := 0
+	for i, v := range m.Data {
+		cells[i] = fmt.Sprint(v)
+		width = max(width, len(cells[i]))
+	}
+	var sb strings.Builder
+	for i := range m.Rows {
+		sb.WriteString("[")
+		for j := range m.Cols {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			fmt.Fprintf(&sb, "%*s", width, cells[i*m.Cols+j])
+		}
+		sb.WriteString("]\n")
+	}
+	return sb.String()
+}
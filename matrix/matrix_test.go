@@ -0,0 +1,26 @@
+package matrix_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"golang/matrix"
+)
+
+func random(r *rand.Rand, rows, cols int) *matrix.Matrix[float64] {
+	m := matrix.New[float64](rows, cols)
+	for i := range m.Data {
+		m.Data[i] = r.Float64()*2 - 1
+	}
+	return m
+}
+
+func TestTranspose(t *testing.T) {
+	a := random(rand.New(rand.NewPCG(1, 2)), 50, 70)
+	if !a.Transpose().Transpose().Equal(a) {
+		t.Fatal("transposing twice changed the matrix")
+	}
+	if at := a.Transpose(); at.Rows != 70 || at.Cols != 50 || at.At(69, 3) != a.At(3, 69) {
+		t.Fatalf("transpose is %dx%d with (69,3) = %v, want 70x50 with %v", at.Rows, at.Cols, at.At(69, 3), a.At(3, 69))
+	}
+}
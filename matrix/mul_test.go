@@ -0,0 +1,65 @@
+package matrix_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"testing"
+
+	"golang/matrix"
+)
+
+func TestMulAgrees(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, dims := range [][3]int{{1, 1, 1}, {3, 5, 2}, {64, 64, 64}, {100, 37, 129}, {200, 200, 200}} {
+		a, b := random(r, dims[0], dims[1]), random(r, dims[1], dims[2])
+		want, err := matrix.MulNaive(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocked, _ := matrix.MulBlocked(a, b, 16)
+		par, _ := matrix.MulParallel(a, b, 32, 4)
+		if !matrix.ApproxEqual(want, blocked, 1e-12) || !matrix.ApproxEqual(want, par, 1e-12) {
+			t.Errorf("%dx%d * %dx%d: blocked or parallel product differs from naive", dims[0], dims[1], dims[1], dims[2])
+		}
+	}
+}
+
+func TestMulInt(t *testing.T) {
+	a, _ := matrix.FromRows([][]int{{1, 2}, {3, 4}})
+	b, _ := matrix.FromRows([][]int{{5, 6}, {7, 8}})
+	want, _ := matrix.FromRows([][]int{{19, 22}, {43, 50}})
+	if got, _ := matrix.MulParallel(a, b, 0, 0); !got.Equal(want) {
+		t.Fatalf("got\n%v\nwant\n%v", got, want)
+	}
+	if _, err := matrix.MulNaive(a, matrix.New[int](3, 3)); err == nil {
+		t.Fatal("2x2 * 3x3: no shape error")
+	}
+}
+
+// benchMul reports GFLOP/s, 2n³ floating point operations per product.
+func benchMul(b *testing.B, mul func(a, b *matrix.Matrix[float64])) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for _, n := range []int{64, 256} {
+		x, y := random(r, n, n), random(r, n, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for range b.N {
+				mul(x, y)
+			}
+			b.ReportMetric(2*float64(n*n*n)*float64(b.N)/float64(b.Elapsed().Nanoseconds()), "GFLOP/s")
+		})
+	}
+}
+
+func BenchmarkMulNaive(b *testing.B) {
+	benchMul(b, func(x, y *matrix.Matrix[float64]) { matrix.MulNaive(x, y) })
+}
+
+func BenchmarkMulBlocked(b *testing.B) {
+	benchMul(b, func(x, y *matrix.Matrix[float64]) { matrix.MulBlocked(x, y, 0) })
+}
+
+func BenchmarkMulParallel(b *testing.B) {
+	workers := runtime.GOMAXPROCS(0)
+	benchMul(b, func(x, y *matrix.Matrix[float64]) { matrix.MulParallel(x, y, 0, workers) })
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// DefBuckets suit request latencies in seconds, from 5ms to 10s.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// LinearBuckets returns count buckets of width starting at start.
+func LinearBuckets(start, width float64, count int) []float64 {
+	b := make([]float64, count)
+	for i := range b {
+		b[i] = start + float64(i)*width
+	}
+	return b
+}
+
+// ExponentialBuckets returns count buckets starting at start, each factor
+// times the previous.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	b := make([]float64, count)
+	for i := range b {
+		b[i] = start
+		start *= factor
+	}
+	return b
+}
+
+// HistogramOpts configures a histogram. Buckets are upper bounds in
+// increasing order; nil uses DefBuckets. A +Inf bucket is always added.
+type HistogramOpts struct {
+	Opts
+	Buckets []float64
+}
+
+// Histogram counts observations into fixed buckets. Quantiles are estimated
+// at query time from the bucket counts, so histograms from many processes can
+// be added together, unlike Summary.
+type Histogram struct {
+	upper  []float64       // shared by every child of a Vec
+	counts []atomic.Uint64 // per bucket, not cumulative; last is +Inf
+	count  atomic.Uint64
+	sum    atomicFloat
+	desc   *desc
+}
+
+func newHistogram(upper []float64) *Histogram {
+	return &Histogram{upper: upper, counts: make([]atomic.Uint64, len(upper)+1)}
+}
+
+// NewHistogram returns an unlabelled histogram ready to register. It panics
+// if the buckets are not strictly increasing.
+func NewHistogram(opts HistogramOpts) *Histogram {
+	v := NewHistogramVec(opts)
+	h := v.With()
+	h.desc = v.desc
+	return h
+}
+
+// Observe records v. Buckets are inclusive upper bounds (le).
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(v)
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+// Sum returns the total of all observations.
+func (h *Histogram) Sum() float64 { return h.sum.Load() }
+
+// Buckets returns the upper bounds, ending with +Inf, and the cumulative
+// count of observations at or below each.
+func (h *Histogram) Buckets() (upper []float64, cumulative []uint64) {
+	upper = append(append([]float64(nil), h.upper...), math.Inf(1))
+	cumulative = make([]uint64, len(h.counts))
+	var total uint64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+		cumulative[i] = total
+	}
+	return upper, cumulative
+}
+
+func validBuckets(b []float64) bool {
+	for i, v := range b {
+		if math.IsNaN(v) || (i > 0 && v <= b[i-1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+/*
+Package metrics records application metrics and exposes them in the Prometheus
+text format.
+
+adder() in clourse/main.go returns a closure that owns a private sum. A
+Counter is the same idea made safe for many goroutines: the running total
+lives in an atomic word instead of a captured variable, so Inc from any
+goroutine needs no lock. Gauge, Histogram and Summary follow the same pattern.
+
+The recording methods (Inc, Add, Set, Observe) do not allocate, and neither
+does looking up an existing labelled child with With. Allocation happens only
+when a new label combination is first seen and when the registry is scraped.
+
+	reg := metrics.NewRegistry()
+	reqs := metrics.NewCounterVec(metrics.Opts{Name: "http_requests_total", Help: "..."}, "code")
+	reg.MustRegister(reqs)
+	reqs.With("200").Inc()
+	http.Handle("/metrics", reg.Handler())
+*/
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Opts names and documents a metric.
+type Opts struct {
+	Name string // [a-zA-Z_:][a-zA-Z0-9_:]*
+	Help string
+}
+
+// atomicFloat is a float64 updated with compare-and-swap on its bits.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Load() float64   { return math.Float64frombits(f.bits.Load()) }
+func (f *atomicFloat) Store(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+func (f *atomicFloat) Add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Counter is a value that only goes up, like requests served or bytes sent.
+type Counter struct {
+	// Whole increments go to an integer so Inc is a single atomic add;
+	// fractional amounts fall back to the float.
+	n    atomic.Uint64
+	frac atomicFloat
+	desc *desc // nil for children of a CounterVec
+}
+
+// NewCounter returns an unlabelled counter ready to register. It panics if
+// the name is invalid.
+func NewCounter(opts Opts) *Counter {
+	return &Counter{desc: newDesc(opts, "counter", nil, "")}
+}
+
+// Inc adds 1.
+func (c *Counter) Inc() { c.n.Add(1) }
+
+// Add adds v, which must not be negative; negative values are ignored
+// because a counter that went down would look like a process restart.
+func (c *Counter) Add(v float64) {
+	switch {
+	case v < 0 || math.IsNaN(v):
+		return
+	case v == math.Trunc(v) && v < 1<<53:
+		c.n.Add(uint64(v))
+	default:
+		c.frac.Add(v)
+	}
+}
+
+// Value returns the current total.
+func (c *Counter) Value() float64 { return float64(c.n.Load()) + c.frac.Load() }
+
+// Gauge is a value that goes up and down, like in-flight requests or queue
+// length.
+type Gauge struct {
+	v    atomicFloat
+	desc *desc
+}
+
+// NewGauge returns an unlabelled gauge ready to register.
+func NewGauge(opts Opts) *Gauge {
+	return &Gauge{desc: newDesc(opts, "gauge", nil, "")}
+}
+
+func (g *Gauge) Set(v float64) { g.v.Store(v) }
+func (g *Gauge) Add(v float64) { g.v.Add(v) }
+func (g *Gauge) Sub(v float64) { g.v.Add(-v) }
+func (g *Gauge) Inc()          { g.v.Add(1) }
+func (g *Gauge) Dec()          { g.v.Add(-1) }
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return g.v.Load() }
+
+func (c *Counter) describe() *desc      { return c.desc }
+func (c *Counter) collect(w *expWriter) { w.sample(c.desc, "", nil, "", "", c.Value()) }
+func (g *Gauge) describe() *desc        { return g.desc }
+func (g *Gauge) collect(w *expWriter)   { w.sample(g.desc, "", nil, "", "", g.Value()) }
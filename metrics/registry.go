@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	inf = math.Inf(1)
+	nan = math.NaN()
+)
+
+// Collector is a metric family that can be registered: a metric from
+// NewCounter, NewGauge, NewHistogram or NewSummary, any of the *Vec types, or
+// the result of NewGaugeFunc and NewCounterFunc.
+type Collector interface {
+	describe() *desc
+	collect(w *expWriter)
+}
+
+// Registry is a set of metric families with unique names.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Register adds c. It fails if a family with the same name is registered or
+// c is a child of a Vec rather than a family.
+func (r *Registry) Register(c Collector) error {
+	d := c.describe()
+	if d == nil {
+		return fmt.Errorf("metrics: %T has no name: it is a Vec child or was not made by a New function", c)
+	}
+	name := d.name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.collectors[name]; dup {
+		return fmt.Errorf("metrics: %s already registered", name)
+	}
+	r.collectors[name] = c
+	return nil
+}
+
+// MustRegister registers every collector and panics on the first error.
+func (r *Registry) MustRegister(cs ...Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Unregister removes the family called name.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.collectors[name]
+	delete(r.collectors, name)
+	return ok
+}
+
+// WriteText writes every family in the Prometheus text exposition format
+// (version 0.0.4), sorted by name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	cs := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		cs = append(cs, c)
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(cs, func(a, b Collector) int { return strings.Compare(a.describe().name, b.describe().name) })
+
+	ew := &expWriter{w: bufio.NewWriter(w)}
+	for _, c := range cs {
+		d := c.describe()
+		if d.help != "" {
+			fmt.Fprintf(ew.w, "# HELP %s %s\n", d.name, helpEscaper.Replace(d.help))
+		}
+		fmt.Fprintf(ew.w, "# TYPE %s %s\n", d.name, d.typ)
+		c.collect(ew)
+	}
+	return ew.w.Flush()
+}
+
+// Handler serves WriteText for a Prometheus scraper.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// expWriter formats samples.
+type expWriter struct {
+	w *bufio.Writer
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// sample writes one line: name+suffix, the family's labels with values, an
+// optional extra label (le or quantile) and the value.
+func (ew *expWriter) sample(d *desc, suffix string, values []string, extraName, extraValue string, v float64) {
+	w := ew.w
+	w.WriteString(d.name)
+	w.WriteString(suffix)
+	if len(values) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, l := range d.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l, labelEscaper.Replace(values[i]))
+		}
+		if extraName != "" {
+			if len(d.labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
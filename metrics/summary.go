@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DefQuantiles are the quantiles a Summary tracks by default.
+var DefQuantiles = []float64{0.5, 0.9, 0.99}
+
+// SummaryOpts configures a summary. Quantiles must be in (0, 1); nil uses
+// DefQuantiles.
+type SummaryOpts struct {
+	Opts
+	Quantiles []float64
+}
+
+// Summary estimates quantiles of a stream of observations with the P²
+// algorithm (Jain and Chlamtac, 1985). Each quantile keeps five markers whose
+// heights are adjusted with a parabolic fit as observations arrive, so memory
+// is constant and Observe never allocates. Estimates cover the whole lifetime
+// of the process and cannot be merged across processes; use a Histogram when
+// either matters.
+type Summary struct {
+	count atomic.Uint64
+	sum   atomicFloat
+
+	mu sync.Mutex
+	qs []p2
+
+	desc *desc
+}
+
+func newSummary(quantiles []float64) *Summary {
+	s := &Summary{qs: make([]p2, len(quantiles))}
+	for i, q := range quantiles {
+		s.qs[i].init(q)
+	}
+	return s
+}
+
+// NewSummary returns an unlabelled summary ready to register. It panics if a
+// quantile is outside (0, 1).
+func NewSummary(opts SummaryOpts) *Summary {
+	v := NewSummaryVec(opts)
+	s := v.With()
+	s.desc = v.desc
+	return s
+}
+
+// Observe records v.
+func (s *Summary) Observe(v float64) {
+	s.count.Add(1)
+	s.sum.Add(v)
+	s.mu.Lock()
+	for i := range s.qs {
+		s.qs[i].add(v)
+	}
+	s.mu.Unlock()
+}
+
+// Count returns the number of observations.
+func (s *Summary) Count() uint64 { return s.count.Load() }
+
+// Sum returns the total of all observations.
+func (s *Summary) Sum() float64 { return s.sum.Load() }
+
+// Quantiles returns the tracked quantiles and their current estimates, which
+// are NaN until the first observation.
+func (s *Summary) Quantiles() (qs, values []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	qs = make([]float64, len(s.qs))
+	values = make([]float64, len(s.qs))
+	for i := range s.qs {
+		qs[i], values[i] = s.qs[i].p, s.qs[i].value()
+	}
+	return qs, values
+}
+
+// p2 estimates one quantile p. Markers 0 and 4 track the minimum and maximum,
+// marker 2 the quantile itself and 1 and 3 the points halfway to it.
+type p2 struct {
+	p       float64
+	n       int        // observations so far
+	height  [5]float64 // marker heights
+	pos     [5]float64 // actual marker positions, 1-based
+	desired [5]float64 // desired marker positions
+	inc     [5]float64 // desired position increment per observation
+}
+
+func (e *p2) init(p float64) {
+	e.p = p
+	e.inc = [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+}
+
+func (e *p2) add(x float64) {
+	if e.n < 5 {
+		// The first five observations are kept sorted as the initial markers.
+		e.height[e.n] = x
+		e.n++
+		if e.n == 5 {
+			slices.Sort(e.height[:])
+			p := e.p
+			e.pos = [5]float64{1, 2, 3, 4, 5}
+			e.desired = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+		}
+		return
+	}
+	e.n++
+
+	// Find the cell k with height[k] <= x < height[k+1], stretching the
+	// extremes if x is a new minimum or maximum.
+	var k int
+	switch {
+	case x < e.height[0]:
+		e.height[0] = x
+		k = 0
+	case x >= e.height[4]:
+		e.height[4] = max(e.height[4], x)
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= e.height[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.pos[i]++
+	}
+	for i := range e.desired {
+		e.desired[i] += e.inc[i]
+	}
+
+	// Move the middle markers one step towards their desired position when
+	// they are off by a whole position or more.
+	for i := 1; i <= 3; i++ {
+		d := e.desired[i] - e.pos[i]
+		if (d >= 1 && e.pos[i+1]-e.pos[i] > 1) || (d <= -1 && e.pos[i-1]-e.pos[i] < -1) {
+			s := 1.0
+			if d < 0 {
+				s = -1
+			}
+			h := e.parabolic(i, s)
+			if e.height[i-1] < h && h < e.height[i+1] {
+				e.height[i] = h
+			} else {
+				e.height[i] = e.linear(i, s)
+			}
+			e.pos[i] += s
+		}
+	}
+}
+
+func (e *p2) parabolic(i int, d float64) float64 {
+	q, n := e.height, e.pos
+	return q[i] + d/(n[i+1]-n[i-1])*((n[i]-n[i-1]+d)*(q[i+1]-q[i])/(n[i+1]-n[i])+
+		(n[i+1]-n[i]-d)*(q[i]-q[i-1])/(n[i]-n[i-1]))
+}
+
+func (e *p2) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return e.height[i] + d*(e.height[j]-e.height[i])/(e.pos[j]-e.pos[i])
+}
+
+func (e *p2) value() float64 {
+	switch {
+	case e.n == 0:
+		return nan
+	case e.n < 5:
+		// Too few observations for markers: use the nearest rank.
+		s := slices.Clone(e.height[:e.n])
+		slices.Sort(s)
+		return s[min(e.n-1, int(e.p*float64(e.n)))]
+	}
+	return e.height[2]
+}
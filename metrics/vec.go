@@ -0,0 +1,297 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// desc is the identity of an exported metric family.
+type desc struct {
+	name, help, typ string
+	labels          []string
+}
+
+func newDesc(opts Opts, typ string, labels []string, reserved string) *desc {
+	if !validName(opts.Name, true) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", opts.Name))
+	}
+	for i, l := range labels {
+		if !validName(l, false) || strings.HasPrefix(l, "__") || l == reserved {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", l, opts.Name))
+		}
+		if slices.Contains(labels[:i], l) {
+			panic(fmt.Sprintf("metrics: duplicate label %q for %s", l, opts.Name))
+		}
+	}
+	return &desc{name: opts.Name, help: opts.Help, typ: typ, labels: slices.Clone(labels)}
+}
+
+// validName checks a metric name ([a-zA-Z_:][a-zA-Z0-9_:]*) or, without
+// colons, a label name.
+func validName(s string, colon bool) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		ok := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(colon && c == ':') || (i > 0 && c >= '0' && c <= '9')
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// vec holds the children of a labelled metric, one per combination of label
+// values. Children are found by a hash of the values so a lookup allocates
+// nothing; values are compared in full to resolve collisions.
+type vec[M any] struct {
+	desc     *desc
+	newChild func() *M
+
+	mu       sync.RWMutex
+	children map[uint64][]*child[M]
+}
+
+type child[M any] struct {
+	values []string
+	m      *M
+}
+
+func newVec[M any](d *desc, newChild func() *M) vec[M] {
+	return vec[M]{desc: d, newChild: newChild, children: make(map[uint64][]*child[M])}
+}
+
+// hashValues is FNV-1a over the values with a separator byte that cannot
+// appear in UTF-8, so ("ab", "c") and ("a", "bc") differ.
+func hashValues(values []string) uint64 {
+	h := uint64(14695981039346656037)
+	for _, v := range values {
+		for i := 0; i < len(v); i++ {
+			h ^= uint64(v[i])
+			h *= 1099511628211
+		}
+		h ^= 0xff
+		h *= 1099511628211
+	}
+	return h
+}
+
+func (v *vec[M]) with(values []string) *M {
+	if len(values) != len(v.desc.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.desc.name, len(v.desc.labels), len(values)))
+	}
+	h := hashValues(values)
+	v.mu.RLock()
+	for _, c := range v.children[h] {
+		if slices.Equal(c.values, values) {
+			v.mu.RUnlock()
+			return c.m
+		}
+	}
+	v.mu.RUnlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, c := range v.children[h] {
+		if slices.Equal(c.values, values) {
+			return c.m
+		}
+	}
+	c := &child[M]{values: slices.Clone(values), m: v.newChild()}
+	v.children[h] = append(v.children[h], c)
+	return c.m
+}
+
+func (v *vec[M]) delete(values []string) bool {
+	h := hashValues(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	cs := v.children[h]
+	for i, c := range cs {
+		if slices.Equal(c.values, values) {
+			if len(cs) == 1 {
+				delete(v.children, h)
+			} else {
+				v.children[h] = slices.Delete(cs, i, i+1)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (v *vec[M]) reset() {
+	v.mu.Lock()
+	clear(v.children)
+	v.mu.Unlock()
+}
+
+// sorted returns the children ordered by label values for stable output.
+func (v *vec[M]) sorted() []*child[M] {
+	v.mu.RLock()
+	out := make([]*child[M], 0, len(v.children))
+	for _, cs := range v.children {
+		out = append(out, cs...)
+	}
+	v.mu.RUnlock()
+	slices.SortFunc(out, func(a, b *child[M]) int { return slices.Compare(a.values, b.values) })
+	return out
+}
+
+func (v *vec[M]) describe() *desc { return v.desc }
+
+// CounterVec is a family of counters partitioned by label values.
+type CounterVec struct{ vec[Counter] }
+
+// NewCounterVec returns a counter family with the given label names. It
+// panics if the name or a label name is invalid.
+func NewCounterVec(opts Opts, labels ...string) *CounterVec {
+	return &CounterVec{newVec(newDesc(opts, "counter", labels, ""), func() *Counter { return new(Counter) })}
+}
+
+// With returns the counter for the label values, creating it on first use.
+// Keep the result to skip the lookup on hot paths.
+func (v *CounterVec) With(values ...string) *Counter { return v.with(values) }
+
+// Delete removes the counter for the label values.
+func (v *CounterVec) Delete(values ...string) bool { return v.delete(values) }
+
+// Reset removes every counter.
+func (v *CounterVec) Reset() { v.reset() }
+
+func (v *CounterVec) collect(w *expWriter) {
+	for _, c := range v.sorted() {
+		w.sample(v.desc, "", c.values, "", "", c.m.Value())
+	}
+}
+
+// GaugeVec is a family of gauges partitioned by label values.
+type GaugeVec struct{ vec[Gauge] }
+
+// NewGaugeVec returns a gauge family with the given label names.
+func NewGaugeVec(opts Opts, labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(newDesc(opts, "gauge", labels, ""), func() *Gauge { return new(Gauge) })}
+}
+
+func (v *GaugeVec) With(values ...string) *Gauge { return v.with(values) }
+func (v *GaugeVec) Delete(values ...string) bool { return v.delete(values) }
+func (v *GaugeVec) Reset()                       { v.reset() }
+
+func (v *GaugeVec) collect(w *expWriter) {
+	for _, c := range v.sorted() {
+		w.sample(v.desc, "", c.values, "", "", c.m.Value())
+	}
+}
+
+// HistogramVec is a family of histograms partitioned by label values. All
+// children share the same buckets.
+type HistogramVec struct{ vec[Histogram] }
+
+// NewHistogramVec returns a histogram family with the given label names. It
+// panics if the buckets are not strictly increasing or a label is "le".
+func NewHistogramVec(opts HistogramOpts, labels ...string) *HistogramVec {
+	upper := opts.Buckets
+	if upper == nil {
+		upper = DefBuckets
+	}
+	if !validBuckets(upper) {
+		panic(fmt.Sprintf("metrics: buckets of %s must be strictly increasing", opts.Name))
+	}
+	upper = slices.Clone(upper)
+	if n := len(upper); n > 0 && upper[n-1] == inf {
+		upper = upper[:n-1] // +Inf is implied
+	}
+	d := newDesc(opts.Opts, "histogram", labels, "le")
+	return &HistogramVec{newVec(d, func() *Histogram { return newHistogram(upper) })}
+}
+
+func (v *HistogramVec) With(values ...string) *Histogram { return v.with(values) }
+func (v *HistogramVec) Delete(values ...string) bool     { return v.delete(values) }
+func (v *HistogramVec) Reset()                           { v.reset() }
+
+func (v *HistogramVec) collect(w *expWriter) {
+	for _, c := range v.sorted() {
+		c.m.write(w, v.desc, c.values)
+	}
+}
+
+func (h *Histogram) describe() *desc      { return h.desc }
+func (h *Histogram) collect(w *expWriter) { h.write(w, h.desc, nil) }
+
+func (h *Histogram) write(w *expWriter, d *desc, values []string) {
+	upper, cum := h.Buckets()
+	for i, u := range upper {
+		w.sample(d, "_bucket", values, "le", formatFloat(u), float64(cum[i]))
+	}
+	w.sample(d, "_sum", values, "", "", h.Sum())
+	w.sample(d, "_count", values, "", "", float64(cum[len(cum)-1]))
+}
+
+// SummaryVec is a family of summaries partitioned by label values.
+type SummaryVec struct{ vec[Summary] }
+
+// NewSummaryVec returns a summary family with the given label names. It
+// panics if a quantile is outside (0, 1) or a label is "quantile".
+func NewSummaryVec(opts SummaryOpts, labels ...string) *SummaryVec {
+	qs := opts.Quantiles
+	if qs == nil {
+		qs = DefQuantiles
+	}
+	for _, q := range qs {
+		if !(q > 0 && q < 1) {
+			panic(fmt.Sprintf("metrics: quantile %v of %s outside (0, 1)", q, opts.Name))
+		}
+	}
+	qs = slices.Clone(qs)
+	d := newDesc(opts.Opts, "summary", labels, "quantile")
+	return &SummaryVec{newVec(d, func() *Summary { return newSummary(qs) })}
+}
+
+func (v *SummaryVec) With(values ...string) *Summary { return v.with(values) }
+func (v *SummaryVec) Delete(values ...string) bool   { return v.delete(values) }
+func (v *SummaryVec) Reset()                         { v.reset() }
+
+func (v *SummaryVec) collect(w *expWriter) {
+	for _, c := range v.sorted() {
+		c.m.write(w, v.desc, c.values)
+	}
+}
+
+func (s *Summary) describe() *desc      { return s.desc }
+func (s *Summary) collect(w *expWriter) { s.write(w, s.desc, nil) }
+
+func (s *Summary) write(w *expWriter, d *desc, values []string) {
+	qs, vals := s.Quantiles()
+	for i, q := range qs {
+		w.sample(d, "", values, "quantile", formatFloat(q), vals[i])
+	}
+	w.sample(d, "_sum", values, "", "", s.Sum())
+	w.sample(d, "_count", values, "", "", float64(s.Count()))
+}
+
+// funcMetric is a gauge or counter whose value is read at scrape time.
+type funcMetric struct {
+	desc *desc
+	fn   func() float64
+}
+
+// NewGaugeFunc returns a gauge whose value is fn(), called on every scrape,
+// e.g. runtime.NumGoroutine.
+func NewGaugeFunc(opts Opts, fn func() float64) Collector {
+	return &funcMetric{newDesc(opts, "gauge", nil, ""), fn}
+}
+
+// NewCounterFunc returns a counter whose value is fn(), which must never
+// decrease.
+func NewCounterFunc(opts Opts, fn func() float64) Collector {
+	return &funcMetric{newDesc(opts, "counter", nil, ""), fn}
+}
+
+func (f *funcMetric) describe() *desc { return f.desc }
+func (f *funcMetric) collect(w *expWriter) {
+	w.sample(f.desc, "", nil, "", "", f.fn())
+}
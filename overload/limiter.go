@@ -0,0 +1,118 @@
+/*
+Package overload protects an http.Handler from the failure mode described in
+concept/Networking/what_happen_when_Go_http_server_run_out_of_Goroutines.md:
+net/http starts a goroutine for every request, so when handlers are slow the
+goroutine count, memory and latency grow without bound.
+
+Limiter caps the number of handlers running at once with a channel semaphore,
+lets a bounded number of requests wait for a slot for at most QueueTimeout, and
+sheds everything else with 503 Service Unavailable so overload turns into fast,
+cheap rejections instead of an ever-growing backlog.
+*/
+package overload
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter is a max-in-flight middleware with a bounded wait queue.
+type Limiter struct {
+	sem          chan struct{}
+	maxQueue     int64
+	queueTimeout time.Duration
+
+	queued   atomic.Int64
+	served   atomic.Int64
+	rejected atomic.Int64 // queue full
+	timedOut atomic.Int64 // waited QueueTimeout without getting a slot
+	canceled atomic.Int64 // client went away while queued
+}
+
+// NewLimiter allows maxInFlight handlers to run and up to maxQueue requests
+// to wait for at most queueTimeout. A zero maxQueue sheds as soon as all slots
+// are busy.
+func NewLimiter(maxInFlight, maxQueue int, queueTimeout time.Duration) *Limiter {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &Limiter{
+		sem:          make(chan struct{}, maxInFlight),
+		maxQueue:     int64(maxQueue),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Stats is a point-in-time view of a Limiter.
+type Stats struct {
+	InFlight int
+	Queued   int
+	Served   int64
+	Rejected int64
+	TimedOut int64
+	Canceled int64
+}
+
+// Shed is the number of requests answered with 503.
+func (s Stats) Shed() int64 { return s.Rejected + s.TimedOut }
+
+// Stats returns the current counters.
+func (l *Limiter) Stats() Stats {
+	return Stats{
+		InFlight: len(l.sem),
+		Queued:   int(l.queued.Load()),
+		Served:   l.served.Load(),
+		Rejected: l.rejected.Load(),
+		TimedOut: l.timedOut.Load(),
+		Canceled: l.canceled.Load(),
+	}
+}
+
+// Wrap returns next guarded by the limiter.
+func (l *Limiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.acquire(w, r) {
+			return
+		}
+		defer func() { <-l.sem }()
+		l.served.Add(1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire takes a slot, answering the request itself when it cannot.
+func (l *Limiter) acquire(w http.ResponseWriter, r *http.Request) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queued.Add(1) > l.maxQueue {
+		l.queued.Add(-1)
+		l.rejected.Add(1)
+		shed(w, "server busy")
+		return false
+	}
+	defer l.queued.Add(-1)
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		l.timedOut.Add(1)
+		shed(w, "queue timeout")
+		return false
+	case <-r.Context().Done():
+		l.canceled.Add(1)
+		return false
+	}
+}
+
+func shed(w http.ResponseWriter, msg string) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, msg, http.StatusServiceUnavailable)
+}
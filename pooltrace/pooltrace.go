@@ -0,0 +1,319 @@
+/*
+Package pooltrace makes the http.Transport connection pool described in
+concept/Networking/Pool_In_Go.md observable.
+
+Transport wraps an *http.Transport and attaches an httptrace.ClientTrace to
+every request. Per host it counts requests served on new vs reused
+connections, measures DNS, dial, TLS and wait-for-connection latency, and
+tracks what happens to a connection after its response body is closed: either
+it goes back to the idle pool (and may later be evicted by IdleConnTimeout) or
+it is discarded because MaxIdleConnsPerHost is already reached. An idle
+connection that the server hangs up on is counted apart from one the
+transport evicts.
+*/
+package pooltrace
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dist accumulates a latency distribution cheaply.
+type Dist struct {
+	Count int64
+	Total time.Duration
+	Max   time.Duration
+}
+
+func (d *Dist) add(v time.Duration) {
+	d.Count++
+	d.Total += v
+	d.Max = max(d.Max, v)
+}
+
+// Mean returns the average, zero when empty.
+func (d Dist) Mean() time.Duration {
+	if d.Count == 0 {
+		return 0
+	}
+	return d.Total / time.Duration(d.Count)
+}
+
+// HostStats are the counters for one host:port.
+type HostStats struct {
+	Requests     int64
+	NewConns     int64 // requests that had to dial
+	ReusedConns  int64 // requests served on a pooled connection
+	IdleReturned int64 // connections put back into the idle pool
+	IdleRejected int64 // connections closed instead, pool full or not reusable
+	IdleEvicted  int64 // idle connections closed by the transport (IdleConnTimeout, CloseIdleConnections)
+	IdleDropped  int64 // idle connections the server closed first
+	Open         int64 // connections currently open
+
+	DNS      Dist
+	Dial     Dist
+	TLS      Dist
+	Wait     Dist // GetConn to GotConn, includes dialing when the pool was empty
+	IdleTime Dist // how long reused connections sat idle
+}
+
+// ReuseRatio is the fraction of requests that did not need a new connection.
+func (s HostStats) ReuseRatio() float64 {
+	if n := s.NewConns + s.ReusedConns; n > 0 {
+		return float64(s.ReusedConns) / float64(n)
+	}
+	return 0
+}
+
+// Transport is an http.RoundTripper that records pool behaviour.
+type Transport struct {
+	base *http.Transport
+
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+// New instruments base. base must not be shared with other callers because its
+// DialContext is replaced by one that tracks connection lifetimes.
+func New(base *http.Transport) *Transport {
+	t := &Transport{base: base, hosts: make(map[string]*HostStats)}
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		t.update(addr, func(s *HostStats) { s.Open++ })
+		return &trackedConn{Conn: c, t: t, host: addr}, nil
+	}
+	return t
+}
+
+// Base returns the wrapped transport.
+func (t *Transport) Base() *http.Transport { return t.base }
+
+// CloseIdleConnections closes pooled connections; they are counted as evicted.
+func (t *Transport) CloseIdleConnections() { t.base.CloseIdleConnections() }
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := canonicalAddr(req)
+	// The dial callbacks run on the transport's dial goroutine, which can
+	// outlive the request, so every variable they share is under mu.
+	var (
+		mu                                     sync.Mutex
+		getConn, dnsStart, connStart, tlsStart time.Time
+		conn                                   *trackedConn
+		use                                    int64 // conn's dispatch that served this request
+	)
+	mark := func(start *time.Time) {
+		mu.Lock()
+		*start = time.Now()
+		mu.Unlock()
+	}
+	since := func(start *time.Time) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Since(*start)
+	}
+	trace := &httptrace.ClientTrace{
+		GetConn:  func(string) { mark(&getConn) },
+		DNSStart: func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			d := since(&dnsStart)
+			t.update(host, func(s *HostStats) { s.DNS.add(d) })
+		},
+		ConnectStart: func(string, string) { mark(&connStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				d := since(&connStart)
+				t.update(host, func(s *HostStats) { s.Dial.add(d) })
+			}
+		},
+		TLSHandshakeStart: func() { mark(&tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				d := since(&tlsStart)
+				t.update(host, func(s *HostStats) { s.TLS.add(d) })
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			wait := since(&getConn)
+			mu.Lock()
+			conn = unwrap(info.Conn)
+			if conn != nil {
+				use = conn.dispatch()
+			}
+			mu.Unlock()
+			t.update(host, func(s *HostStats) {
+				s.Wait.add(wait)
+				if info.Reused {
+					s.ReusedConns++
+					if info.WasIdle {
+						s.IdleTime.add(info.IdleTime)
+					}
+				} else {
+					s.NewConns++
+				}
+			})
+		},
+		PutIdleConn: func(err error) {
+			mu.Lock()
+			if err == nil && conn != nil {
+				conn.release(use)
+			}
+			mu.Unlock()
+			t.update(host, func(s *HostStats) {
+				if err == nil {
+					s.IdleReturned++
+				} else {
+					s.IdleRejected++
+				}
+			})
+		},
+	}
+	t.update(host, func(s *HostStats) { s.Requests++ })
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	return t.base.RoundTrip(req)
+}
+
+// Snapshot returns a copy of the per-host counters.
+func (t *Transport) Snapshot() map[string]HostStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]HostStats, len(t.hosts))
+	for h, s := range t.hosts {
+		out[h] = *s
+	}
+	return out
+}
+
+// Reset zeroes all counters except the number of open connections.
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for h, s := range t.hosts {
+		t.hosts[h] = &HostStats{Open: s.Open}
+	}
+}
+
+// WriteReport prints one block per host.
+func (t *Transport) WriteReport(w io.Writer) error {
+	snap := t.Snapshot()
+	hosts := make([]string, 0, len(snap))
+	for h := range snap {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	for _, h := range hosts {
+		s := snap[h]
+		_, err := fmt.Fprintf(w, "%s\n  requests %d  new %d  reused %d (%.0f%%)  open %d\n"+
+			"  idle: returned %d  rejected %d  evicted %d  dropped by server %d  mean idle %v\n"+
+			"  latency: wait %v (max %v)  dial %v  tls %v  dns %v\n",
+			h, s.Requests, s.NewConns, s.ReusedConns, s.ReuseRatio()*100, s.Open,
+			s.IdleReturned, s.IdleRejected, s.IdleEvicted, s.IdleDropped, s.IdleTime.Mean(),
+			s.Wait.Mean(), s.Wait.Max, s.Dial.Mean(), s.TLS.Mean(), s.DNS.Mean())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Transport) update(host string, f func(*HostStats)) {
+	t.mu.Lock()
+	s := t.hosts[host]
+	if s == nil {
+		s = new(HostStats)
+		t.hosts[host] = s
+	}
+	f(s)
+	t.mu.Unlock()
+}
+
+// trackedConn notices when the transport closes a connection that is sitting
+// in the idle pool, which is the only way to observe evictions from outside.
+type trackedConn struct {
+	net.Conn
+	t    *Transport
+	host string
+
+	// uses counts dispatches. state is n while dispatch n has the
+	// connection and -n once it went back to the pool after it, so a late
+	// PutIdleConn cannot mark a connection idle that was handed out again.
+	uses  atomic.Int64
+	state atomic.Int64
+
+	// readFailed is set when a read fails before Close: the server hung up
+	// and the transport is only cleaning up.
+	readFailed atomic.Bool
+	closed     atomic.Bool
+}
+
+// dispatch records that a request got the connection and returns its
+// dispatch number.
+func (c *trackedConn) dispatch() int64 {
+	n := c.uses.Add(1)
+	c.state.Store(n)
+	return n
+}
+
+// release marks the connection idle, unless a later dispatch already took it.
+func (c *trackedConn) release(n int64) { c.state.CompareAndSwap(n, -n) }
+
+func (c *trackedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if err != nil && !c.closed.Load() {
+		c.readFailed.Store(true)
+	}
+	return n, err
+}
+
+func (c *trackedConn) Close() error {
+	if c.closed.CompareAndSwap(false, true) {
+		idle, dropped := c.state.Load() < 0, c.readFailed.Load()
+		c.t.update(c.host, func(s *HostStats) {
+			s.Open--
+			switch {
+			case idle && dropped:
+				s.IdleDropped++
+			case idle:
+				s.IdleEvicted++
+			}
+		})
+	}
+	return c.Conn.Close()
+}
+
+func unwrap(c net.Conn) *trackedConn {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	tc, _ := c.(*trackedConn)
+	return tc
+}
+
+// canonicalAddr returns host:port the way the transport dials it, so request
+// side and dial side counters land under the same key.
+func canonicalAddr(req *http.Request) string {
+	host, port := req.URL.Hostname(), req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
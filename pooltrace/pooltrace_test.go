@@ -0,0 +1,119 @@
+package pooltrace_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang/pooltrace"
+)
+
+func get(t *testing.T, c *http.Client, url string) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func only(t *testing.T, tr *pooltrace.Transport) pooltrace.HostStats {
+	t.Helper()
+	snap := tr.Snapshot()
+	if len(snap) != 1 {
+		t.Fatalf("stats for %d hosts, want 1", len(snap))
+	}
+	for _, s := range snap {
+		return s
+	}
+	panic("unreachable")
+}
+
+// eventually polls f, since connections are closed on the transport's own
+// goroutines.
+func eventually(t *testing.T, what string, f func() bool) {
+	t.Helper()
+	for range 200 {
+		if f() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal(what)
+}
+
+func TestReuseAndEviction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	tr := pooltrace.New(srv.Client().Transport.(*http.Transport).Clone())
+	c := &http.Client{Transport: tr}
+
+	for range 3 {
+		get(t, c, srv.URL)
+	}
+	s := only(t, tr)
+	if s.Requests != 3 || s.NewConns != 1 || s.ReusedConns != 2 || s.IdleReturned != 3 || s.Open != 1 {
+		t.Fatalf("after 3 sequential requests: %+v", s)
+	}
+	tr.CloseIdleConnections()
+	eventually(t, "CloseIdleConnections not counted as an eviction", func() bool {
+		s := only(t, tr)
+		return s.IdleEvicted == 1 && s.IdleDropped == 0 && s.Open == 0
+	})
+}
+
+func TestServerCloseIsNotEviction(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	srv.Config.IdleTimeout = 10 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+	tr := pooltrace.New(srv.Client().Transport.(*http.Transport).Clone())
+	c := &http.Client{Transport: tr}
+
+	get(t, c, srv.URL)
+	eventually(t, "server close of an idle connection not counted as dropped", func() bool {
+		s := only(t, tr)
+		return s.IdleDropped == 1 && s.IdleEvicted == 0 && s.Open == 0
+	})
+}
+
+// TestConcurrentRequests is mostly for the race detector: trace callbacks
+// run on dial goroutines and connections are returned and reused while
+// other requests are in flight.
+func TestConcurrentRequests(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	base := srv.Client().Transport.(*http.Transport).Clone()
+	base.MaxIdleConnsPerHost = 2
+	tr := pooltrace.New(base)
+	c := &http.Client{Transport: tr}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				get(t, c, srv.URL)
+			}
+		}()
+	}
+	wg.Wait()
+	s := only(t, tr)
+	if s.Requests != 160 || s.NewConns+s.ReusedConns != 160 || s.IdleReturned+s.IdleRejected != 160 {
+		t.Fatalf("after 160 concurrent requests: %+v", s)
+	}
+	if s.IdleEvicted != 0 {
+		t.Fatalf("%d evictions with no idle timeout", s.IdleEvicted)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+package reactor
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Conn is one accepted connection. It is owned by the reactor loop and must
+// only be used from Handler callbacks.
+type Conn struct {
+	r      *Reactor
+	fd     int
+	remote string
+	out    []byte // bytes the kernel did not accept yet
+	closed bool
+
+	closeAfterFlush bool
+	watchingOut     bool
+
+	// Context is free for the handler to keep per-connection state in.
+	Context any
+}
+
+// Fd returns the underlying socket descriptor.
+func (c *Conn) Fd() int { return c.fd }
+
+// RemoteAddr returns the peer address as host:port.
+func (c *Conn) RemoteAddr() string { return c.remote }
+
+// Write sends p without blocking. Whatever the socket buffer cannot take right
+// now is queued and flushed when epoll reports the fd writable again.
+func (c *Conn) Write(p []byte) {
+	if c.closed || c.closeAfterFlush {
+		return
+	}
+	if len(c.out) == 0 {
+		n, err := write(c.fd, p)
+		if err != nil {
+			c.r.closeConn(c, err)
+			return
+		}
+		p = p[n:]
+	}
+	if len(p) == 0 {
+		return
+	}
+	c.out = append(c.out, p...)
+	c.watchOut(true)
+}
+
+// Close closes the connection once all queued output has been written.
+func (c *Conn) Close() {
+	if c.closed {
+		return
+	}
+	if len(c.out) > 0 {
+		c.closeAfterFlush = true
+		return
+	}
+	c.r.closeConn(c, nil)
+}
+
+// Buffered reports how many bytes are waiting for the socket to drain.
+func (c *Conn) Buffered() int { return len(c.out) }
+
+func (c *Conn) flush() error {
+	n, err := write(c.fd, c.out)
+	if err != nil {
+		return err
+	}
+	c.out = c.out[:copy(c.out, c.out[n:])]
+	if len(c.out) > 0 {
+		return nil
+	}
+	c.watchOut(false)
+	if c.closeAfterFlush {
+		c.r.closeConn(c, nil)
+	}
+	return nil
+}
+
+// watchOut toggles EPOLLOUT interest. Staying subscribed while there is
+// nothing to write would make a level-triggered loop spin.
+func (c *Conn) watchOut(on bool) {
+	if c.watchingOut == on {
+		return
+	}
+	c.watchingOut = on
+	events := uint32(syscall.EPOLLIN | syscall.EPOLLRDHUP)
+	if on {
+		events |= syscall.EPOLLOUT
+	}
+	if err := c.r.ctl(syscall.EPOLL_CTL_MOD, c.fd, events); err != nil {
+		c.r.closeConn(c, err)
+	}
+}
+
+// write writes as much of p as the socket accepts, returning a nil error on
+// EAGAIN.
+func write(fd int, p []byte) (int, error) {
+	total := 0
+	for total < len(p) {
+		n, err := syscall.Write(fd, p[total:])
+		switch {
+		case errors.Is(err, syscall.EAGAIN):
+			return total, nil
+		case errors.Is(err, syscall.EINTR):
+			continue
+		case err != nil:
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
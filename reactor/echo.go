@@ -0,0 +1,10 @@
+//go:build linux
+
+package reactor
+
+// Echo writes every byte it receives back to the sender.
+type Echo struct{}
+
+func (Echo) OnOpen(*Conn)                {}
+func (Echo) OnData(c *Conn, data []byte) { c.Write(data) }
+func (Echo) OnClose(*Conn, error)        {}
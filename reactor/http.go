@@ -0,0 +1,146 @@
+//go:build linux
+
+package reactor
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxHeaderBytes bounds how much an unfinished request head may buffer.
+	maxHeaderBytes = 8 << 10
+	// maxBodyBytes bounds a request body; larger ones are refused before
+	// any of the body is buffered.
+	maxBodyBytes = 1 << 20
+)
+
+// Request is the part of an HTTP/1.1 request the minimal server understands.
+// Header keys are canonicalised with http.CanonicalHeaderKey.
+type Request struct {
+	Method string
+	Path   string
+	Proto  string
+	Header map[string]string
+	Body   []byte
+}
+
+// Response is written back with a Content-Length, so bodies are never chunked.
+type Response struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
+
+// HTTP is a Handler that speaks just enough HTTP/1.1 to serve small
+// keep-alive and pipelined requests. It exists to compare the reactor with
+// net/http, not to replace it.
+type HTTP struct {
+	Serve func(*Request) Response
+}
+
+func (h HTTP) OnOpen(c *Conn)           { c.Context = new(bytes.Buffer) }
+func (h HTTP) OnClose(c *Conn, _ error) { c.Context = nil }
+
+func (h HTTP) OnData(c *Conn, data []byte) {
+	in := c.Context.(*bytes.Buffer)
+	in.Write(data)
+	for {
+		req, n, status := parseRequest(in.Bytes())
+		if status != 0 {
+			writeResponse(c, "HTTP/1.1", Response{Status: status}, false)
+			c.Close()
+			return
+		}
+		if req == nil {
+			return // need more bytes
+		}
+		in.Next(n)
+		keepAlive := wantsKeepAlive(req)
+		writeResponse(c, req.Proto, h.Serve(req), keepAlive)
+		if !keepAlive {
+			c.Close()
+			return
+		}
+	}
+}
+
+// parseRequest returns the first complete request in buf and how many bytes
+// it used. A nil request with status 0 means buf holds only part of one; a
+// non-zero status is the error to answer with.
+func parseRequest(buf []byte) (req *Request, n int, status int) {
+	end := bytes.Index(buf, []byte("\r\n\r\n"))
+	if end < 0 {
+		if len(buf) > maxHeaderBytes {
+			return nil, 0, http.StatusRequestHeaderFieldsTooLarge
+		}
+		return nil, 0, 0
+	}
+	lines := strings.Split(string(buf[:end]), "\r\n")
+	parts := strings.SplitN(lines[0], " ", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/1.") {
+		return nil, 0, http.StatusBadRequest
+	}
+	req = &Request{Method: parts[0], Path: parts[1], Proto: parts[2], Header: make(map[string]string)}
+	for _, l := range lines[1:] {
+		k, v, ok := strings.Cut(l, ":")
+		if !ok {
+			return nil, 0, http.StatusBadRequest
+		}
+		req.Header[http.CanonicalHeaderKey(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+
+	n = end + 4
+	cl, te := req.Header["Content-Length"], req.Header["Transfer-Encoding"]
+	if cl != "" && te != "" {
+		// The two framings disagree on where the body ends, the classic
+		// request smuggling setup (RFC 9112 section 6.1); refuse it.
+		return nil, 0, http.StatusBadRequest
+	}
+	if cl != "" {
+		size, err := strconv.Atoi(cl)
+		if err != nil || size < 0 {
+			return nil, 0, http.StatusBadRequest
+		}
+		if size > maxBodyBytes {
+			return nil, 0, http.StatusRequestEntityTooLarge
+		}
+		if len(buf) < n+size {
+			return nil, 0, 0
+		}
+		req.Body = buf[n : n+size : n+size]
+		n += size
+	} else if te != "" {
+		return nil, 0, http.StatusNotImplemented
+	}
+	return req, n, 0
+}
+
+func wantsKeepAlive(r *Request) bool {
+	conn := strings.ToLower(r.Header["Connection"])
+	if r.Proto == "HTTP/1.0" {
+		return conn == "keep-alive"
+	}
+	return conn != "close"
+}
+
+func writeResponse(c *Conn, proto string, resp Response, keepAlive bool) {
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	var b bytes.Buffer
+	b.WriteString(proto + " " + strconv.Itoa(resp.Status) + " " + http.StatusText(resp.Status) + "\r\n")
+	for k, v := range resp.Header {
+		b.WriteString(k + ": " + v + "\r\n")
+	}
+	b.WriteString("Content-Length: " + strconv.Itoa(len(resp.Body)) + "\r\n")
+	if keepAlive {
+		b.WriteString("Connection: keep-alive\r\n\r\n")
+	} else {
+		b.WriteString("Connection: close\r\n\r\n")
+	}
+	b.Write(resp.Body)
+	c.Write(b.Bytes())
+}
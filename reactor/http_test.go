@@ -0,0 +1,47 @@
+//go:build linux
+
+package reactor
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		in     string
+		n      int // bytes used, 0 when incomplete or refused
+		body   string
+		status int
+	}{
+		{"get", "GET / HTTP/1.1\r\nHost: x\r\n\r\n", 27, "", 0},
+		{"pipelined", "GET / HTTP/1.1\r\n\r\nGET /b HTTP/1.1\r\n\r\n", 18, "", 0},
+		{"body", "POST / HTTP/1.1\r\nContent-Length: 3\r\n\r\nabcGET", 41, "abc", 0},
+		{"partial head", "GET / HTTP/1.1\r\nHost:", 0, "", 0},
+		{"partial body", "POST / HTTP/1.1\r\nContent-Length: 5\r\n\r\nab", 0, "", 0},
+		{"bad request line", "GET /\r\n\r\n", 0, "", http.StatusBadRequest},
+		{"bad length", "POST / HTTP/1.1\r\nContent-Length: -1\r\n\r\n", 0, "", http.StatusBadRequest},
+		{"chunked", "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n", 0, "", http.StatusNotImplemented},
+		{"length and chunked", "POST / HTTP/1.1\r\nContent-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\n",
+			0, "", http.StatusBadRequest},
+		{"body too large", "POST / HTTP/1.1\r\nContent-Length: " + strconv.Itoa(maxBodyBytes+1) + "\r\n\r\n",
+			0, "", http.StatusRequestEntityTooLarge},
+		{"head too large", "GET / HTTP/1.1\r\nX: " + strings.Repeat("a", maxHeaderBytes), 0, "", http.StatusRequestHeaderFieldsTooLarge},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, n, status := parseRequest([]byte(tc.in))
+			if status != tc.status || n != tc.n {
+				t.Fatalf("n %d, status %d; want %d, %d", n, status, tc.n, tc.status)
+			}
+			if (req != nil) != (tc.n > 0) {
+				t.Fatalf("request %+v", req)
+			}
+			if req != nil && string(req.Body) != tc.body {
+				t.Fatalf("body %q, want %q", req.Body, tc.body)
+			}
+		})
+	}
+}
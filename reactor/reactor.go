@@ -0,0 +1,291 @@
+//go:build linux
+
+/*
+Package reactor is a single-threaded event loop built directly on epoll, the
+same kernel interface the Go netpoller uses on Linux
+(advance-concept/8. Network & I/O Internals).
+
+Every socket is non-blocking. The loop blocks only in epoll_wait, and each ready
+fd is handled until it would block (EAGAIN). In LevelTriggered mode a single
+read per wakeup is enough because epoll keeps reporting the fd while data is
+left. In EdgeTriggered mode the kernel reports each transition only once, so the
+loop must drain accept/read/write until EAGAIN or it will stall.
+*/
+package reactor
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+)
+
+// Mode selects how fds are registered with epoll.
+type Mode int
+
+const (
+	LevelTriggered Mode = iota
+	EdgeTriggered
+)
+
+func (m Mode) String() string {
+	if m == EdgeTriggered {
+		return "edge-triggered"
+	}
+	return "level-triggered"
+}
+
+// Handler reacts to connection events. All callbacks run on the loop
+// goroutine, so they must not block.
+type Handler interface {
+	// OnOpen is called once a connection has been accepted.
+	OnOpen(c *Conn)
+	// OnData is called with newly read bytes. data is only valid during the call.
+	OnData(c *Conn, data []byte)
+	// OnClose is called after the connection has been closed, err is nil on EOF.
+	OnClose(c *Conn, err error)
+}
+
+// Reactor owns an epoll instance, a listening socket and its connections.
+type Reactor struct {
+	mode    Mode
+	epfd    int
+	lfd     int
+	wakeR   int // read end of the self-pipe used by Close
+	wakeW   int
+	handler Handler
+	conns   map[int]*Conn
+	buf     []byte
+
+	closeOnce sync.Once
+}
+
+// Listen creates a non-blocking TCP listener on addr ("127.0.0.1:0" picks a
+// free port) and registers it with a new epoll instance.
+func Listen(addr string, mode Mode, h Handler) (*Reactor, error) {
+	tcp, err := net.ResolveTCPAddr("tcp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	lfd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM|syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("reactor: socket: %w", err)
+	}
+	sa := &syscall.SockaddrInet4{Port: tcp.Port}
+	copy(sa.Addr[:], tcp.IP.To4())
+	if err := syscall.SetsockoptInt(lfd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
+		syscall.Close(lfd)
+		return nil, fmt.Errorf("reactor: setsockopt: %w", err)
+	}
+	if err := syscall.Bind(lfd, sa); err != nil {
+		syscall.Close(lfd)
+		return nil, fmt.Errorf("reactor: bind %s: %w", addr, err)
+	}
+	if err := syscall.Listen(lfd, syscall.SOMAXCONN); err != nil {
+		syscall.Close(lfd)
+		return nil, fmt.Errorf("reactor: listen: %w", err)
+	}
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		syscall.Close(lfd)
+		return nil, fmt.Errorf("reactor: epoll_create1: %w", err)
+	}
+	var pipe [2]int
+	if err := syscall.Pipe2(pipe[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+		syscall.Close(lfd)
+		syscall.Close(epfd)
+		return nil, fmt.Errorf("reactor: pipe2: %w", err)
+	}
+
+	r := &Reactor{
+		mode:    mode,
+		epfd:    epfd,
+		lfd:     lfd,
+		wakeR:   pipe[0],
+		wakeW:   pipe[1],
+		handler: h,
+		conns:   make(map[int]*Conn),
+		buf:     make([]byte, 64<<10),
+	}
+	if err := r.ctl(syscall.EPOLL_CTL_ADD, lfd, syscall.EPOLLIN); err != nil {
+		r.release()
+		return nil, err
+	}
+	if err := r.ctl(syscall.EPOLL_CTL_ADD, r.wakeR, syscall.EPOLLIN); err != nil {
+		r.release()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (r *Reactor) Addr() net.Addr {
+	sa, err := syscall.Getsockname(r.lfd)
+	if err != nil {
+		return nil
+	}
+	in := sa.(*syscall.SockaddrInet4)
+	return &net.TCPAddr{IP: net.IP(in.Addr[:]).To4(), Port: in.Port}
+}
+
+// Mode reports the trigger mode the reactor was created with.
+func (r *Reactor) Mode() Mode { return r.mode }
+
+// Run executes the event loop on the calling goroutine until Close is called.
+// It must only be called once.
+func (r *Reactor) Run() error {
+	defer r.release()
+	events := make([]syscall.EpollEvent, 256)
+	for {
+		n, err := syscall.EpollWait(r.epfd, events, -1)
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			return fmt.Errorf("reactor: epoll_wait: %w", err)
+		}
+		for _, ev := range events[:n] {
+			fd := int(ev.Fd)
+			switch {
+			case fd == r.wakeR:
+				return nil
+			case fd == r.lfd:
+				r.accept()
+			default:
+				if c := r.conns[fd]; c != nil {
+					r.serve(c, ev.Events)
+				}
+			}
+		}
+	}
+}
+
+// Close stops Run from another goroutine. Open connections are closed and
+// OnClose is called for each of them.
+func (r *Reactor) Close() error {
+	r.closeOnce.Do(func() {
+		syscall.Write(r.wakeW, []byte{1})
+	})
+	return nil
+}
+
+func (r *Reactor) release() {
+	for _, c := range r.conns {
+		r.closeConn(c, nil)
+	}
+	syscall.Close(r.lfd)
+	syscall.Close(r.epfd)
+	syscall.Close(r.wakeR)
+	syscall.Close(r.wakeW)
+}
+
+// epollET is syscall.EPOLLET as a uint32; the syscall constant is a negative
+// untyped int on linux and cannot be or-ed into EpollEvent.Events directly.
+const epollET uint32 = 1 << 31
+
+// ctl wraps epoll_ctl, adding EPOLLET when running edge-triggered.
+func (r *Reactor) ctl(op, fd int, events uint32) error {
+	if r.mode == EdgeTriggered {
+		events |= epollET
+	}
+	ev := syscall.EpollEvent{Events: events, Fd: int32(fd)}
+	if err := syscall.EpollCtl(r.epfd, op, fd, &ev); err != nil {
+		return fmt.Errorf("reactor: epoll_ctl fd %d: %w", fd, err)
+	}
+	return nil
+}
+
+func (r *Reactor) accept() {
+	for {
+		nfd, sa, err := syscall.Accept4(r.lfd, syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC)
+		if err != nil {
+			// EAGAIN: backlog drained. Anything else (EMFILE, ECONNABORTED)
+			// is dropped here and retried on the next wakeup.
+			return
+		}
+		syscall.SetsockoptInt(nfd, syscall.IPPROTO_TCP, syscall.TCP_NODELAY, 1)
+		c := &Conn{r: r, fd: nfd, remote: sockaddrString(sa)}
+		if err := r.ctl(syscall.EPOLL_CTL_ADD, nfd, syscall.EPOLLIN|syscall.EPOLLRDHUP); err != nil {
+			syscall.Close(nfd)
+			continue
+		}
+		r.conns[nfd] = c
+		r.handler.OnOpen(c)
+		if r.mode == LevelTriggered {
+			// One accept per wakeup; epoll reports the listener again if
+			// more connections are waiting.
+			return
+		}
+	}
+}
+
+func (r *Reactor) serve(c *Conn, events uint32) {
+	if events&syscall.EPOLLERR != 0 {
+		err, _ := syscall.GetsockoptInt(c.fd, syscall.SOL_SOCKET, syscall.SO_ERROR)
+		r.closeConn(c, syscall.Errno(err))
+		return
+	}
+	if events&syscall.EPOLLHUP != 0 {
+		// Both directions are shut, but the peer's last bytes may still be
+		// queued: hand them to the handler before closing.
+		r.read(c, true)
+		r.closeConn(c, nil)
+		return
+	}
+	if events&syscall.EPOLLOUT != 0 {
+		if err := c.flush(); err != nil {
+			r.closeConn(c, err)
+			return
+		}
+	}
+	if events&(syscall.EPOLLIN|syscall.EPOLLRDHUP) != 0 {
+		r.read(c, false)
+	}
+}
+
+// read hands incoming bytes to the handler. Level-triggered, one read per
+// wakeup is enough unless drain asks for everything up to EOF or EAGAIN.
+func (r *Reactor) read(c *Conn, drain bool) {
+	for !c.closed {
+		n, err := syscall.Read(c.fd, r.buf)
+		switch {
+		case errors.Is(err, syscall.EAGAIN):
+			return
+		case errors.Is(err, syscall.EINTR):
+			continue
+		case err != nil:
+			r.closeConn(c, err)
+			return
+		case n == 0:
+			r.closeConn(c, nil)
+			return
+		}
+		r.handler.OnData(c, r.buf[:n])
+		if r.mode == LevelTriggered && !drain {
+			return
+		}
+	}
+}
+
+func (r *Reactor) closeConn(c *Conn, err error) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	delete(r.conns, c.fd)
+	syscall.EpollCtl(r.epfd, syscall.EPOLL_CTL_DEL, c.fd, nil)
+	syscall.Close(c.fd)
+	r.handler.OnClose(c, err)
+}
+
+func sockaddrString(sa syscall.Sockaddr) string {
+	switch a := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return (&net.TCPAddr{IP: net.IP(a.Addr[:]), Port: a.Port}).String()
+	case *syscall.SockaddrInet6:
+		return (&net.TCPAddr{IP: net.IP(a.Addr[:]), Port: a.Port}).String()
+	}
+	return "unknown"
+}
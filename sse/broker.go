@@ -0,0 +1,306 @@
+/*
+Package sse fans topic events out to many HTTP clients with Server-Sent Events.
+
+The channel lessons (channels/rangeAndClose.go, concurrency/range_and_close.go)
+stream values from one producer to one consumer. A Broker keeps that shape per
+client: every subscriber gets its own buffered channel, and a goroutine per
+HTTP request ranges over it and writes text/event-stream frames.
+
+Each topic remembers its most recent events in a bounded ring buffer. A client
+that reconnects with Last-Event-ID receives what it missed from the ring before
+live events resume. A client that cannot keep up is handled by the broker's
+Policy rather than being allowed to stall the publisher or grow memory.
+*/
+package sse
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is one published message. IDs are assigned by the broker and grow
+// monotonically across all topics.
+type Event struct {
+	ID    uint64
+	Topic string
+	Type  string // the SSE "event:" field, "message" when empty
+	Data  []byte
+}
+
+// Policy decides what happens when a subscriber's queue is full.
+type Policy int
+
+const (
+	// Disconnect drops the subscriber as soon as its queue overflows.
+	// It reconnects with Last-Event-ID and catches up from the replay buffer.
+	Disconnect Policy = iota
+	// Wait blocks the publisher for up to Options.SendTimeout, then disconnects.
+	Wait
+	// DropEvents keeps the subscriber and skips events it has no room for.
+	DropEvents
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Disconnect:
+		return "disconnect"
+	case Wait:
+		return "wait"
+	case DropEvents:
+		return "drop-events"
+	}
+	return "Policy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// Options configures a Broker. Zero fields take the documented defaults.
+type Options struct {
+	Replay      int           // events kept per topic for Last-Event-ID, default 256
+	QueueSize   int           // per-subscriber buffer, default 64
+	Policy      Policy        // default Disconnect
+	SendTimeout time.Duration // for Wait, default 1s
+	Heartbeat   time.Duration // comment line sent on idle streams, default 15s
+	Retry       time.Duration // reconnect delay advertised to clients, default 2s
+}
+
+// ErrReplayGap means a subscriber asked to resume from an event that has
+// already fallen out of the replay buffer.
+var ErrReplayGap = errors.New("sse: requested events are no longer in the replay buffer")
+
+// Broker routes events from publishers to subscribers.
+type Broker struct {
+	opts Options
+
+	// pubMu serialises Publish so every subscriber sees events in ID order.
+	pubMu sync.Mutex
+
+	mu     sync.Mutex
+	nextID uint64
+	topics map[string]*topic
+
+	published    atomic.Int64
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+}
+
+type topic struct {
+	ring    []Event // ring[head] is the oldest event once the ring is full
+	head    int
+	evicted uint64 // ID of the newest event pushed out of the ring
+	subs    map[*Subscriber]struct{}
+}
+
+// NewBroker returns a broker with opts applied over the defaults.
+func NewBroker(opts Options) *Broker {
+	if opts.Replay <= 0 {
+		opts.Replay = 256
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 64
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = time.Second
+	}
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = 15 * time.Second
+	}
+	if opts.Retry <= 0 {
+		opts.Retry = 2 * time.Second
+	}
+	return &Broker{opts: opts, topics: make(map[string]*topic)}
+}
+
+// Stats are broker-wide counters.
+type Stats struct {
+	Published    int64
+	Dropped      int64 // events skipped under DropEvents
+	Disconnected int64 // subscribers kicked for being too slow
+	Subscribers  int
+}
+
+// Stats returns the current counters.
+func (b *Broker) Stats() Stats {
+	b.mu.Lock()
+	subs := 0
+	for _, t := range b.topics {
+		subs += len(t.subs)
+	}
+	b.mu.Unlock()
+	return Stats{
+		Published:    b.published.Load(),
+		Dropped:      b.dropped.Load(),
+		Disconnected: b.disconnected.Load(),
+		Subscribers:  subs,
+	}
+}
+
+func (b *Broker) topic(name string) *topic {
+	t := b.topics[name]
+	if t == nil {
+		t = &topic{subs: make(map[*Subscriber]struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// Publish assigns the next ID to an event, stores it for replay and delivers
+// it to the topic's subscribers.
+func (b *Broker) Publish(topicName, eventType string, data []byte) Event {
+	b.pubMu.Lock()
+	defer b.pubMu.Unlock()
+
+	b.mu.Lock()
+	b.nextID++
+	ev := Event{ID: b.nextID, Topic: topicName, Type: eventType, Data: data}
+	t := b.topic(topicName)
+	if len(t.ring) < b.opts.Replay {
+		t.ring = append(t.ring, ev)
+	} else {
+		t.evicted = t.ring[t.head].ID
+		t.ring[t.head] = ev
+		t.head = (t.head + 1) % len(t.ring)
+	}
+	subs := make([]*Subscriber, 0, len(t.subs))
+	for s := range t.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	b.published.Add(1)
+	for _, s := range subs {
+		b.deliver(s, ev)
+	}
+	return ev
+}
+
+func (b *Broker) deliver(s *Subscriber, ev Event) {
+	select {
+	case s.events <- ev:
+		return
+	case <-s.done:
+		return
+	default:
+	}
+	switch b.opts.Policy {
+	case DropEvents:
+		b.dropped.Add(1)
+		s.dropped.Add(1)
+	case Wait:
+		t := time.NewTimer(b.opts.SendTimeout)
+		defer t.Stop()
+		select {
+		case s.events <- ev:
+		case <-s.done:
+		case <-t.C:
+			b.kick(s)
+		}
+	default:
+		b.kick(s)
+	}
+}
+
+func (b *Broker) kick(s *Subscriber) {
+	if s.close() {
+		b.disconnected.Add(1)
+	}
+}
+
+// Subscriber is one consumer of one or more topics.
+type Subscriber struct {
+	b      *Broker
+	topics []string
+	events chan Event
+
+	// replay holds the missed events found at subscribe time, oldest first.
+	replay []Event
+
+	once    sync.Once
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// Subscribe registers a subscriber for topics. If lastID is non-zero, events
+// after it that are still in the replay buffers are returned by Replay. The
+// error is ErrReplayGap when some of the missed events have been overwritten;
+// the subscriber is still valid in that case.
+func (b *Broker) Subscribe(lastID uint64, topics ...string) (*Subscriber, error) {
+	s := &Subscriber{
+		b:      b,
+		topics: topics,
+		events: make(chan Event, b.opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	var err error
+	b.mu.Lock()
+	for _, name := range topics {
+		t := b.topic(name)
+		t.subs[s] = struct{}{}
+		if lastID == 0 {
+			continue
+		}
+		if t.evicted > lastID {
+			err = ErrReplayGap
+		}
+		n := len(t.ring)
+		for i := 0; i < n; i++ {
+			if ev := t.ring[(t.head+i)%n]; ev.ID > lastID {
+				s.replay = append(s.replay, ev)
+			}
+		}
+	}
+	b.mu.Unlock()
+	sortByID(s.replay)
+	return s, err
+}
+
+// Replay returns the events missed before subscribing, oldest first.
+func (s *Subscriber) Replay() []Event { return s.replay }
+
+// Events delivers live events, all newer than anything in Replay: the ring
+// snapshot and the registration happen under the same lock as Publish.
+func (s *Subscriber) Events() <-chan Event { return s.events }
+
+// Done is closed when the subscriber is closed or kicked.
+func (s *Subscriber) Done() <-chan struct{} { return s.done }
+
+// Dropped returns how many events were skipped under DropEvents.
+func (s *Subscriber) Dropped() int64 { return s.dropped.Load() }
+
+// Close unsubscribes from all topics.
+func (s *Subscriber) Close() { s.close() }
+
+// close reports whether this call was the one that closed the subscriber.
+func (s *Subscriber) close() bool {
+	closed := false
+	s.once.Do(func() {
+		closed = true
+		close(s.done)
+		s.b.mu.Lock()
+		for _, name := range s.topics {
+			t := s.b.topics[name]
+			if t == nil {
+				continue // named twice, already removed
+			}
+			delete(t.subs, s)
+			// A topic nobody published to only existed for its
+			// subscribers; drop it so ?topic= cannot grow the map.
+			if len(t.subs) == 0 && len(t.ring) == 0 {
+				delete(s.b.topics, name)
+			}
+		}
+		s.b.mu.Unlock()
+	})
+	return closed
+}
+
+// sortByID is an insertion sort: replay slices are short and each topic's
+// part is already ordered.
+func sortByID(evs []Event) {
+	for i := 1; i < len(evs); i++ {
+		for j := i; j > 0 && evs[j].ID < evs[j-1].ID; j-- {
+			evs[j], evs[j-1] = evs[j-1], evs[j]
+		}
+	}
+}
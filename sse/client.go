@@ -0,0 +1,132 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client consumes an event stream and reconnects the way a browser
+// EventSource does: it waits for the server's retry: delay and resumes with
+// Last-Event-ID so no event is lost while it was away.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client // http.DefaultClient when nil
+
+	// LastEventID is the resume point, updated as events arrive.
+	LastEventID uint64
+	// Reconnects counts how many times the stream had to be reopened.
+	Reconnects int
+	// OnGap is called when the server reports that events were lost.
+	OnGap func()
+
+	retry time.Duration
+}
+
+// Stream calls fn for every event until ctx is done or fn returns an error.
+func (c *Client) Stream(ctx context.Context, fn func(Event) error) error {
+	if c.retry == 0 {
+		c.retry = time.Second
+	}
+	for {
+		err := c.once(ctx, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if cb, ok := err.(callbackError); ok {
+			return cb.err
+		}
+		c.Reconnects++
+		select {
+		case <-time.After(c.retry):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// callbackError distinguishes fn's errors from connection errors.
+type callbackError struct{ err error }
+
+func (e callbackError) Error() string { return e.err.Error() }
+
+func (c *Client) once(ctx context.Context, fn func(Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return callbackError{err}
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.LastEventID != 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(c.LastEventID, 10))
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sse: unexpected status %s", resp.Status)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	var (
+		ev      Event
+		data    strings.Builder
+		hasData bool
+	)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			// Blank line: dispatch.
+			if hasData {
+				ev.Data = []byte(strings.TrimSuffix(data.String(), "\n"))
+				if ev.ID != 0 {
+					c.LastEventID = ev.ID
+				}
+				if ev.Type == "gap" {
+					if c.OnGap != nil {
+						c.OnGap()
+					}
+				} else if err := fn(ev); err != nil {
+					return callbackError{err}
+				}
+			}
+			ev, hasData = Event{}, false
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment, e.g. heartbeat
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID, _ = strconv.ParseUint(value, 10, 64)
+		case "topic":
+			ev.Topic = value
+		case "event":
+			ev.Type = value
+		case "data":
+			data.WriteString(value + "\n")
+			hasData = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				c.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("sse: stream ended")
+}
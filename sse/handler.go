@@ -0,0 +1,121 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServeHTTP streams the topics named by repeated ?topic= parameters. The
+// resume point comes from the Last-Event-ID header, which browsers send on
+// reconnect, or a lastEventId query parameter for the first connection.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	topics := r.URL.Query()["topic"]
+	if len(topics) == 0 {
+		http.Error(w, "at least one topic parameter is required", http.StatusBadRequest)
+		return
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	var last uint64
+	if lastID != "" {
+		var err error
+		if last, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub, err := b.Subscribe(last, topics...)
+	defer sub.Close()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("retry: " + strconv.FormatInt(b.opts.Retry.Milliseconds(), 10) + "\n\n")
+	if err == ErrReplayGap {
+		bw.WriteString("event: gap\ndata: some events were lost, resync required\n\n")
+	}
+	for _, ev := range sub.Replay() {
+		writeEvent(bw, ev)
+	}
+	if bw.Flush() != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(b.opts.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case ev := <-sub.Events():
+			writeEvent(bw, ev)
+			// Batch whatever else is already queued into the same flush.
+			for drained := false; !drained; {
+				select {
+				case ev := <-sub.Events():
+					writeEvent(bw, ev)
+				default:
+					drained = true
+				}
+			}
+		case <-heartbeat.C:
+			bw.WriteString(": heartbeat\n\n")
+		case <-sub.Done():
+			// Kicked for being slow; closing the response makes the
+			// client reconnect and catch up from the replay buffer.
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if bw.Flush() != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent encodes one event. Each line of the payload becomes its own
+// data: field, as the format requires; a line ends at "\r\n", "\r" or "\n",
+// the same set a client splits on. The topic goes in a topic: field, which
+// EventSource ignores and Client reads back into Event.Topic.
+func writeEvent(bw *bufio.Writer, ev Event) {
+	bw.WriteString("id: " + strconv.FormatUint(ev.ID, 10) + "\n")
+	if ev.Topic != "" {
+		bw.WriteString("topic: " + ev.Topic + "\n")
+	}
+	if ev.Type != "" {
+		bw.WriteString("event: " + ev.Type + "\n")
+	}
+	data := ev.Data
+	for {
+		i := bytes.IndexAny(data, "\r\n")
+		bw.WriteString("data: ")
+		if i < 0 {
+			bw.Write(data)
+			bw.WriteByte('\n')
+			break
+		}
+		bw.Write(data[:i])
+		bw.WriteByte('\n')
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		data = data[i+1:]
+	}
+	bw.WriteByte('\n')
+}
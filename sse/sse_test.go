@@ -0,0 +1,80 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUnpublishedTopicRemovedOnClose(t *testing.T) {
+	b := NewBroker(Options{})
+	b.Publish("news", "", []byte("x"))
+	s, _ := b.Subscribe(0, "news", "nobody-publishes-here", "nobody-publishes-here")
+	if len(b.topics) != 2 {
+		t.Fatalf("%d topics while subscribed, want 2", len(b.topics))
+	}
+	s.Close()
+	if _, ok := b.topics["nobody-publishes-here"]; ok || len(b.topics) != 1 {
+		t.Fatalf("topics after close: %v, want only news", b.topics)
+	}
+}
+
+func TestWriteEventSplitsLines(t *testing.T) {
+	for _, tc := range []struct{ data, want string }{
+		{"one", "data: one\n"},
+		{"a\nb", "data: a\ndata: b\n"},
+		{"a\r\nb", "data: a\ndata: b\n"},
+		{"a\rb", "data: a\ndata: b\n"},
+		{"a\n\rb", "data: a\ndata: \ndata: b\n"},
+		{"a\r\n", "data: a\ndata: \n"},
+		{"", "data: \n"},
+	} {
+		var buf bytes.Buffer
+		bw := bufio.NewWriter(&buf)
+		writeEvent(bw, Event{ID: 1, Data: []byte(tc.data)})
+		bw.Flush()
+		if want := "id: 1\n" + tc.want + "\n"; buf.String() != want {
+			t.Errorf("data %q encoded as %q, want %q", tc.data, buf.String(), want)
+		}
+	}
+}
+
+func TestClientReceivesTopic(t *testing.T) {
+	b := NewBroker(Options{Retry: 10 * time.Millisecond})
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	first := b.Publish("a", "", []byte("0"))
+	b.Publish("a", "", []byte("1"))
+	b.Publish("b", "tick", []byte("2\r\n3"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Resume after the first event so the rest arrive from the replay buffer.
+	c := &Client{URL: srv.URL + "/?topic=a&topic=b", LastEventID: first.ID}
+	var got []Event
+	done := errors.New("done")
+	err := c.Stream(ctx, func(ev Event) error {
+		got = append(got, ev)
+		if len(got) == 2 {
+			return done
+		}
+		return nil
+	})
+	if err != done {
+		t.Fatalf("Stream: %v", err)
+	}
+	want := []Event{
+		{ID: 2, Topic: "a", Data: []byte("1")},
+		{ID: 3, Topic: "b", Type: "tick", Data: []byte("2\n3")},
+	}
+	for i, ev := range got {
+		w := want[i]
+		if ev.ID != w.ID || ev.Topic != w.Topic || ev.Type != w.Type || !bytes.Equal(ev.Data, w.Data) {
+			t.Errorf("event %d: got %+v, want %+v", i, ev, w)
+		}
+	}
+}
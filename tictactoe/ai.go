@@ -0,0 +1,211 @@
+package tictactoe
+
+import (
+	"math"
+	"slices"
+)
+
+// winScore is the value of a won position. The number of moves played is
+// subtracted so quicker wins score higher, and because it depends only on the
+// position, not on the search depth, scores can be shared through the
+// transposition table.
+const winScore = 1_000_000
+
+// AI chooses moves with negamax search and alpha-beta pruning.
+//
+// Positions reached through different move orders are the same position, so
+// their values are kept in a transposition table keyed by Board.Hash. On 3x3
+// that cuts the search from ~550,000 nodes for the first move to a few
+// thousand.
+type AI struct {
+	// MaxDepth limits the search in plies; 0 searches to the end of the game.
+	// Positions at the limit are scored by counting open lines, which makes
+	// large boards playable but no longer perfect.
+	MaxDepth int
+	// MaxEntries bounds the transposition table; 0 means 1<<20. A full
+	// table is cleared before the next new position is stored.
+	MaxEntries int
+
+	// Nodes counts positions searched by the last call to Best.
+	Nodes int
+
+	tt    map[uint64]ttEntry
+	limit int
+	dims  [2]int
+}
+
+type bound int8
+
+const (
+	exact bound = iota
+	lower       // the value is at least score (search failed high)
+	upper       // the value is at most score (search failed low)
+)
+
+type ttEntry struct {
+	score int32
+	depth int16 // remaining depth searched; math.MaxInt16 means to the end
+	bound bound
+	best  int16 // cell index of the best move, -1 if none; see MaxN
+}
+
+// Best returns the best move for the player to move and its score from that
+// player's point of view: above 0 is a forced win, 0 a draw with best play,
+// below 0 a forced loss (or, with MaxDepth, the heuristic estimate). It
+// returns false when the game is already over.
+func (ai *AI) Best(b *Board) (Move, int, bool) {
+	if b.Over() {
+		return Move{}, 0, false
+	}
+	if ai.tt == nil || ai.dims != [2]int{b.N, b.K} {
+		ai.tt = make(map[uint64]ttEntry)
+		ai.dims = [2]int{b.N, b.K}
+	}
+	ai.limit = orDefault(ai.MaxEntries, 1<<20)
+	ai.Nodes = 0
+	depth := math.MaxInt16
+	if ai.MaxDepth > 0 {
+		depth = ai.MaxDepth
+	}
+	work := b.Clone()
+	score := ai.negamax(work, depth, -winScore-1, winScore+1)
+	e := ai.tt[work.Hash()]
+	return Move{int(e.best) / b.N, int(e.best) % b.N}, score, true
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func (ai *AI) negamax(b *Board, depth, alpha, beta int) int {
+	ai.Nodes++
+	if b.winner != Empty {
+		// The previous move won: bad for the side to move.
+		return -(winScore - b.moves)
+	}
+	if b.moves == len(b.cells) {
+		return 0
+	}
+	if depth == 0 {
+		return b.evaluate()
+	}
+
+	alpha0 := alpha
+	best := int16(-1)
+	e, hit := ai.tt[b.hash]
+	if hit {
+		best = e.best
+		if int(e.depth) >= depth {
+			s := int(e.score)
+			switch {
+			case e.bound == exact:
+				return s
+			case e.bound == lower && s >= beta:
+				return s
+			case e.bound == upper && s <= alpha:
+				return s
+			}
+		}
+	}
+
+	value := -winScore - 1
+	for _, i := range b.orderedCells(best) {
+		m := Move{i / b.N, i % b.N}
+		b.Play(m)
+		s := -ai.negamax(b, depth-1, -beta, -alpha)
+		b.Undo(m)
+		if s > value {
+			value, best = s, int16(i)
+		}
+		alpha = max(alpha, s)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	e = ttEntry{score: int32(value), depth: int16(min(depth, math.MaxInt16)), best: best}
+	switch {
+	case value <= alpha0:
+		e.bound = upper
+	case value >= beta:
+		e.bound = lower
+	default:
+		e.bound = exact
+	}
+	if _, ok := ai.tt[b.hash]; !ok && len(ai.tt) >= ai.limit {
+		clear(ai.tt)
+	}
+	ai.tt[b.hash] = e
+	return value
+}
+
+// orderedCells lists the empty cells, the transposition table's best move
+// first and the rest from the centre outwards, so good moves are tried early
+// and alpha-beta prunes more.
+func (b *Board) orderedCells(first int16) []int {
+	cells := make([]int, 0, len(b.cells)-b.moves)
+	for i, p := range b.cells {
+		if p == Empty && i != int(first) {
+			cells = append(cells, i)
+		}
+	}
+	centre := float64(b.N-1) / 2
+	dist := func(i int) float64 {
+		return math.Abs(float64(i/b.N)-centre) + math.Abs(float64(i%b.N)-centre)
+	}
+	slices.SortStableFunc(cells, func(x, y int) int {
+		dx, dy := dist(x), dist(y)
+		switch {
+		case dx < dy:
+			return -1
+		case dx > dy:
+			return 1
+		}
+		return 0
+	})
+	if first >= 0 && b.cells[first] == Empty {
+		cells = slices.Insert(cells, 0, int(first))
+	}
+	return cells
+}
+
+// evaluate scores a position at the depth limit for the side to move: every
+// K-cell window holding marks of only one player is worth 4^marks to that
+// player. The result stays far below winScore.
+func (b *Board) evaluate() int {
+	me := X
+	if b.moves%2 == 1 {
+		me = O
+	}
+	score := 0
+	for _, d := range directions {
+		for r := 0; r < b.N; r++ {
+			for c := 0; c < b.N; c++ {
+				er, ec := r+(b.K-1)*d[0], c+(b.K-1)*d[1]
+				if er < 0 || er >= b.N || ec < 0 || ec >= b.N {
+					continue
+				}
+				var mine, theirs int
+				for s := 0; s < b.K; s++ {
+					switch b.cells[(r+s*d[0])*b.N+c+s*d[1]] {
+					case me:
+						mine++
+					case Empty:
+					default:
+						theirs++
+					}
+				}
+				switch {
+				case mine > 0 && theirs == 0:
+					score += 1 << (2 * mine)
+				case theirs > 0 && mine == 0:
+					score -= 1 << (2 * theirs)
+				}
+			}
+		}
+	}
+	return max(-winScore/2, min(winScore/2, score))
+}
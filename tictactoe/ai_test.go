@@ -0,0 +1,100 @@
+package tictactoe
+
+import (
+	"fmt"
+	"testing"
+)
+
+type tally struct {
+	games, wins, draws, losses int
+	lost                       []string
+}
+
+// explore walks every game where the AI plays side and the opponent tries
+// every legal move.
+func explore(b *Board, ai *AI, side Player, line []Move, t *tally) {
+	if b.Over() {
+		t.games++
+		switch b.Winner() {
+		case side:
+			t.wins++
+		case Empty:
+			t.draws++
+		default:
+			t.losses++
+			if len(t.lost) < 3 {
+				t.lost = append(t.lost, fmt.Sprint(line))
+			}
+		}
+		return
+	}
+	if b.Turn() == side {
+		m, _, _ := ai.Best(b)
+		b.Play(m)
+		explore(b, ai, side, append(line, m), t)
+		b.Undo(m)
+		return
+	}
+	for _, m := range b.Moves() {
+		b.Play(m)
+		explore(b, ai, side, append(line, m), t)
+		b.Undo(m)
+	}
+}
+
+func TestEmptyBoardIsDraw(t *testing.T) {
+	b, _ := NewBoard(3, 3)
+	if _, score, _ := (&AI{}).Best(b); score != 0 {
+		t.Fatalf("empty board scored %d, want 0 (draw with best play)", score)
+	}
+}
+
+// TestNeverLoses plays the AI against every possible opponent: at each
+// opponent turn all legal moves are tried, at each AI turn the AI's choice is
+// played. If no game in that tree is lost, no opponent strategy can beat the
+// AI.
+func TestNeverLoses(t *testing.T) {
+	ai := &AI{}
+	for _, side := range []Player{X, O} {
+		b, _ := NewBoard(3, 3)
+		var tl tally
+		explore(b, ai, side, nil, &tl)
+		t.Logf("AI as %s: %d games, %d won, %d drawn, %d lost", side, tl.games, tl.wins, tl.draws, tl.losses)
+		if tl.losses > 0 {
+			t.Errorf("AI as %s lost %d games, e.g. %v", side, tl.losses, tl.lost)
+		}
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	ai := &AI{MaxEntries: 100}
+	b, _ := NewBoard(3, 3)
+	for !b.Over() {
+		m, _, _ := ai.Best(b)
+		if n := len(ai.tt); n > 100 {
+			t.Fatalf("table holds %d entries, want <= 100", n)
+		}
+		b.Play(m)
+	}
+	if !b.Draw() {
+		t.Fatalf("self-play with a small table ended with %s winning, want a draw", b.Winner())
+	}
+}
+
+func TestNewBoardBounds(t *testing.T) {
+	for _, tc := range []struct {
+		n, k int
+		ok   bool
+	}{
+		{3, 3, true},
+		{MaxN, 5, true},
+		{MaxN + 1, 5, false},
+		{3, 4, false},
+		{0, 0, false},
+	} {
+		_, err := NewBoard(tc.n, tc.k)
+		if (err == nil) != tc.ok {
+			t.Errorf("NewBoard(%d, %d) error %v, want ok %v", tc.n, tc.k, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Package tictactoe implements m,n,k-games on a square board: N x N cells, K in
+a row to win. N = K = 3 is tic-tac-toe, N = 15, K = 5 is gomoku.
+
+slice/sliceOfSlice.go draws a 3x3 [][]string board and places marks by hand
+with no rules. Board replaces it with a single flat []Player slice (cell r, c is
+at index r*N+c), rejects illegal moves, tracks whose turn it is and detects the
+winner after every move by looking only at the lines through that move.
+
+AI plays with minimax search; see ai.go.
+*/
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Player is the mark in a cell, or the side to move.
+type Player int8
+
+const (
+	Empty Player = iota
+	X
+	O
+)
+
+func (p Player) String() string {
+	switch p {
+	case X:
+		return "X"
+	case O:
+		return "O"
+	}
+	return "_"
+}
+
+// Other returns the opponent of p.
+func (p Player) Other() Player {
+	switch p {
+	case X:
+		return O
+	case O:
+		return X
+	}
+	return Empty
+}
+
+// Move is a cell, zero-based.
+type Move struct {
+	Row, Col int
+}
+
+func (m Move) String() string { return fmt.Sprintf("%d %d", m.Row+1, m.Col+1) }
+
+var (
+	ErrOutOfRange = errors.New("tictactoe: move outside the board")
+	ErrOccupied   = errors.New("tictactoe: cell already taken")
+	ErrGameOver   = errors.New("tictactoe: game is over")
+)
+
+// Board is the state of one game. X always moves first.
+type Board struct {
+	N, K   int
+	cells  []Player
+	moves  int
+	winner Player
+	hash   uint64
+	zob    [][2]uint64 // Zobrist keys per cell and player
+}
+
+// MaxN is the largest board NewBoard accepts: the AI stores cell indexes in
+// an int16, and 181*181 is the last square that fits.
+const MaxN = 181
+
+// NewBoard returns an empty N x N board where K in a row wins.
+func NewBoard(n, k int) (*Board, error) {
+	if n < 1 || k < 1 || k > n || n > MaxN {
+		return nil, fmt.Errorf("tictactoe: need 1 <= K <= N <= %d, got N=%d K=%d", MaxN, n, k)
+	}
+	// Fixed seed: the same position always hashes the same, across runs too.
+	r := rand.New(rand.NewPCG(uint64(n), uint64(k)))
+	zob := make([][2]uint64, n*n)
+	for i := range zob {
+		zob[i] = [2]uint64{r.Uint64(), r.Uint64()}
+	}
+	return &Board{N: n, K: k, cells: make([]Player, n*n), zob: zob}, nil
+}
+
+// Clone returns an independent copy of b.
+func (b *Board) Clone() *Board {
+	c := *b
+	c.cells = append([]Player(nil), b.cells...)
+	return &c
+}
+
+// At returns the mark at row r, column c.
+func (b *Board) At(r, c int) Player { return b.cells[r*b.N+c] }
+
+// Turn returns the player to move, or Empty once the game is over.
+func (b *Board) Turn() Player {
+	if b.Over() {
+		return Empty
+	}
+	if b.moves%2 == 0 {
+		return X
+	}
+	return O
+}
+
+// Winner returns the player with K in a row, or Empty.
+func (b *Board) Winner() Player { return b.winner }
+
+// Draw reports whether the board is full with no winner.
+func (b *Board) Draw() bool { return b.winner == Empty && b.moves == len(b.cells) }
+
+// Over reports whether the game has ended.
+func (b *Board) Over() bool { return b.winner != Empty || b.moves == len(b.cells) }
+
+// MovesPlayed returns the number of marks on the board.
+func (b *Board) MovesPlayed() int { return b.moves }
+
+// Hash identifies the position: equal positions have equal hashes.
+func (b *Board) Hash() uint64 { return b.hash }
+
+// Moves returns the legal moves, none once the game is over.
+func (b *Board) Moves() []Move {
+	if b.Over() {
+		return nil
+	}
+	out := make([]Move, 0, len(b.cells)-b.moves)
+	for i, p := range b.cells {
+		if p == Empty {
+			out = append(out, Move{i / b.N, i % b.N})
+		}
+	}
+	return out
+}
+
+// Play puts the mark of the player to move on m.
+func (b *Board) Play(m Move) error {
+	switch {
+	case b.Over():
+		return ErrGameOver
+	case m.Row < 0 || m.Row >= b.N || m.Col < 0 || m.Col >= b.N:
+		return ErrOutOfRange
+	case b.cells[m.Row*b.N+m.Col] != Empty:
+		return ErrOccupied
+	}
+	p := b.Turn()
+	i := m.Row*b.N + m.Col
+	b.cells[i] = p
+	b.hash ^= b.zob[i][p-1]
+	b.moves++
+	if b.lineThrough(m, p) >= b.K {
+		b.winner = p
+	}
+	return nil
+}
+
+// Undo takes back m, which must be the last move played.
+func (b *Board) Undo(m Move) {
+	i := m.Row*b.N + m.Col
+	p := b.cells[i]
+	b.cells[i] = Empty
+	b.hash ^= b.zob[i][p-1]
+	b.moves--
+	// No move follows a win, so the position before m had no winner.
+	b.winner = Empty
+}
+
+var directions = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// lineThrough returns the longest run of p through m in any direction.
+func (b *Board) lineThrough(m Move, p Player) int {
+	best := 0
+	for _, d := range directions {
+		n := 1
+		for s := -1; s <= 1; s += 2 {
+			r, c := m.Row+s*d[0], m.Col+s*d[1]
+			for r >= 0 && r < b.N && c >= 0 && c < b.N && b.cells[r*b.N+c] == p {
+				n++
+				r, c = r+s*d[0], c+s*d[1]
+			}
+		}
+		best = max(best, n)
+	}
+	return best
+}
+
+// String draws the board like slice/sliceOfSlice.go: one row per line, "_"
+// for empty cells.
+func (b *Board) String() string {
+	var sb strings.Builder
+	for r := 0; r < b.N; r++ {
+		row := make([]string, b.N)
+		for c := range row {
+			row[c] = b.At(r, c).String()
+		}
+		sb.WriteString(strings.Join(row, " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
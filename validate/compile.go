@@ -0,0 +1,235 @@
+package validate
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// structRules is the compiled form of one struct type.
+type structRules struct {
+	fields []compiledField
+	err    error // cached so a bad tag is reported every time, cheaply
+}
+
+type compiledField struct {
+	index    int
+	goName   string
+	jsonName string
+	embedded bool // an untagged embedded struct: its fields keep the parent's path
+	fieldRules
+}
+
+// fieldRules is what one validate tag compiles to.
+type fieldRules struct {
+	skip      bool
+	required  bool
+	omitempty bool
+	rules     []rule
+	elem      *fieldRules // the rules after dive
+}
+
+var noRules = &fieldRules{}
+
+func (fr *fieldRules) elemRules() *fieldRules {
+	if fr.elem != nil {
+		return fr.elem
+	}
+	return noRules
+}
+
+type rule struct {
+	name, param string
+	check       func(reflect.Value) bool
+	message     func(reflect.Value) string
+}
+
+// compile returns the cached rules for struct type t, compiling them on
+// first use. Two goroutines may both compile a new type; the result is the
+// same and one of them wins.
+func (v *Validator) compile(t reflect.Type) (*structRules, error) {
+	if sr, ok := v.cache.Load(t); ok {
+		return sr.(*structRules), sr.(*structRules).err
+	}
+	sr := &structRules{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("validate")
+		if !ok && hasTagKey(f.Tag, "validate") {
+			sr.err = fmt.Errorf("%w: %s.%s: malformed struct tag %q", ErrBadTag, t, f.Name, f.Tag)
+			break
+		}
+		if !ok && !mayContainStruct(f.Type) {
+			continue
+		}
+		fr, err := v.compileRules(tag, f.Type)
+		if err != nil {
+			sr.err = fmt.Errorf("%w: %s.%s: %v", ErrBadTag, t, f.Name, err)
+			break
+		}
+		cf := compiledField{index: i, goName: f.Name, jsonName: f.Name, fieldRules: *fr}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			cf.jsonName = name
+		}
+		cf.embedded = f.Anonymous && name == "" && deref(f.Type).Kind() == reflect.Struct
+		sr.fields = append(sr.fields, cf)
+	}
+	actual, _ := v.cache.LoadOrStore(t, sr)
+	sr = actual.(*structRules)
+	return sr, sr.err
+}
+
+// compileTag compiles a tag for Var, where the static type is unknown.
+func (v *Validator) compileTag(tag string) (*fieldRules, error) {
+	fr, err := v.compileRules(tag, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrBadTag, tag, err)
+	}
+	return fr, nil
+}
+
+// compileRules parses a tag. t is the field's type, or nil when unknown;
+// when known, rules that cannot apply to it are rejected here rather than
+// failing on every value.
+func (v *Validator) compileRules(tag string, t reflect.Type) (*fieldRules, error) {
+	fr := &fieldRules{}
+	if tag == "-" {
+		fr.skip = true
+		return fr, nil
+	}
+	if tag == "" {
+		return fr, nil
+	}
+	parts := strings.Split(tag, ",")
+	for i, part := range parts {
+		name, param, _ := strings.Cut(part, "=")
+		switch name {
+		case "":
+			return nil, fmt.Errorf("empty rule in %q", tag)
+		case "required":
+			fr.required = true
+		case "omitempty":
+			fr.omitempty = true
+		case "dive":
+			var et reflect.Type
+			if t != nil {
+				switch k := deref(t).Kind(); k {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					et = deref(t).Elem()
+				case reflect.Interface:
+				default:
+					return nil, fmt.Errorf("dive on %s", t)
+				}
+			}
+			elem, err := v.compileRules(strings.Join(parts[i+1:], ","), et)
+			if err != nil {
+				return nil, err
+			}
+			fr.elem = elem
+			return fr, nil
+		default:
+			r, err := v.rule(name, param, t)
+			if err != nil {
+				return nil, err
+			}
+			fr.rules = append(fr.rules, r)
+		}
+	}
+	return fr, nil
+}
+
+func (v *Validator) rule(name, param string, t reflect.Type) (rule, error) {
+	if b, ok := builtins[name]; ok {
+		if t != nil && deref(t).Kind() != reflect.Interface && !b.kinds[deref(t).Kind()] {
+			return rule{}, fmt.Errorf("%s does not apply to %s", name, t)
+		}
+		check, msg, err := b.build(param, t)
+		if err != nil {
+			return rule{}, fmt.Errorf("%s=%s: %v", name, param, err)
+		}
+		return rule{name: name, param: param, check: check, message: msg}, nil
+	}
+	v.mu.RLock()
+	fn, ok := v.rules[name]
+	v.mu.RUnlock()
+	if !ok {
+		return rule{}, fmt.Errorf("unknown rule %q", name)
+	}
+	msg := "failed " + name
+	if param != "" {
+		msg += "=" + param
+	}
+	return rule{
+		name:    name,
+		param:   param,
+		check:   func(v reflect.Value) bool { return fn(v, param) },
+		message: func(reflect.Value) string { return msg },
+	}, nil
+}
+
+// hasTagKey reports whether key appears as a key in tag, even where the
+// tag is too malformed for Lookup to reach it, as in `validate: "required"`.
+// Occurrences inside quoted values, like `json:"validated_at"`, do not count.
+func hasTagKey(tag reflect.StructTag, key string) bool {
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i < len(s) && s[i] == ':' && s[:i] == key {
+			return true
+		}
+		s = s[i:]
+		if !strings.HasPrefix(s, `:"`) {
+			// Not a key:"value" pair; resume at the next space.
+			j := strings.IndexByte(s, ' ')
+			if j < 0 {
+				return false
+			}
+			s = s[j:]
+			continue
+		}
+		j := 2
+		for j < len(s) && s[j] != '"' {
+			if s[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) {
+			return false
+		}
+		s = s[j+1:]
+	}
+	return false
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// sortKeys orders map keys so errors come out in a stable order.
+func sortKeys(keys []reflect.Value) {
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		switch a.Kind() {
+		case reflect.String:
+			return cmp.Compare(a.String(), b.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(a.Int(), b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(a.Uint(), b.Uint())
+		}
+		return cmp.Compare(fmt.Sprint(value(a)), fmt.Sprint(value(b)))
+	})
+}
@@ -0,0 +1,39 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHasTagKey(t *testing.T) {
+	for _, tc := range []struct {
+		tag  reflect.StructTag
+		want bool
+	}{
+		{`validate:"required"`, true},
+		{`validate: "required"`, true},
+		{`validate:required`, true},
+		{`json:"name" validate: "required"`, true},
+		{`json:"a\"validate:" validate:"x"`, true},
+		{`json:"validated_at"`, false},
+		{`json:"validate:x"`, false},
+		{`json:"a\"validate:"`, false},
+		{`xvalidate:"x"`, false},
+		{`json: "validated_at"`, false},
+		{`validate`, false},
+		{``, false},
+	} {
+		if got := hasTagKey(tc.tag, "validate"); got != tc.want {
+			t.Errorf("hasTagKey(%q) = %v, want %v", tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestValueMentioningValidate(t *testing.T) {
+	type event struct {
+		At string `json:"validated_at"`
+	}
+	if err := Struct(event{}); err != nil {
+		t.Errorf("Struct = %v, want nil", err)
+	}
+}
@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// builtin compiles one rule's parameter. kinds lists the kinds of value the
+// rule understands, after pointers are dereferenced.
+type builtin struct {
+	kinds map[reflect.Kind]bool
+	build buildFunc
+}
+
+// buildFunc compiles a parameter into the rule's check and message. t is
+// the field's type, nil when not known until the value is seen.
+type buildFunc func(param string, t reflect.Type) (check func(reflect.Value) bool, msg func(reflect.Value) string, err error)
+
+var builtins = map[string]builtin{
+	"min":   {sizeKinds, compare("at least", func(n, p float64) bool { return n >= p })},
+	"max":   {sizeKinds, compare("at most", func(n, p float64) bool { return n <= p })},
+	"len":   {sizeKinds, compare("exactly", func(n, p float64) bool { return n == p })},
+	"oneof": {oneofKinds, oneof},
+	"email": {stringKind, noParam(isEmail, "must be a valid email address")},
+	"url":   {stringKind, noParam(isURL, "must be an absolute URL")},
+}
+
+var (
+	stringKind = kinds(reflect.String)
+	intKinds   = []reflect.Kind{
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+	}
+	oneofKinds = kinds(append([]reflect.Kind{reflect.String}, intKinds...)...)
+	sizeKinds  = kinds(append([]reflect.Kind{reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+		reflect.Float32, reflect.Float64}, intKinds...)...)
+)
+
+func kinds(ks ...reflect.Kind) map[reflect.Kind]bool {
+	m := make(map[reflect.Kind]bool, len(ks))
+	for _, k := range ks {
+		m[k] = true
+	}
+	return m
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// size returns what min, max and len compare, and the unit for messages.
+func size(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
+
+// compare builds min, max and len. The parameter is a number, or a
+// duration such as 1m30s for time.Duration fields.
+func compare(phrase string, ok func(n, p float64) bool) buildFunc {
+	return func(param string, t reflect.Type) (func(reflect.Value) bool, func(reflect.Value) string, error) {
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			d, derr := time.ParseDuration(param)
+			if derr != nil {
+				return nil, nil, errors.New("want a number or a duration")
+			}
+			if t != nil && deref(t) != durationType {
+				return nil, nil, fmt.Errorf("duration on %s", t)
+			}
+			p = float64(d)
+		}
+		check := func(v reflect.Value) bool {
+			n, _, known := size(v)
+			return known && ok(n, p)
+		}
+		msg := func(v reflect.Value) string {
+			_, unit, known := size(v)
+			switch {
+			case !known:
+				return fmt.Sprintf("cannot be measured (%s)", v.Type())
+			case unit == " items":
+				return "must have " + phrase + " " + param + unit
+			case unit == "" && phrase == "exactly":
+				return "must be " + param
+			}
+			return "must be " + phrase + " " + param + unit
+		}
+		return check, msg, nil
+	}
+}
+
+// oneof takes alternatives separated by |, since commas separate rules.
+func oneof(param string, _ reflect.Type) (func(reflect.Value) bool, func(reflect.Value) string, error) {
+	if param == "" {
+		return nil, nil, errors.New("no alternatives")
+	}
+	alts := strings.Split(param, "|")
+	set := make(map[string]bool, len(alts))
+	for _, a := range alts {
+		set[a] = true
+	}
+	check := func(v reflect.Value) bool {
+		switch v.Kind() {
+		case reflect.String:
+			return set[v.String()]
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return set[strconv.FormatInt(v.Int(), 10)]
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return set[strconv.FormatUint(v.Uint(), 10)]
+		}
+		return false
+	}
+	msg := "must be one of " + strings.Join(alts, ", ")
+	return check, func(reflect.Value) string { return msg }, nil
+}
+
+func noParam(ok func(string) bool, msg string) buildFunc {
+	return func(param string, _ reflect.Type) (func(reflect.Value) bool, func(reflect.Value) string, error) {
+		if param != "" {
+			return nil, nil, errors.New("takes no parameter")
+		}
+		check := func(v reflect.Value) bool { return v.Kind() == reflect.String && ok(v.String()) }
+		return check, func(reflect.Value) string { return msg }, nil
+	}
+}
+
+// isEmail accepts a bare addr-spec: what net/mail parses, without a display
+// name or angle brackets, and with a dot in the domain.
+func isEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	if err != nil || a.Name != "" || a.Address != s {
+		return false
+	}
+	_, domain, _ := strings.Cut(s, "@")
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
@@ -0,0 +1,381 @@
+/*
+Package validate checks struct fields against rules written in struct tags.
+
+struct/main.go declares
+
+	name string `filed: name`
+
+which no package will ever read: a tag is a list of key:"value" pairs, and
+both the space after the colon and the missing quotes make reflect's
+StructTag.Lookup give up without a word (go vet's structtag check does
+notice). This package reads a validate key:
+
+	type Signup struct {
+		Email string            `json:"email" validate:"required,email"`
+		Age   int               `json:"age" validate:"min=13,max=120"`
+		Plan  string            `json:"plan" validate:"oneof=free|pro"`
+		Tags  []string          `json:"tags" validate:"max=5,dive,min=1"`
+		Addr  *Address          `json:"addr" validate:"required"`
+		Meta  map[string]string `json:"meta" validate:"dive,max=64"`
+	}
+
+Rules are separated by commas and run in order; the first one that fails is
+reported for that field. min, max and len measure numbers by value, strings
+by runes and slices and maps by length. dive applies the rules after it to
+each element or map value instead of the container. omitempty skips the
+remaining rules for a zero value.
+
+Nested structs, and structs inside slices, arrays, maps and pointers, are
+validated whether or not the field has a tag; validate:"-" stops that. A tag
+that mentions validate but does not parse is an error rather than being
+ignored.
+
+Each struct type's tags are parsed once and the compiled rules cached, so
+validating a value costs reflection over its fields and the rule calls,
+not string parsing. Failures come back as Errors, one FieldError per field,
+with the path in JSON form (items[2].name, using json tag names) and in Go
+form (Items[2].Name).
+*/
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Func is a custom rule. It receives the field's value, with pointers
+// already dereferenced, and the text after "=" in the tag, and reports
+// whether the value passes.
+type Func func(v reflect.Value, param string) bool
+
+// FieldError is one failed rule.
+type FieldError struct {
+	Path    string `json:"path"`            // JSON path, e.g. items[2].name
+	Field   string `json:"field"`           // Go path, e.g. Items[2].Name
+	Rule    string `json:"rule"`            // e.g. min
+	Param   string `json:"param,omitempty"` // e.g. 1
+	Message string `json:"message"`         // e.g. must be at least 1
+	Value   any    `json:"-"`
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Errors lists every field that failed. It is the only error type Struct
+// and Var return for invalid data; any other error means the rules
+// themselves are wrong.
+type Errors []FieldError
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ByPath returns the errors keyed by JSON path, the shape most form and API
+// clients want.
+func (es Errors) ByPath() map[string]string {
+	m := make(map[string]string, len(es))
+	for _, e := range es {
+		m[e.Path] = e.Message
+	}
+	return m
+}
+
+// Validator holds the rule set and the cache of compiled struct types.
+// Its methods are safe for concurrent use.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Func
+	cache sync.Map // reflect.Type -> *structRules
+}
+
+// New returns a Validator with the built-in rules.
+func New() *Validator {
+	return &Validator{rules: make(map[string]Func)}
+}
+
+var std = New()
+
+// Struct validates v, a struct or pointer to one, with the default Validator.
+func Struct(v any) error { return std.Struct(v) }
+
+// Var validates a single value against a tag with the default Validator.
+func Var(v any, tag string) error { return std.Var(v, tag) }
+
+// Register adds a rule to the default Validator.
+func Register(name string, fn Func) { std.Register(name, fn) }
+
+// Register adds or replaces a custom rule. Built-in rule names cannot be
+// replaced. Rules are resolved when a type is first validated, so register
+// them before that, typically in init.
+func (v *Validator) Register(name string, fn Func) {
+	if _, ok := builtins[name]; ok || name == "dive" || name == "omitempty" || name == "required" {
+		panic("validate: cannot replace built-in rule " + name)
+	}
+	if name == "" || strings.ContainsAny(name, ",=|") {
+		panic("validate: invalid rule name " + strconv.Quote(name))
+	}
+	v.mu.Lock()
+	v.rules[name] = fn
+	v.mu.Unlock()
+	v.cache.Clear()
+}
+
+// Struct validates s, a struct or a non-nil pointer to one. It returns nil,
+// Errors, or an error describing a bad tag.
+func (v *Validator) Struct(s any) error {
+	rv := reflect.ValueOf(s)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() || !rv.IsValid() || deref(rv.Type()).Kind() != reflect.Struct {
+		return fmt.Errorf("validate: Struct wants a struct or non-nil pointer to struct, got %T", s)
+	}
+	w := walker{v: v, visiting: make(map[visit]bool)}
+	w.field(rv, noRules)
+	return w.result()
+}
+
+// Var validates one value against tag, as if it were a field carrying
+// validate:"<tag>". Structs inside it are validated as well.
+func (v *Validator) Var(value any, tag string) error {
+	fr, err := v.compileTag(tag)
+	if err != nil {
+		return err
+	}
+	w := walker{v: v, visiting: make(map[visit]bool)}
+	w.field(reflect.ValueOf(value), fr)
+	return w.result()
+}
+
+// walker carries the state of one Struct or Var call.
+type walker struct {
+	v        *Validator
+	errs     Errors
+	err      error // a compile error, which stops the walk
+	visiting map[visit]bool
+	segs     []seg
+}
+
+// visit is a pointer on the current path. The type is part of the key
+// because a struct and its first field share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (w *walker) result() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+	return nil
+}
+
+func (w *walker) structValue(rv reflect.Value) {
+	sr, err := w.v.compile(rv.Type())
+	if err != nil {
+		w.err = err
+		return
+	}
+	for i := range sr.fields {
+		f := &sr.fields[i]
+		if f.embedded {
+			w.field(rv.Field(f.index), &f.fieldRules)
+		} else {
+			w.segs = append(w.segs, seg{kind: 'f', json: f.jsonName, goName: f.goName})
+			w.field(rv.Field(f.index), &f.fieldRules)
+			w.segs = w.segs[:len(w.segs)-1]
+		}
+		if w.err != nil {
+			return
+		}
+	}
+}
+
+// field applies fr to v, then descends into v if it holds structs.
+func (w *walker) field(v reflect.Value, fr *fieldRules) {
+	if fr.skip {
+		return
+	}
+	// Look through interfaces and pointers. A nil is only checked for
+	// required; no other rule can say anything about it.
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if fr.required {
+				w.fail("required", "", "is required", nil)
+			}
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			key := visit{v.Pointer(), v.Type()}
+			if w.visiting[key] {
+				return // already being validated further up
+			}
+			w.visiting[key] = true
+			defer delete(w.visiting, key)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		if fr.required {
+			w.fail("required", "", "is required", nil)
+		}
+		return
+	}
+	if fr.required && isEmpty(v) {
+		w.fail("required", "", "is required", value(v))
+		return
+	}
+	if fr.omitempty && isEmpty(v) {
+		return
+	}
+	for _, r := range fr.rules {
+		if !r.check(v) {
+			w.fail(r.name, r.param, r.message(v), value(v))
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		w.structValue(v)
+	case reflect.Slice, reflect.Array:
+		if fr.elem == nil && !mayContainStruct(v.Type().Elem()) {
+			return
+		}
+		for i := range v.Len() {
+			w.segs = append(w.segs, seg{kind: 'i', index: i})
+			w.field(v.Index(i), fr.elemRules())
+			w.segs = w.segs[:len(w.segs)-1]
+			if w.err != nil {
+				return
+			}
+		}
+	case reflect.Map:
+		if fr.elem == nil && !mayContainStruct(v.Type().Elem()) {
+			return
+		}
+		keys := v.MapKeys()
+		sortKeys(keys)
+		for _, k := range keys {
+			w.segs = append(w.segs, seg{kind: 'k', key: k})
+			w.field(v.MapIndex(k), fr.elemRules())
+			w.segs = w.segs[:len(w.segs)-1]
+			if w.err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (w *walker) fail(rule, param, msg string, v any) {
+	jsonPath, goPath := w.path()
+	w.errs = append(w.errs, FieldError{
+		Path: jsonPath, Field: goPath, Rule: rule, Param: param, Message: msg, Value: v,
+	})
+}
+
+func value(v reflect.Value) any {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// mayContainStruct reports whether a value of type t can hold a struct
+// somewhere inside it, which decides if untagged containers are walked.
+func mayContainStruct(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Struct, reflect.Interface:
+			return true
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+// seg is one step of the path to the value being checked: a field, a
+// slice index or a map key. The walker keeps them on a stack and only turns
+// them into strings when a rule fails.
+type seg struct {
+	kind   byte // 'f' field, 'i' index, 'k' map key
+	json   string
+	goName string
+	index  int
+	key    reflect.Value
+}
+
+// path returns the JSON and Go spellings of the current location. Map keys
+// are written as .key in the JSON form when the key is a plain identifier
+// and as ["key"] otherwise, so that a.b.c never hides a key containing a dot.
+func (w *walker) path() (jsonPath, goPath string) {
+	var j, g strings.Builder
+	for _, s := range w.segs {
+		switch s.kind {
+		case 'f':
+			if j.Len() > 0 {
+				j.WriteByte('.')
+				g.WriteByte('.')
+			}
+			j.WriteString(s.json)
+			g.WriteString(s.goName)
+		case 'i':
+			idx := "[" + strconv.Itoa(s.index) + "]"
+			j.WriteString(idx)
+			g.WriteString(idx)
+		case 'k':
+			if s.key.Kind() != reflect.String {
+				k := fmt.Sprint(value(s.key))
+				j.WriteString("[" + strconv.Quote(k) + "]")
+				g.WriteString("[" + k + "]")
+				continue
+			}
+			k := s.key.String()
+			if isIdent(k) && j.Len() > 0 {
+				j.WriteString("." + k)
+			} else {
+				j.WriteString("[" + strconv.Quote(k) + "]")
+			}
+			g.WriteString("[" + strconv.Quote(k) + "]")
+		}
+	}
+	return j.String(), g.String()
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrBadTag wraps every error about a tag that cannot be compiled.
+var ErrBadTag = errors.New("validate: bad tag")
@@ -0,0 +1,520 @@
+/*
+Package ws implements RFC 6455 WebSockets with only the standard library.
+
+concept/Networking/how_Go_optimize_Websocket_and_GRPC.md describes the usual Go
+shape for long-lived connections: one goroutine reads, one goroutine writes,
+and everything else talks to them through channels. Conn is exactly that. The
+read goroutine parses frames, reassembles fragmented messages and answers pings;
+the write goroutine owns the socket for writing, fragments large messages, sends
+keep-alive pings and drives the close handshake. Outgoing messages wait in a
+bounded queue, so a slow peer shows up as ErrSendQueueFull instead of unbounded
+memory growth.
+*/
+package ws
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Close status codes from RFC 6455 section 7.4.1.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005 // never sent, reported when a close frame has no body
+	CloseAbnormal        = 1006 // never sent, reported when the TCP connection just dropped
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+// MessageType distinguishes text from binary messages.
+type MessageType int
+
+const (
+	Text   MessageType = MessageType(opText)
+	Binary MessageType = MessageType(opBinary)
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case Text:
+		return "text"
+	case Binary:
+		return "binary"
+	}
+	return "MessageType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Message is one complete, reassembled data message.
+type Message struct {
+	Type MessageType
+	Data []byte
+}
+
+var (
+	// ErrClosed is returned when using a connection that is closing or closed.
+	ErrClosed = errors.New("ws: connection closed")
+	// ErrSendQueueFull is returned by Send when the peer is not keeping up.
+	ErrSendQueueFull = errors.New("ws: send queue full")
+	// ErrPongTimeout ends a connection whose peer stopped answering pings.
+	ErrPongTimeout = errors.New("ws: pong timeout")
+	// ErrCloseTimeout ends a connection whose peer never completed the close handshake.
+	ErrCloseTimeout = errors.New("ws: close handshake timed out")
+)
+
+// CloseError reports the status the peer sent in its close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("ws: closed with status %d %q", e.Code, e.Reason)
+}
+
+// Options tunes a connection. The zero value uses the defaults listed per field.
+type Options struct {
+	SendQueue      int           // queued outgoing messages, default 64
+	ReadQueue      int           // reassembled messages waiting for ReadMessage, default 16
+	MaxMessageSize int64         // largest accepted message, default 1 MiB
+	FragmentSize   int           // outgoing messages are split into frames of this size, default 32 KiB
+	PingInterval   time.Duration // default 30s, negative disables pings
+	PongTimeout    time.Duration // extra silence tolerated after a ping, default PingInterval
+	WriteTimeout   time.Duration // per frame write deadline, default 10s
+	CloseTimeout   time.Duration // wait for the peer's close frame, default 5s
+
+	Subprotocols []string                 // offered by Dial, accepted by Upgrade in preference order
+	CheckOrigin  func(*http.Request) bool // server only, default allows same origin and non-browser clients
+	TLSConfig    *tls.Config              // client only, for wss://
+}
+
+func (o *Options) withDefaults() Options {
+	var v Options
+	if o != nil {
+		v = *o
+	}
+	if v.SendQueue <= 0 {
+		v.SendQueue = 64
+	}
+	if v.ReadQueue <= 0 {
+		v.ReadQueue = 16
+	}
+	if v.MaxMessageSize <= 0 {
+		v.MaxMessageSize = 1 << 20
+	}
+	if v.FragmentSize <= 0 {
+		v.FragmentSize = 32 << 10
+	}
+	if v.PingInterval == 0 {
+		v.PingInterval = 30 * time.Second
+	}
+	if v.PongTimeout <= 0 {
+		v.PongTimeout = v.PingInterval
+	}
+	if v.WriteTimeout <= 0 {
+		v.WriteTimeout = 10 * time.Second
+	}
+	if v.CloseTimeout <= 0 {
+		v.CloseTimeout = 5 * time.Second
+	}
+	return v
+}
+
+var noDeadline time.Time
+
+type outFrame struct {
+	op   opcode
+	data []byte
+}
+
+// Conn is a WebSocket connection. All methods are safe for concurrent use.
+type Conn struct {
+	nc       net.Conn
+	br       *bufio.Reader
+	client   bool // clients mask what they send and expect unmasked frames
+	protocol string
+	opts     Options
+
+	send chan outFrame // data messages
+	ctrl chan outFrame // ping, pong and close, written before queued data
+	in   chan Message
+
+	lastRead  atomic.Int64 // unix nanos of the last frame received
+	closing   atomic.Bool  // a close frame has been queued
+	closeRecv atomic.Bool  // the peer's close frame has arrived
+	closeSent atomic.Bool  // our close frame is on the wire
+	closeInit sync.Once
+	peerClose *CloseError // status from the peer, written before closeRecv is set
+	heldClose *outFrame   // close frame deferred until a fragmented message is out; write goroutine only
+
+	done    chan struct{}
+	endOnce sync.Once
+	err     error // set before done is closed
+}
+
+func newConn(nc net.Conn, br *bufio.Reader, client bool, protocol string, o Options) *Conn {
+	c := &Conn{
+		nc:       nc,
+		br:       br,
+		client:   client,
+		protocol: protocol,
+		opts:     o,
+		send:     make(chan outFrame, o.SendQueue),
+		ctrl:     make(chan outFrame, 4),
+		in:       make(chan Message, o.ReadQueue),
+		done:     make(chan struct{}),
+	}
+	c.lastRead.Store(time.Now().UnixNano())
+	go c.readLoop()
+	go c.writeLoop()
+	return c
+}
+
+// Subprotocol returns the negotiated subprotocol, if any.
+func (c *Conn) Subprotocol() string { return c.protocol }
+
+// RemoteAddr returns the peer's network address.
+func (c *Conn) RemoteAddr() net.Addr { return c.nc.RemoteAddr() }
+
+// Done is closed once the connection has fully shut down.
+func (c *Conn) Done() <-chan struct{} { return c.done }
+
+// Err reports why the connection ended: a *CloseError after a close
+// handshake, or the I/O or protocol error that killed it. It is nil while the
+// connection is open.
+func (c *Conn) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Queued returns the number of messages waiting to be written.
+func (c *Conn) Queued() int { return len(c.send) }
+
+// Send queues a message without blocking. data must not be modified afterwards.
+func (c *Conn) Send(typ MessageType, data []byte) error {
+	if c.closing.Load() {
+		return ErrClosed
+	}
+	select {
+	case c.send <- outFrame{op: opcode(typ), data: data}:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	default:
+		return ErrSendQueueFull
+	}
+}
+
+// WriteMessage queues a message, waiting for room in the send queue.
+func (c *Conn) WriteMessage(ctx context.Context, typ MessageType, data []byte) error {
+	if c.closing.Load() {
+		return ErrClosed
+	}
+	select {
+	case c.send <- outFrame{op: opcode(typ), data: data}:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadMessage returns the next data message. After the connection ends it
+// returns the reason, see Err.
+func (c *Conn) ReadMessage(ctx context.Context) (Message, error) {
+	select {
+	case m := <-c.in:
+		return m, nil
+	default:
+	}
+	select {
+	case m := <-c.in:
+		return m, nil
+	case <-c.done:
+		return Message{}, c.err
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+// Close starts the close handshake, flushing queued messages first, and waits
+// until the peer answers or CloseTimeout passes.
+func (c *Conn) Close(code int, reason string) error {
+	c.startClose(code, reason)
+	<-c.done
+	var ce *CloseError
+	if errors.As(c.err, &ce) {
+		return nil
+	}
+	return c.err
+}
+
+// startClose queues our close frame once. If the peer never answers, the
+// timer tears the connection down.
+func (c *Conn) startClose(code int, reason string) {
+	c.closeInit.Do(func() {
+		c.closing.Store(true)
+		select {
+		case c.ctrl <- outFrame{op: opClose, data: closePayload(code, reason)}:
+		case <-c.done:
+			return
+		}
+		time.AfterFunc(c.opts.CloseTimeout, func() { c.end(ErrCloseTimeout) })
+	})
+}
+
+// end tears down the TCP connection and records why.
+func (c *Conn) end(err error) {
+	c.endOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		c.nc.Close()
+	})
+}
+
+// fail ends the connection after a read error. Protocol violations are
+// reported to the peer with a close frame first.
+func (c *Conn) fail(err error) {
+	var pe *protocolError
+	if errors.As(err, &pe) && !c.closing.Load() {
+		c.startClose(pe.code, pe.reason)
+		// Give the write goroutine a moment to get the close frame out.
+		time.AfterFunc(100*time.Millisecond, func() { c.end(err) })
+		return
+	}
+	if c.closing.Load() && c.closeRecv.Load() {
+		return // normal shutdown already in progress
+	}
+	c.end(err)
+}
+
+func (c *Conn) readLoop() {
+	var (
+		msgOp opcode
+		msg   []byte
+		inMsg bool
+	)
+	for {
+		f, err := readFrame(c.br, c.opts.MaxMessageSize)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		c.lastRead.Store(time.Now().UnixNano())
+		if c.client && f.masked {
+			c.fail(errProtocol("server frames must not be masked"))
+			return
+		}
+		if !c.client && !f.masked {
+			c.fail(errProtocol("client frames must be masked"))
+			return
+		}
+
+		switch f.op {
+		case opPing:
+			select {
+			case c.ctrl <- outFrame{op: opPong, data: f.payload}:
+			default: // a pong is already pending; one answer is enough
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code, reason, err := parseClose(f.payload)
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			// peerClose is published by the store to closeRecv: the write
+			// goroutine reads it once it sees closeRecv set.
+			ce := &CloseError{Code: code, Reason: reason}
+			c.peerClose = ce
+			c.closeRecv.Store(true)
+			if c.closing.Load() {
+				// We started the handshake and this is the answer. If our
+				// frame is still queued, the write goroutine ends the
+				// connection once it is out; either side may see the
+				// other's flag, and end runs once.
+				if c.closeSent.Load() {
+					c.end(ce)
+				}
+				return
+			}
+			// Echo the status back; the write goroutine ends the
+			// connection once the echo is on the wire.
+			echo := code
+			if code == CloseNoStatus {
+				echo = CloseNormal
+			}
+			c.startClose(echo, "")
+			return
+		case opText, opBinary:
+			if inMsg {
+				c.fail(errProtocol("new message started before the previous one finished"))
+				return
+			}
+			msgOp, msg, inMsg = f.op, f.payload, true
+		case opContinuation:
+			if !inMsg {
+				c.fail(errProtocol("continuation frame without a message in progress"))
+				return
+			}
+			if int64(len(msg)+len(f.payload)) > c.opts.MaxMessageSize {
+				c.fail(&protocolError{code: CloseMessageTooBig, reason: "message exceeds limit"})
+				return
+			}
+			msg = append(msg, f.payload...)
+		}
+		if !f.fin {
+			continue
+		}
+		inMsg = false
+		if msgOp == opText && !utf8.Valid(msg) {
+			c.fail(&protocolError{code: CloseInvalidPayload, reason: "text message is not valid UTF-8"})
+			return
+		}
+		select {
+		case c.in <- Message{Type: MessageType(msgOp), Data: msg}:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Conn) writeLoop() {
+	var ping <-chan time.Time
+	if c.opts.PingInterval > 0 {
+		t := time.NewTicker(c.opts.PingInterval)
+		defer t.Stop()
+		ping = t.C
+	}
+	buf := make([]byte, 0, c.opts.FragmentSize+14)
+	for {
+		if f := c.heldClose; f != nil {
+			c.heldClose = nil
+			c.writeControl(*f, &buf)
+			return
+		}
+		// Control frames jump the queue.
+		select {
+		case f := <-c.ctrl:
+			if !c.writeControl(f, &buf) {
+				return
+			}
+			continue
+		default:
+		}
+		select {
+		case f := <-c.ctrl:
+			if !c.writeControl(f, &buf) {
+				return
+			}
+		case m := <-c.send:
+			if err := c.writeMessage(m, &buf); err != nil {
+				c.end(err)
+				return
+			}
+		case <-ping:
+			silent := time.Since(time.Unix(0, c.lastRead.Load()))
+			if silent > c.opts.PingInterval+c.opts.PongTimeout {
+				c.end(ErrPongTimeout)
+				return
+			}
+			if err := c.writeFrame(&buf, true, opPing, nil); err != nil {
+				c.end(err)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// writeControl writes a control frame and reports whether the loop should
+// keep running.
+func (c *Conn) writeControl(f outFrame, buf *[]byte) bool {
+	if f.op == opClose && !c.closeRecv.Load() {
+		// Locally initiated close: flush what the application already
+		// queued so Close does not silently drop messages.
+		for flushed := false; !flushed; {
+			select {
+			case m := <-c.send:
+				if err := c.writeMessage(m, buf); err != nil {
+					c.end(err)
+					return false
+				}
+			default:
+				flushed = true
+			}
+		}
+	}
+	if err := c.writeFrame(buf, true, f.op, f.data); err != nil {
+		c.end(err)
+		return false
+	}
+	if f.op != opClose {
+		return true
+	}
+	c.closeSent.Store(true)
+	if c.closeRecv.Load() {
+		// We were answering the peer's close: the handshake is complete.
+		c.end(c.peerClose)
+	}
+	return false
+}
+
+// writeMessage splits m into FragmentSize frames. Pings and pongs may be sent
+// between fragments, which the protocol explicitly allows.
+func (c *Conn) writeMessage(m outFrame, buf *[]byte) error {
+	data, op := m.data, m.op
+	for {
+		n := min(len(data), c.opts.FragmentSize)
+		fin := n == len(data)
+		if err := c.writeFrame(buf, fin, op, data[:n]); err != nil {
+			return err
+		}
+		if fin {
+			return nil
+		}
+		data, op = data[n:], opContinuation
+		select {
+		case f := <-c.ctrl:
+			if f.op == opClose {
+				// Finish this message first; writeLoop sends the close next.
+				c.heldClose = &f
+				continue
+			}
+			if err := c.writeFrame(buf, true, f.op, f.data); err != nil {
+				return err
+			}
+		default:
+		}
+	}
+}
+
+func (c *Conn) writeFrame(buf *[]byte, fin bool, op opcode, payload []byte) error {
+	*buf = appendFrame((*buf)[:0], fin, op, payload, c.client)
+	c.nc.SetWriteDeadline(time.Now().Add(c.opts.WriteTimeout))
+	_, err := c.nc.Write(*buf)
+	return err
+}
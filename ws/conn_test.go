@@ -0,0 +1,197 @@
+package ws_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang/ws"
+)
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// echoServer answers every message with the same message.
+func echoServer(t *testing.T, opts *ws.Options) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := ws.Upgrade(w, r, opts)
+		if err != nil {
+			return
+		}
+		for {
+			m, err := c.ReadMessage(context.Background())
+			if err != nil {
+				return
+			}
+			c.WriteMessage(context.Background(), m.Type, m.Data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func wsURL(s *httptest.Server) string { return "ws" + strings.TrimPrefix(s.URL, "http") }
+
+func dial(t *testing.T, ctx context.Context, srv *httptest.Server, opts *ws.Options) *ws.Conn {
+	t.Helper()
+	c, err := ws.Dial(ctx, wsURL(srv), nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(ws.CloseNormal, "") })
+	return c
+}
+
+func roundTrip(t *testing.T, ctx context.Context, c *ws.Conn, typ ws.MessageType, data []byte) {
+	t.Helper()
+	if err := c.WriteMessage(ctx, typ, data); err != nil {
+		t.Fatal(err)
+	}
+	m, err := c.ReadMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != typ || !bytes.Equal(m.Data, data) {
+		t.Fatalf("got %v message of %d bytes, want %v of %d", m.Type, len(m.Data), typ, len(data))
+	}
+}
+
+func TestEcho(t *testing.T) {
+	ctx := testContext(t)
+	c := dial(t, ctx, echoServer(t, nil), nil)
+	roundTrip(t, ctx, c, ws.Text, []byte("héllo, wörld"))
+	roundTrip(t, ctx, c, ws.Binary, []byte{0, 1, 2, 0xff})
+}
+
+func TestFragmented(t *testing.T) {
+	ctx := testContext(t)
+	opts := &ws.Options{FragmentSize: 16 << 10}
+	c := dial(t, ctx, echoServer(t, opts), opts)
+	roundTrip(t, ctx, c, ws.Binary, bytes.Repeat([]byte("0123456789abcdef"), 300<<10/16))
+}
+
+func TestPingKeepsIdleConnAlive(t *testing.T) {
+	ctx := testContext(t)
+	opts := &ws.Options{PingInterval: 30 * time.Millisecond, PongTimeout: 30 * time.Millisecond}
+	c := dial(t, ctx, echoServer(t, opts), opts)
+	// Neither side sends data for ten ping intervals; only pings and pongs
+	// keep the pong timeout from firing.
+	time.Sleep(300 * time.Millisecond)
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, ctx, c, ws.Text, []byte("still here"))
+}
+
+func TestCloseStatus(t *testing.T) {
+	ctx := testContext(t)
+	got := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := ws.Upgrade(w, r, nil)
+		if err != nil {
+			got <- err
+			return
+		}
+		_, err = c.ReadMessage(context.Background())
+		got <- err
+	}))
+	defer srv.Close()
+	c, err := ws.Dial(ctx, wsURL(srv), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(4001, "done here"); err != nil {
+		t.Fatalf("client close: %v", err)
+	}
+	var ce *ws.CloseError
+	if err := <-got; !errors.As(err, &ce) || ce.Code != 4001 || ce.Reason != "done here" {
+		t.Fatalf("server saw %v, want status 4001", err)
+	}
+}
+
+// TestCloseBothSides has both ends start the handshake at once, so the
+// peer's close frame arrives while our own Close is writing; run with -race.
+func TestCloseBothSides(t *testing.T) {
+	ctx := testContext(t)
+	for range 20 {
+		start := make(chan struct{})
+		closed := make(chan error, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := ws.Upgrade(w, r, nil)
+			if err != nil {
+				closed <- err
+				return
+			}
+			<-start
+			closed <- c.Close(ws.CloseGoingAway, "server")
+		}))
+		c, err := ws.Dial(ctx, wsURL(srv), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		close(start)
+		errc := c.Close(ws.CloseNormal, "client")
+		errs := <-closed
+		srv.Close()
+		for _, err := range []error{errc, errs} {
+			var ce *ws.CloseError
+			if err != nil && !errors.As(err, &ce) {
+				t.Fatalf("close: %v", err)
+			}
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	srv := echoServer(t, nil)
+	// Speak the protocol by hand to send what ws.Conn never would.
+	nc, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	fmt.Fprintf(nc, "GET / HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	br := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status %s", resp.Status)
+	}
+	// Masked text frame (zero key) carrying 0xff, which is never valid UTF-8.
+	nc.Write([]byte{0x81, 0x81, 0, 0, 0, 0, 0xff})
+	nc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		t.Fatal(err)
+	}
+	if hdr[0] != 0x88 || int(hdr[2])<<8|int(hdr[3]) != ws.CloseInvalidPayload {
+		t.Fatalf("got frame % x, want close 1007", hdr)
+	}
+}
+
+func TestBadHandshake(t *testing.T) {
+	srv := echoServer(t, nil)
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("plain GET got %s", resp.Status)
+	}
+}
@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// opcode is the 4-bit frame type from RFC 6455 section 5.2.
+type opcode byte
+
+const (
+	opContinuation opcode = 0x0
+	opText         opcode = 0x1
+	opBinary       opcode = 0x2
+	opClose        opcode = 0x8
+	opPing         opcode = 0x9
+	opPong         opcode = 0xA
+)
+
+func (o opcode) isControl() bool { return o&0x8 != 0 }
+
+const (
+	finBit  = 0x80
+	rsvBits = 0x70
+	maskBit = 0x80
+
+	// maxControlPayload is the limit for ping, pong and close frames.
+	maxControlPayload = 125
+)
+
+// frame is one decoded frame with its payload already unmasked.
+type frame struct {
+	fin     bool
+	op      opcode
+	masked  bool
+	payload []byte
+}
+
+// protocolError is a violation the peer must be told about with a close code.
+type protocolError struct {
+	code   int
+	reason string
+}
+
+func (e *protocolError) Error() string { return fmt.Sprintf("ws: %s (close %d)", e.reason, e.code) }
+
+func errProtocol(format string, args ...any) error {
+	return &protocolError{code: CloseProtocolError, reason: fmt.Sprintf(format, args...)}
+}
+
+// readFrame decodes the next frame. Payloads larger than maxPayload are
+// rejected before anything is allocated for them.
+func readFrame(r *bufio.Reader, maxPayload int64) (frame, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{
+		fin:    hdr[0]&finBit != 0,
+		op:     opcode(hdr[0] & 0x0f),
+		masked: hdr[1]&maskBit != 0,
+	}
+	if hdr[0]&rsvBits != 0 {
+		return frame{}, errProtocol("reserved bits set without a negotiated extension")
+	}
+	switch f.op {
+	case opContinuation, opText, opBinary, opClose, opPing, opPong:
+	default:
+		return frame{}, errProtocol("unknown opcode %#x", byte(f.op))
+	}
+
+	length := int64(hdr[1] &^ maskBit)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		u := binary.BigEndian.Uint64(ext[:])
+		if u>>63 != 0 {
+			return frame{}, errProtocol("payload length has the most significant bit set")
+		}
+		length = int64(u)
+	}
+	if f.op.isControl() {
+		if !f.fin {
+			return frame{}, errProtocol("fragmented control frame")
+		}
+		if length > maxControlPayload {
+			return frame{}, errProtocol("control frame payload of %d bytes", length)
+		}
+	}
+	if length > maxPayload {
+		return frame{}, &protocolError{code: CloseMessageTooBig, reason: fmt.Sprintf("frame of %d bytes exceeds limit", length)}
+	}
+
+	var key [4]byte
+	if f.masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return frame{}, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	if f.masked {
+		maskBytes(key, f.payload)
+	}
+	return f, nil
+}
+
+// appendFrame encodes a frame onto dst. Clients must mask every frame they
+// send (section 5.3); the key comes from crypto/rand so intermediaries cannot
+// predict it.
+func appendFrame(dst []byte, fin bool, op opcode, payload []byte, mask bool) []byte {
+	b0 := byte(op)
+	if fin {
+		b0 |= finBit
+	}
+	var b1 byte
+	if mask {
+		b1 = maskBit
+	}
+	switch n := len(payload); {
+	case n < 126:
+		dst = append(dst, b0, b1|byte(n))
+	case n <= 0xffff:
+		dst = append(dst, b0, b1|126)
+		dst = binary.BigEndian.AppendUint16(dst, uint16(n))
+	default:
+		dst = append(dst, b0, b1|127)
+		dst = binary.BigEndian.AppendUint64(dst, uint64(n))
+	}
+	if !mask {
+		return append(dst, payload...)
+	}
+	var key [4]byte
+	rand.Read(key[:])
+	dst = append(dst, key[:]...)
+	start := len(dst)
+	dst = append(dst, payload...)
+	maskBytes(key, dst[start:])
+	return dst
+}
+
+// maskBytes XORs b with the masking key. Masking is its own inverse.
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
+
+// closePayload builds the body of a close frame.
+func closePayload(code int, reason string) []byte {
+	if code == CloseNoStatus {
+		return nil
+	}
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	b := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return append(b, reason...)
+}
+
+// parseClose decodes a close frame body.
+func parseClose(p []byte) (code int, reason string, err error) {
+	switch {
+	case len(p) == 0:
+		return CloseNoStatus, "", nil
+	case len(p) == 1:
+		return 0, "", errProtocol("close frame with a 1 byte payload")
+	}
+	code = int(binary.BigEndian.Uint16(p))
+	if !validCloseCode(code) {
+		return 0, "", errProtocol("invalid close code %d", code)
+	}
+	return code, string(p[2:]), nil
+}
+
+// validCloseCode reports whether code may appear on the wire (section 7.4).
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 3000 && code <= 4999:
+		return true
+	case code >= 1000 && code <= 1014:
+		return code != 1004 && code != CloseNoStatus && code != CloseAbnormal
+	}
+	return false
+}
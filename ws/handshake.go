@@ -0,0 +1,218 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// acceptGUID is the fixed GUID from RFC 6455 section 1.3.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// ErrBadHandshake is returned when the opening handshake is invalid.
+var ErrBadHandshake = errors.New("ws: bad handshake")
+
+// Upgrade completes the server side of the opening handshake and takes over
+// the connection. On failure an HTTP error has already been written.
+func Upgrade(w http.ResponseWriter, r *http.Request, opts *Options) (*Conn, error) {
+	o := opts.withDefaults()
+	fail := func(status int, reason string) (*Conn, error) {
+		http.Error(w, reason, status)
+		return nil, fmt.Errorf("%w: %s", ErrBadHandshake, reason)
+	}
+
+	if r.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, "method must be GET")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "missing Upgrade: websocket")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, "unsupported Sec-WebSocket-Version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		return fail(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	check := o.CheckOrigin
+	if check == nil {
+		check = sameOrigin
+	}
+	if !check(r) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "response writer cannot be hijacked")
+	}
+	protocol := selectProtocol(r.Header, o.Subprotocols)
+	netConn, brw, err := h.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("ws: hijack: %w", err)
+	}
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if protocol != "" {
+		resp += "Sec-WebSocket-Protocol: " + protocol + "\r\n"
+	}
+	if _, err := netConn.Write([]byte(resp + "\r\n")); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("ws: write handshake: %w", err)
+	}
+	return newConn(netConn, brw.Reader, false, protocol, o), nil
+}
+
+// Dial opens a client connection to a ws:// or wss:// URL. header is sent
+// with the handshake and may be nil.
+func Dial(ctx context.Context, rawURL string, header http.Header, opts *Options) (*Conn, error) {
+	o := opts.withDefaults()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	useTLS := false
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		useTLS = true
+	default:
+		return nil, fmt.Errorf("ws: unsupported scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		if useTLS {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var netConn net.Conn
+	if useTLS {
+		d := tls.Dialer{Config: o.TLSConfig}
+		netConn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		netConn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Bound the handshake by ctx as well as the dial.
+	if dl, ok := ctx.Deadline(); ok {
+		netConn.SetDeadline(dl)
+	}
+	stop := context.AfterFunc(ctx, func() { netConn.Close() })
+	defer stop()
+
+	var raw [16]byte
+	rand.Read(raw[:])
+	key := base64.StdEncoding.EncodeToString(raw[:])
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.Path, RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       u.Host,
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(o.Subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(o.Subprotocols, ", "))
+	}
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("ws: write handshake: %w", err)
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("ws: read handshake: %w", err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode != http.StatusSwitchingProtocols:
+		err = fmt.Errorf("%w: status %s", ErrBadHandshake, resp.Status)
+	case !headerHasToken(resp.Header, "Upgrade", "websocket") || !headerHasToken(resp.Header, "Connection", "upgrade"):
+		err = fmt.Errorf("%w: missing upgrade headers", ErrBadHandshake)
+	case resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key):
+		err = fmt.Errorf("%w: Sec-WebSocket-Accept mismatch", ErrBadHandshake)
+	}
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	netConn.SetDeadline(noDeadline)
+	return newConn(netConn, br, true, resp.Header.Get("Sec-WebSocket-Protocol"), o), nil
+}
+
+// headerHasToken reports whether a comma separated header contains token,
+// compared case-insensitively.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectProtocol picks the first of the server's subprotocols the client offered.
+func selectProtocol(h http.Header, supported []string) string {
+	for _, s := range supported {
+		if headerHasToken(h, "Sec-WebSocket-Protocol", s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// sameOrigin accepts requests without an Origin header (non-browser clients)
+// and browser requests whose Origin host matches the Host header.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
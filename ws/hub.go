@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Hub fans messages out to every registered connection. A client whose send
+// queue is full is disconnected with CloseTryAgainLater rather than allowed to
+// slow down everybody else.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*Conn]struct{}
+
+	dropped atomic.Int64
+}
+
+// NewHub returns an empty hub.
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*Conn]struct{})}
+}
+
+// Add registers c. It is removed automatically when it closes.
+func (h *Hub) Add(c *Conn) {
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	go func() {
+		<-c.Done()
+		h.Remove(c)
+	}()
+}
+
+// Remove unregisters c.
+func (h *Hub) Remove(c *Conn) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+}
+
+// Len returns the number of registered connections.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Dropped returns how many slow clients have been disconnected.
+func (h *Hub) Dropped() int64 { return h.dropped.Load() }
+
+// Broadcast queues the message for every client and returns how many accepted it.
+func (h *Hub) Broadcast(typ MessageType, data []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	sent := 0
+	for c := range h.clients {
+		switch err := c.Send(typ, data); err {
+		case nil:
+			sent++
+		case ErrSendQueueFull:
+			h.dropped.Add(1)
+			go c.Close(CloseTryAgainLater, "too slow")
+		}
+	}
+	return sent
+}
+
+// Close closes every client with CloseGoingAway.
+func (h *Hub) Close() {
+	h.mu.RLock()
+	clients := make([]*Conn, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Close(CloseGoingAway, "server shutting down")
+		}()
+	}
+	wg.Wait()
+}
+
+// Handler upgrades each request, registers the connection and rebroadcasts
+// every message it receives, which makes a minimal chat room.
+func (h *Hub) Handler(opts *Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, opts)
+		if err != nil {
+			return
+		}
+		h.Add(c)
+		for {
+			m, err := c.ReadMessage(context.Background())
+			if err != nil {
+				return
+			}
+			h.Broadcast(m.Type, m.Data)
+		}
+	})
+}
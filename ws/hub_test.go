@@ -0,0 +1,54 @@
+package ws_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang/ws"
+)
+
+func TestBroadcast(t *testing.T) {
+	ctx := testContext(t)
+	hub := ws.NewHub()
+	srv := httptest.NewServer(hub.Handler(nil))
+	defer srv.Close()
+	var clients []*ws.Conn
+	for range 5 {
+		clients = append(clients, dial(t, ctx, srv, nil))
+	}
+	for hub.Len() < len(clients) {
+		time.Sleep(time.Millisecond)
+	}
+	clients[0].WriteMessage(ctx, ws.Text, []byte("hi all"))
+	for i, c := range clients {
+		m, err := c.ReadMessage(ctx)
+		if err != nil {
+			t.Fatalf("client %d: %v", i, err)
+		}
+		if string(m.Data) != "hi all" {
+			t.Fatalf("client %d got %q", i, m.Data)
+		}
+	}
+}
+
+func TestSlowClientDropped(t *testing.T) {
+	ctx := testContext(t)
+	hub := ws.NewHub()
+	srv := httptest.NewServer(hub.Handler(&ws.Options{SendQueue: 4}))
+	defer srv.Close()
+	// The client never reads, so once the kernel buffers fill up the
+	// server-side send queue overflows.
+	dial(t, ctx, srv, &ws.Options{ReadQueue: 1, CloseTimeout: 100 * time.Millisecond})
+	for hub.Len() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	payload := make([]byte, 64<<10)
+	for deadline := time.Now().Add(5 * time.Second); hub.Dropped() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("slow client was never dropped")
+		}
+		hub.Broadcast(ws.Binary, payload)
+		time.Sleep(time.Millisecond)
+	}
+}